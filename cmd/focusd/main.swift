@@ -8,35 +8,73 @@ struct FocusOutput: Codable {
     let bundle_id: String
     let pid: Int
     let window_title: String
+    let idle_ms: Int64
 }
 
-guard let app = NSWorkspace.shared.frontmostApplication else {
-    exit(1)
-}
-
-let tsMs = Int64(Date().timeIntervalSince1970 * 1000)
-let appName = app.localizedName ?? ""
-let bundleId = app.bundleIdentifier ?? ""
-let pid = Int(app.processIdentifier)
-
-var windowTitle = ""
-
-if let windowList = CGWindowListCopyWindowInfo([.optionOnScreenOnly, .excludeDesktopElements], kCGNullWindowID) as? [[String: Any]] {
+func windowTitle(for pid: Int) -> String {
+    guard let windowList = CGWindowListCopyWindowInfo([.optionOnScreenOnly, .excludeDesktopElements], kCGNullWindowID) as? [[String: Any]] else {
+        return ""
+    }
     for window in windowList {
         if let windowOwnerPID = window[kCGWindowOwnerPID as String] as? Int, windowOwnerPID == pid {
             // Get the window name (title)
             if let name = window[kCGWindowName as String] as? String, !name.isEmpty {
-                windowTitle = name
-                break // Assume the first one found is the main one
+                return name // Assume the first one found is the main one
             }
         }
     }
+    return ""
+}
+
+func idleMs() -> Int64 {
+    let seconds = CGEventSource.secondsSinceLastEventType(.combinedSessionState, eventType: CGEventType(rawValue: ~0)!)
+    return Int64(seconds * 1000)
+}
+
+func sample() -> FocusOutput? {
+    guard let app = NSWorkspace.shared.frontmostApplication else {
+        return nil
+    }
+    let pid = Int(app.processIdentifier)
+    return FocusOutput(
+        ts_ms: Int64(Date().timeIntervalSince1970 * 1000),
+        app_name: app.localizedName ?? "",
+        bundle_id: app.bundleIdentifier ?? "",
+        pid: pid,
+        window_title: windowTitle(for: pid),
+        idle_ms: idleMs()
+    )
 }
 
-let output = FocusOutput(ts_ms: tsMs, app_name: appName, bundle_id: bundleId, pid: pid, window_title: windowTitle)
 let encoder = JSONEncoder()
-if let data = try? encoder.encode(output) {
-    if let json = String(data: data, encoding: .utf8) {
+
+func emit(_ output: FocusOutput) {
+    if let data = try? encoder.encode(output), let json = String(data: data, encoding: .utf8) {
         print(json)
+        fflush(stdout)
+    }
+}
+
+if CommandLine.arguments.contains("--stream") {
+    // Stay resident and print one JSON line per focus change. Running the main
+    // run loop keeps frontmostApplication up to date without a process spawn
+    // per sample. A periodic heartbeat carries idle_ms even when focus is
+    // unchanged.
+    let heartbeatMs: Int64 = 10_000
+    var last: FocusOutput?
+    while true {
+        if let current = sample() {
+            let changed = last == nil || last!.pid != current.pid || last!.bundle_id != current.bundle_id || last!.window_title != current.window_title
+            if changed || current.ts_ms - last!.ts_ms >= heartbeatMs {
+                emit(current)
+                last = current
+            }
+        }
+        RunLoop.main.run(until: Date().addingTimeInterval(0.5))
     }
 }
+
+guard let output = sample() else {
+    exit(1)
+}
+emit(output)
@@ -21,6 +21,7 @@ class ActionType(str, Enum):
     TASK_BREAKDOWN = "TASK_BREAKDOWN"
     REST_REMINDER = "REST_REMINDER"
     REFRAME = "REFRAME"
+    AMBIENT = "AMBIENT"
 
 
 class Context(BaseModel):
@@ -98,6 +98,8 @@ class BanditPolicy(Policy):
             return "If the task feels large, try breaking it into 2-3 small steps."
         if action == ActionType.REFRAME:
             return "Maybe try a different angle. Start with the easiest part?"
+        if action == ActionType.AMBIENT:
+            return "Still here with you."
         if action == ActionType.ENCOURAGE:
             if app_name:
                 return f"Nice pace in {app_name}. Keep it up."
@@ -129,6 +131,8 @@ class BanditPolicy(Policy):
             return 3.0
         if action == ActionType.REFRAME:
             return 2.5
+        if action == ActionType.AMBIENT:
+            return 0.1
         return 1.0
 
     def _feedback_reward(self, feedback: str) -> float:
@@ -84,6 +84,7 @@ class OllamaPolicy(Policy):
             switch_count = str(context.switch_count or 0)
         no_progress_minutes = context.signals.get("no_progress_minutes", "0")
         focus_state = context.focus_state or context.signals.get("focus_state", "UNKNOWN")
+        focus_pattern = context.signals.get("focus_pattern", "none")
         hour_of_day = context.signals.get("hour_of_day", "")
         user_text = context.user_text
         mode = context.mode
@@ -105,6 +106,7 @@ Current Context:
 - Focus State: {focus_state}
 - App Switch Count: {switch_count}
 - No-Progress Minutes: {no_progress_minutes}
+- Switch Pattern: {focus_pattern} (ping_pong: toggling between two apps, peek: brief glances at distracting apps, slow_return: long time away from productive apps)
 - Focus Duration Minutes: {focus_minutes}
 - Current App: {app_name}
 - Window Title: {window_title}
@@ -117,18 +119,19 @@ Always prioritize the user's input text. If input is present and meaningful, res
 If input is empty or signals are weak, prefer DO_NOT_DISTURB instead of forcing a suggestion.
 Use non-judgmental language; avoid commands and absolute judgments. Use gentle suggestions ("也许/可以/要不要").
 Keep interventions low-frequency; if unsure, choose DO_NOT_DISTURB.
+AMBIENT only tints the floating ball without any popup; use it for a barely noticeable nudge when a message would interrupt.
 If late night (hour 23-5), you may offer quiet companionship or a short reflection prompt, but do not push tasks.
 Use the User Profile and Recent Memory to personalize without sounding like monitoring.
 
 Output Format (JSON only):
 {{
-  "action_type": "DO_NOT_DISTURB" | "ENCOURAGE" | "TASK_BREAKDOWN" | "REST_REMINDER" | "REFRAME",
+  "action_type": "DO_NOT_DISTURB" | "ENCOURAGE" | "TASK_BREAKDOWN" | "REST_REMINDER" | "REFRAME" | "AMBIENT",
   "message": "A short, friendly message to the user (in Chinese)",
   "confidence": 0.0 to 1.0,
   "cost": 0.0 to 1.0 (interruption cost),
   "risk_level": "LOW" | "MEDIUM" | "HIGH",
   "reason": "One short sentence citing concrete signals (e.g., focus_state=FOCUSED, switch_count=1)",
-  "state": "FOCUSED" | "LIGHT" | "DISTRACTED" | "NO_PROGRESS" | "UNKNOWN"
+  "state": "FOCUSED" | "LIGHT" | "DISTRACTED" | "NO_PROGRESS" | "PING_PONG" | "PEEKING" | "SLOW_RETURN" | "UNKNOWN"
 }}
 """
 
@@ -0,0 +1,161 @@
+// Command always-sim replays a recorded day or a scripted scenario through the
+// focus monitor, decision pipeline and gateway on a virtual clock and prints
+// which interventions would have fired.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"always/core/internal/db"
+	"always/core/internal/models"
+	"always/core/internal/sim"
+)
+
+type settingFlags map[string]string
+
+func (s settingFlags) String() string {
+	return fmt.Sprint(map[string]string(s))
+}
+
+func (s settingFlags) Set(raw string) error {
+	key, value, ok := strings.Cut(raw, "=")
+	if !ok || strings.TrimSpace(key) == "" {
+		return fmt.Errorf("expected key=value, got %q", raw)
+	}
+	s[strings.TrimSpace(key)] = value
+	return nil
+}
+
+func main() {
+	overrides := settingFlags{}
+	scenarioPath := flag.String("scenario", "", "scripted scenario JSON file")
+	dbPath := flag.String("db", "", "database to replay a recorded day from")
+	date := flag.String("date", "", "local date to replay with -db (YYYY-MM-DD, default today)")
+	mode := flag.String("mode", "", "decision mode (LIGHT|DND); overrides the scenario")
+	every := flag.Duration("every", 0, "decision interval; overrides the scenario")
+	aiURL := flag.String("ai-url", "", "use a running AI service instead of the built-in policy")
+	speed := flag.Float64("speed", 0, "virtual seconds per real second (0 = as fast as possible)")
+	simDB := flag.String("sim-db", "", "keep the simulated database at this path")
+	jsonOut := flag.Bool("json", false, "print steps and summary as JSON lines")
+	all := flag.Bool("all", false, "print every decision tick, not only proposed interventions")
+	verbose := flag.Bool("v", false, "log service output to stderr")
+	flag.Var(overrides, "set", "override a setting, key=value (repeatable)")
+	flag.Parse()
+
+	scenario, err := loadScenario(*scenarioPath, *dbPath, *date)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "always-sim:", err)
+		os.Exit(2)
+	}
+	if *mode != "" {
+		scenario.Mode = models.Mode(strings.ToUpper(*mode))
+	}
+	if *every > 0 {
+		scenario.DecideEverySeconds = int64(every.Seconds())
+	}
+	if *every > 0 || *mode != "" {
+		scenario.Normalize()
+	}
+
+	opts := sim.Options{
+		AIURL:     *aiURL,
+		DBPath:    *simDB,
+		Speed:     *speed,
+		Overrides: overrides,
+	}
+	if *verbose {
+		opts.Logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if !*jsonOut {
+		fmt.Fprintln(table, "TIME\tSTATE\tAPP\tSWITCHES\tPROPOSED\tFINAL\tREASON")
+	}
+	summary, err := sim.Run(scenario, opts, func(step sim.Step) {
+		if !*all && (step.Proposed == "" || step.Proposed == models.ActionDoNotDisturb) {
+			return
+		}
+		if *jsonOut {
+			_ = encoder.Encode(step)
+			return
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%d\t%s\t%s\t%s\n",
+			step.Time, step.FocusState, dash(step.AppName), step.SwitchCount,
+			dash(string(step.Proposed)), step.Final, dash(step.Reason))
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "always-sim:", err)
+		os.Exit(1)
+	}
+	if *jsonOut {
+		_ = encoder.Encode(map[string]any{"summary": summary})
+		return
+	}
+	table.Flush()
+	printSummary(summary)
+}
+
+func loadScenario(scenarioPath string, dbPath string, date string) (*sim.Scenario, error) {
+	switch {
+	case scenarioPath != "" && dbPath != "":
+		return nil, fmt.Errorf("use either -scenario or -db, not both")
+	case scenarioPath != "":
+		return sim.LoadScenario(scenarioPath)
+	case dbPath != "":
+		day := time.Now()
+		if date != "" {
+			parsed, err := time.ParseInLocation("2006-01-02", date, time.Local)
+			if err != nil {
+				return nil, fmt.Errorf("invalid -date: %w", err)
+			}
+			day = parsed
+		}
+		if _, err := os.Stat(dbPath); err != nil {
+			return nil, err
+		}
+		store, err := db.Open(dbPath)
+		if err != nil {
+			return nil, err
+		}
+		defer store.DB().Close()
+		return sim.RecordedDay(store, day)
+	default:
+		return nil, fmt.Errorf("one of -scenario or -db is required")
+	}
+}
+
+func printSummary(summary sim.Summary) {
+	fmt.Printf("\nticks=%d proposed=%d fired=%d\n", summary.Ticks, summary.Proposed, summary.Fired)
+	printCounts("fired", summary.ByAction)
+	printCounts("suppressed", summary.Suppressed)
+	for key, reason := range summary.Rejected {
+		fmt.Printf("warning: setting %s rejected: %s\n", key, reason)
+	}
+}
+
+func printCounts(label string, counts map[string]int) {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Printf("  %s %-24s %d\n", label, key, counts[key])
+	}
+}
+
+func dash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
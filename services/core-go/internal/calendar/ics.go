@@ -0,0 +1,323 @@
+package calendar
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event is one VEVENT. Recurring events keep their rule and are expanded on
+// demand.
+type Event struct {
+	UID     string
+	Summary string
+	Start   time.Time
+	End     time.Time
+	AllDay  bool
+	Rule    *Recurrence
+	ExDates []time.Time
+	// ExDays holds the EXDATEs given as plain dates, each of which removes
+	// the occurrence starting on that day whatever its time.
+	ExDays []time.Time
+	// RecurrenceID is set on an event that replaces one occurrence of the
+	// recurring event with the same UID.
+	RecurrenceID time.Time
+	// Busy is false for cancelled events and events marked TRANSPARENT.
+	Busy bool
+}
+
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// Parse reads the VEVENTs of an iCalendar stream. Components other than
+// VEVENT, such as VALARM or VTIMEZONE, are skipped; time zones are resolved
+// by their TZID through the system zone database, with the common Windows
+// zone names mapped to IANA ones. A VEVENT that does not parse is skipped too,
+// so one bad entry does not hide the rest of the calendar: the other events
+// are returned together with an error listing the skipped ones.
+func Parse(r io.Reader) ([]Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+	var events []Event
+	var skipped []error
+	var current *Event
+	// broken is the first error in the current VEVENT.
+	var broken error
+	var durations []string
+	depth := 0
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		prop, err := parseProperty(line)
+		if err != nil {
+			if current == nil {
+				skipped = append(skipped, fmt.Errorf("line %d: %w", i+1, err))
+			} else if broken == nil {
+				broken = fmt.Errorf("line %d: %w", i+1, err)
+			}
+			continue
+		}
+		switch {
+		case prop.name == "BEGIN" && strings.EqualFold(prop.value, "VEVENT") && current == nil:
+			current = &Event{Busy: true}
+			broken = nil
+			durations = durations[:0]
+			continue
+		case prop.name == "END" && strings.EqualFold(prop.value, "VEVENT") && current != nil && depth == 0:
+			if broken == nil {
+				broken = finishEvent(current, durations)
+			}
+			if broken != nil {
+				skipped = append(skipped, fmt.Errorf("event %q: %w", current.UID, broken))
+			} else {
+				events = append(events, *current)
+			}
+			current = nil
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		switch prop.name {
+		case "BEGIN":
+			depth++
+			continue
+		case "END":
+			depth = max(depth-1, 0)
+			continue
+		}
+		if depth > 0 || broken != nil {
+			continue
+		}
+		if err := applyProperty(current, prop, &durations); err != nil {
+			broken = fmt.Errorf("line %d: %w", i+1, err)
+		}
+	}
+	if current != nil {
+		skipped = append(skipped, fmt.Errorf("unterminated VEVENT %q", current.UID))
+	}
+	return events, errors.Join(skipped...)
+}
+
+func applyProperty(event *Event, prop property, durations *[]string) error {
+	switch prop.name {
+	case "UID":
+		event.UID = prop.value
+	case "SUMMARY":
+		event.Summary = unescapeText(prop.value)
+	case "DTSTART":
+		start, allDay, err := parseDateTime(prop.value, prop.params)
+		if err != nil {
+			return fmt.Errorf("DTSTART: %w", err)
+		}
+		event.Start = start
+		event.AllDay = allDay
+	case "DTEND":
+		end, _, err := parseDateTime(prop.value, prop.params)
+		if err != nil {
+			return fmt.Errorf("DTEND: %w", err)
+		}
+		event.End = end
+	case "DURATION":
+		*durations = append(*durations, prop.value)
+	case "RRULE":
+		rule, err := parseRecurrence(prop.value)
+		if err != nil {
+			return fmt.Errorf("RRULE: %w", err)
+		}
+		event.Rule = rule
+	case "EXDATE":
+		for _, value := range strings.Split(prop.value, ",") {
+			exdate, allDay, err := parseDateTime(value, prop.params)
+			if err != nil {
+				return fmt.Errorf("EXDATE: %w", err)
+			}
+			if allDay {
+				event.ExDays = append(event.ExDays, exdate)
+			} else {
+				event.ExDates = append(event.ExDates, exdate)
+			}
+		}
+	case "RECURRENCE-ID":
+		id, _, err := parseDateTime(prop.value, prop.params)
+		if err != nil {
+			return fmt.Errorf("RECURRENCE-ID: %w", err)
+		}
+		event.RecurrenceID = id
+	case "STATUS":
+		if strings.EqualFold(prop.value, "CANCELLED") {
+			event.Busy = false
+		}
+	case "TRANSP":
+		if strings.EqualFold(prop.value, "TRANSPARENT") {
+			event.Busy = false
+		}
+	}
+	return nil
+}
+
+func finishEvent(event *Event, durations []string) error {
+	if event.Start.IsZero() {
+		return fmt.Errorf("missing DTSTART")
+	}
+	if event.End.IsZero() && len(durations) > 0 {
+		duration, err := parseDuration(durations[len(durations)-1])
+		if err != nil {
+			return fmt.Errorf("DURATION: %w", err)
+		}
+		event.End = event.Start.Add(duration)
+	}
+	if event.End.IsZero() {
+		if event.AllDay {
+			event.End = event.Start.AddDate(0, 0, 1)
+		} else {
+			event.End = event.Start
+		}
+	}
+	if event.End.Before(event.Start) {
+		return fmt.Errorf("ends before it starts")
+	}
+	if rule := event.Rule; rule != nil && rule.untilDate {
+		u := rule.Until
+		rule.Until = time.Date(u.Year(), u.Month(), u.Day(), 23, 59, 59, 0, event.Start.Location())
+		rule.untilDate = false
+	}
+	return nil
+}
+
+// unfold joins continuation lines, which start with a space or a tab.
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read calendar: %w", err)
+	}
+	return lines, nil
+}
+
+func parseProperty(line string) (property, error) {
+	prop := property{params: map[string]string{}}
+	quoted := false
+	colon := -1
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		}
+		if r == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return prop, fmt.Errorf("missing ':' in %q", line)
+	}
+	prop.value = line[colon+1:]
+	parts := strings.Split(line[:colon], ";")
+	prop.name = strings.ToUpper(strings.TrimSpace(parts[0]))
+	for _, part := range parts[1:] {
+		key, value, _ := strings.Cut(part, "=")
+		prop.params[strings.ToUpper(strings.TrimSpace(key))] = strings.Trim(value, `"`)
+	}
+	return prop, nil
+}
+
+// parseDateTime reads a DATE or DATE-TIME value. UTC values end in Z, TZID
+// names a zone for both kinds, and anything else is local time. A TZID that
+// does not resolve is an error.
+func parseDateTime(value string, params map[string]string) (time.Time, bool, error) {
+	value = strings.TrimSpace(value)
+	if strings.HasSuffix(value, "Z") && len(value) != 8 {
+		t, err := time.Parse("20060102T150405Z", value)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid date-time %q", value)
+		}
+		return t, false, nil
+	}
+	loc := time.Local
+	if tzid := params["TZID"]; tzid != "" {
+		zone, err := loadZone(tzid)
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		loc = zone
+	}
+	if strings.EqualFold(params["VALUE"], "DATE") || len(value) == 8 {
+		t, err := time.ParseInLocation("20060102", value, loc)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid date %q", value)
+		}
+		return t, true, nil
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid date-time %q", value)
+	}
+	return t, false, nil
+}
+
+// parseDuration reads an RFC 5545 duration such as PT1H30M or P1D.
+func parseDuration(value string) (time.Duration, error) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "+")
+	if strings.HasPrefix(value, "-") || !strings.HasPrefix(value, "P") {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	var total time.Duration
+	inTime := false
+	number := ""
+	for _, r := range value[1:] {
+		switch {
+		case r == 'T':
+			inTime = true
+		case r >= '0' && r <= '9':
+			number += string(r)
+		default:
+			n, err := strconv.Atoi(number)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q", value)
+			}
+			number = ""
+			switch {
+			case r == 'W':
+				total += time.Duration(n) * 7 * 24 * time.Hour
+			case r == 'D':
+				total += time.Duration(n) * 24 * time.Hour
+			case r == 'H' && inTime:
+				total += time.Duration(n) * time.Hour
+			case r == 'M' && inTime:
+				total += time.Duration(n) * time.Minute
+			case r == 'S' && inTime:
+				total += time.Duration(n) * time.Second
+			default:
+				return 0, fmt.Errorf("invalid duration %q", value)
+			}
+		}
+	}
+	if number != "" {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	return total, nil
+}
+
+func unescapeText(value string) string {
+	replacer := strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`)
+	return replacer.Replace(value)
+}
@@ -0,0 +1,180 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// parseEvents wraps VEVENT lines in a VCALENDAR and parses it.
+func parseEvents(t *testing.T, lines ...string) []Event {
+	t.Helper()
+	body := strings.Join(append(append([]string{"BEGIN:VCALENDAR"}, lines...), "END:VCALENDAR"), "\r\n")
+	events, err := Parse(strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	return events
+}
+
+func TestParseFoldedAndEscapedText(t *testing.T) {
+	events := parseEvents(t,
+		"BEGIN:VEVENT",
+		"UID:fold",
+		"SUMMARY:Weekly",
+		"  sync\\, with",
+		"\t notes",
+		"DTSTART:20261012T100000",
+		"BEGIN:VALARM",
+		"SUMMARY:ignored",
+		"END:VALARM",
+		"END:VEVENT",
+	)
+	if len(events) != 1 {
+		t.Fatalf("events = %d, want 1", len(events))
+	}
+	if got, want := events[0].Summary, "Weekly sync, with notes"; got != want {
+		t.Fatalf("summary = %q, want %q", got, want)
+	}
+}
+
+func TestParseEventEnd(t *testing.T) {
+	local := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 10, day, hour, minute, 0, 0, time.Local)
+	}
+	cases := []struct {
+		name  string
+		lines []string
+		start time.Time
+		end   time.Time
+	}{
+		{"dtend", []string{"DTSTART:20261012T100000", "DTEND:20261012T104500"}, local(12, 10, 0), local(12, 10, 45)},
+		{"duration", []string{"DTSTART:20261012T100000", "DURATION:PT1H30M"}, local(12, 10, 0), local(12, 11, 30)},
+		{"duration in days", []string{"DTSTART:20261012T100000", "DURATION:P1DT2H"}, local(12, 10, 0), local(13, 12, 0)},
+		{"dtend wins over duration", []string{"DTSTART:20261012T100000", "DURATION:PT2H", "DTEND:20261012T103000"}, local(12, 10, 0), local(12, 10, 30)},
+		{"all day", []string{"DTSTART;VALUE=DATE:20261012"}, local(12, 0, 0), local(13, 0, 0)},
+		{"no end", []string{"DTSTART:20261012T100000"}, local(12, 10, 0), local(12, 10, 0)},
+		{"utc", []string{"DTSTART:20261012T100000Z", "DURATION:PT15M"}, time.Date(2026, 10, 12, 10, 0, 0, 0, time.UTC), time.Date(2026, 10, 12, 10, 15, 0, 0, time.UTC)},
+	}
+	for _, tc := range cases {
+		lines := append(append([]string{"BEGIN:VEVENT", "UID:" + tc.name}, tc.lines...), "END:VEVENT")
+		events := parseEvents(t, lines...)
+		if len(events) != 1 {
+			t.Errorf("%s: events = %d, want 1", tc.name, len(events))
+			continue
+		}
+		if !events[0].Start.Equal(tc.start) || !events[0].End.Equal(tc.end) {
+			t.Errorf("%s: %s-%s, want %s-%s", tc.name, events[0].Start, events[0].End, tc.start, tc.end)
+		}
+	}
+}
+
+func TestParseDateTimeZones(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("zone database unavailable: %v", err)
+	}
+	cases := []struct {
+		name   string
+		value  string
+		params map[string]string
+		want   time.Time
+		allDay bool
+	}{
+		{"date-time with tzid", "20261012T100000", map[string]string{"TZID": "America/New_York"}, time.Date(2026, 10, 12, 10, 0, 0, 0, newYork), false},
+		{"date with tzid", "20261012", map[string]string{"TZID": "America/New_York", "VALUE": "DATE"}, time.Date(2026, 10, 12, 0, 0, 0, 0, newYork), true},
+		{"floating date", "20261012", nil, time.Date(2026, 10, 12, 0, 0, 0, 0, time.Local), true},
+		{"windows tzid", "20261012T100000", map[string]string{"TZID": "Eastern Standard Time"}, time.Date(2026, 10, 12, 10, 0, 0, 0, newYork), false},
+		{"utc ignores tzid", "20261012T100000Z", map[string]string{"TZID": "America/New_York"}, time.Date(2026, 10, 12, 10, 0, 0, 0, time.UTC), false},
+		{"utc ignores unknown tzid", "20261012T100000Z", map[string]string{"TZID": "Mars/Olympus"}, time.Date(2026, 10, 12, 10, 0, 0, 0, time.UTC), false},
+	}
+	for _, tc := range cases {
+		got, allDay, err := parseDateTime(tc.value, tc.params)
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if !got.Equal(tc.want) || allDay != tc.allDay {
+			t.Errorf("%s: %s all-day %v, want %s all-day %v", tc.name, got, allDay, tc.want, tc.allDay)
+		}
+	}
+}
+
+func TestUnknownTZIDIsAnError(t *testing.T) {
+	for _, tzid := range []string{"Mars/Olympus", "Martian Standard Time"} {
+		if got, _, err := parseDateTime("20261012T100000", map[string]string{"TZID": tzid}); err == nil {
+			t.Errorf("TZID %q: parsed as %s, want an error", tzid, got)
+		}
+	}
+}
+
+func TestWindowsZonesResolve(t *testing.T) {
+	if _, err := time.LoadLocation("America/New_York"); err != nil {
+		t.Skipf("zone database unavailable: %v", err)
+	}
+	for windows, iana := range windowsZones {
+		if _, err := time.LoadLocation(iana); err != nil {
+			t.Errorf("%s: %s does not load: %v", windows, iana, err)
+		}
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	cases := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"PT1H30M", 90 * time.Minute, true},
+		{"+PT45S", 45 * time.Second, true},
+		{"P2W", 14 * 24 * time.Hour, true},
+		{"P1DT1M", 24*time.Hour + time.Minute, true},
+		{"-PT1H", 0, false},
+		{"PT1X", 0, false},
+		{"P1H", 0, false},
+		{"PT1", 0, false},
+		{"1H", 0, false},
+	}
+	for _, tc := range cases {
+		got, err := parseDuration(tc.value)
+		if (err == nil) != tc.ok || got != tc.want {
+			t.Errorf("parseDuration(%q) = %s, %v, want %s, ok %v", tc.value, got, err, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestParseSkipsBrokenEvents(t *testing.T) {
+	good := []string{"BEGIN:VEVENT", "UID:good", "DTSTART:20261012T100000", "END:VEVENT"}
+	cases := map[string][]string{
+		"missing dtstart":   {"BEGIN:VEVENT", "UID:bad", "END:VEVENT"},
+		"ends before start": {"BEGIN:VEVENT", "UID:bad", "DTSTART:20261012T100000", "DTEND:20261012T090000", "END:VEVENT"},
+		"unsupported rrule": {"BEGIN:VEVENT", "UID:bad", "DTSTART:20261012T100000", "RRULE:FREQ=HOURLY", "END:VEVENT"},
+		"bad exdate":        {"BEGIN:VEVENT", "UID:bad", "DTSTART:20261012T100000", "EXDATE:yesterday", "END:VEVENT"},
+		"unknown tzid":      {"BEGIN:VEVENT", "UID:bad", "DTSTART;TZID=Mars/Olympus:20261012T100000", "END:VEVENT"},
+		"malformed line":    {"BEGIN:VEVENT", "UID:bad", "DTSTART:20261012T100000", "this line has no colon", "END:VEVENT"},
+		"unterminated":      {"BEGIN:VEVENT", "UID:bad", "DTSTART:20261012T100000"},
+	}
+	for name, broken := range cases {
+		lines := append(append(append([]string{"BEGIN:VCALENDAR"}, good...), broken...), good...)
+		if name != "unterminated" {
+			lines = append(lines, "END:VCALENDAR")
+		}
+		events, err := Parse(strings.NewReader(strings.Join(lines, "\r\n")))
+		if err == nil {
+			t.Errorf("%s: no error reported", name)
+		}
+		want := 2
+		if name == "unterminated" {
+			want = 1
+		}
+		if len(events) != want {
+			t.Errorf("%s: %d events kept, want %d", name, len(events), want)
+			continue
+		}
+		for _, event := range events {
+			if event.UID != "good" {
+				t.Errorf("%s: kept %q", name, event.UID)
+			}
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package calendar
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxPeriods bounds how many periods of a rule are walked for one query.
+const maxPeriods = 20000
+
+// Recurrence is the subset of RRULE the core understands: FREQ, INTERVAL,
+// COUNT, UNTIL, BYDAY and BYMONTHDAY. Other parts are ignored.
+type Recurrence struct {
+	Freq       string
+	Interval   int
+	Count      int
+	Until      time.Time
+	ByDay      []weekdayNum
+	ByMonthDay []int
+	// untilDate is set when UNTIL is a DATE, which covers that whole day in
+	// the zone of DTSTART; finishEvent resolves Until once DTSTART is known.
+	untilDate bool
+}
+
+// weekdayNum is a BYDAY entry such as MO or -1FR; N is 0 when every such
+// weekday of the period matches.
+type weekdayNum struct {
+	N       int
+	Weekday time.Weekday
+}
+
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+func parseRecurrence(value string) (*Recurrence, error) {
+	rule := &Recurrence{Interval: 1}
+	for _, part := range strings.Split(value, ";") {
+		key, val, ok := strings.Cut(part, "=")
+		if !ok {
+			continue
+		}
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			rule.Freq = strings.ToUpper(val)
+		case "INTERVAL":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid INTERVAL %q", val)
+			}
+			rule.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid COUNT %q", val)
+			}
+			rule.Count = n
+		case "UNTIL":
+			until, date, err := parseDateTime(val, nil)
+			if err != nil {
+				return nil, fmt.Errorf("invalid UNTIL %q", val)
+			}
+			rule.Until = until
+			rule.untilDate = date
+		case "BYDAY":
+			for _, item := range strings.Split(val, ",") {
+				item = strings.ToUpper(strings.TrimSpace(item))
+				if len(item) < 2 {
+					return nil, fmt.Errorf("invalid BYDAY %q", val)
+				}
+				weekday, ok := weekdayCodes[item[len(item)-2:]]
+				if !ok {
+					return nil, fmt.Errorf("invalid BYDAY %q", val)
+				}
+				entry := weekdayNum{Weekday: weekday}
+				if prefix := item[:len(item)-2]; prefix != "" {
+					n, err := strconv.Atoi(prefix)
+					if err != nil {
+						return nil, fmt.Errorf("invalid BYDAY %q", val)
+					}
+					entry.N = n
+				}
+				rule.ByDay = append(rule.ByDay, entry)
+			}
+		case "BYMONTHDAY":
+			for _, item := range strings.Split(val, ",") {
+				n, err := strconv.Atoi(strings.TrimSpace(item))
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return nil, fmt.Errorf("invalid BYMONTHDAY %q", val)
+				}
+				rule.ByMonthDay = append(rule.ByMonthDay, n)
+			}
+		}
+	}
+	switch rule.Freq {
+	case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+	default:
+		return nil, fmt.Errorf("unsupported FREQ %q", rule.Freq)
+	}
+	return rule, nil
+}
+
+// Occurrence is one concrete instance of an event.
+type Occurrence struct {
+	Summary string    `json:"summary,omitempty"`
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+}
+
+// Occurrences returns the instances of the event that overlap [from, to),
+// in start order.
+func (e Event) Occurrences(from, to time.Time) []Occurrence {
+	duration := e.End.Sub(e.Start)
+	overlaps := func(start time.Time) bool {
+		end := start.Add(duration)
+		return start.Before(to) && (end.After(from) || (duration == 0 && !start.Before(from)))
+	}
+	if e.Rule == nil {
+		if overlaps(e.Start) {
+			return []Occurrence{{Summary: e.Summary, Start: e.Start, End: e.End}}
+		}
+		return nil
+	}
+
+	// Without COUNT every instance stands alone, so the walk starts near the
+	// window instead of at DTSTART.
+	first := 0
+	if e.Rule.Count == 0 {
+		first = e.Rule.periodBefore(e.Start, from.Add(-duration))
+	}
+	var out []Occurrence
+	count := 0
+	for period := first; period < first+maxPeriods; period++ {
+		candidates := e.Rule.candidates(e.Start, period)
+		if len(candidates) == 0 {
+			continue
+		}
+		for _, start := range candidates {
+			if start.Before(e.Start) {
+				continue
+			}
+			if !e.Rule.Until.IsZero() && start.After(e.Rule.Until) {
+				return out
+			}
+			if !start.Before(to) {
+				return out
+			}
+			count++
+			if e.Rule.Count > 0 && count > e.Rule.Count {
+				return out
+			}
+			if e.excluded(start) || !overlaps(start) {
+				continue
+			}
+			out = append(out, Occurrence{Summary: e.Summary, Start: start, End: start.Add(duration)})
+		}
+	}
+	return out
+}
+
+// periodBefore returns a period of the rule that starts no later than t, close
+// enough that walking from it reaches t quickly.
+func (r *Recurrence) periodBefore(dtstart time.Time, t time.Time) int {
+	if !t.After(dtstart) {
+		return 0
+	}
+	var n int
+	switch r.Freq {
+	case "DAILY":
+		n = int(t.Sub(dtstart) / (24 * time.Hour))
+	case "WEEKLY":
+		n = int(t.Sub(dtstart) / (7 * 24 * time.Hour))
+	case "MONTHLY":
+		n = (t.Year()-dtstart.Year())*12 + int(t.Month()-dtstart.Month())
+	case "YEARLY":
+		n = t.Year() - dtstart.Year()
+	}
+	// One period of slack covers daylight saving shifts and partial weeks.
+	return max(n/r.Interval-1, 0)
+}
+
+// excluded reports whether an EXDATE removes the occurrence at start. A
+// date-only EXDATE matches by the day the occurrence starts on in its own zone.
+func (e Event) excluded(start time.Time) bool {
+	for _, exdate := range e.ExDates {
+		if exdate.Equal(start) {
+			return true
+		}
+	}
+	year, month, day := start.Date()
+	for _, exday := range e.ExDays {
+		if y, m, d := exday.Date(); y == year && m == month && d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// candidates lists the instance starts of one period of the rule, keeping the
+// wall-clock time of dtstart.
+func (r *Recurrence) candidates(dtstart time.Time, period int) []time.Time {
+	step := period * r.Interval
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, dtstart.Hour(), dtstart.Minute(), dtstart.Second(), 0, dtstart.Location())
+	}
+	var out []time.Time
+	switch r.Freq {
+	case "DAILY":
+		day := dtstart.AddDate(0, 0, step)
+		if len(r.ByDay) > 0 && !r.matchesWeekday(day.Weekday()) {
+			return nil
+		}
+		out = append(out, at(day.Year(), day.Month(), day.Day()))
+	case "WEEKLY":
+		// Weeks start on Monday, the RFC 5545 default for WKST.
+		offset := (int(dtstart.Weekday()) + 6) % 7
+		monday := dtstart.AddDate(0, 0, step*7-offset)
+		if len(r.ByDay) == 0 {
+			day := monday.AddDate(0, 0, offset)
+			return []time.Time{at(day.Year(), day.Month(), day.Day())}
+		}
+		for i := 0; i < 7; i++ {
+			day := monday.AddDate(0, 0, i)
+			if r.matchesWeekday(day.Weekday()) {
+				out = append(out, at(day.Year(), day.Month(), day.Day()))
+			}
+		}
+	case "MONTHLY":
+		first := time.Date(dtstart.Year(), dtstart.Month()+time.Month(step), 1, 0, 0, 0, 0, dtstart.Location())
+		out = r.monthCandidates(first, dtstart.Day(), at)
+	case "YEARLY":
+		first := time.Date(dtstart.Year()+step, dtstart.Month(), 1, 0, 0, 0, 0, dtstart.Location())
+		out = r.monthCandidates(first, dtstart.Day(), at)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Before(out[j]) })
+	return out
+}
+
+func (r *Recurrence) monthCandidates(first time.Time, defaultDay int, at func(int, time.Month, int) time.Time) []time.Time {
+	year, month := first.Year(), first.Month()
+	days := time.Date(year, month+1, 0, 0, 0, 0, 0, first.Location()).Day()
+	var out []time.Time
+	switch {
+	case len(r.ByMonthDay) > 0:
+		for _, day := range r.ByMonthDay {
+			if day < 0 {
+				day = days + day + 1
+			}
+			if day >= 1 && day <= days {
+				out = append(out, at(year, month, day))
+			}
+		}
+	case len(r.ByDay) > 0:
+		for _, entry := range r.ByDay {
+			var matches []int
+			for day := 1; day <= days; day++ {
+				if time.Date(year, month, day, 0, 0, 0, 0, first.Location()).Weekday() == entry.Weekday {
+					matches = append(matches, day)
+				}
+			}
+			switch {
+			case entry.N == 0:
+				for _, day := range matches {
+					out = append(out, at(year, month, day))
+				}
+			case entry.N > 0 && entry.N <= len(matches):
+				out = append(out, at(year, month, matches[entry.N-1]))
+			case entry.N < 0 && -entry.N <= len(matches):
+				out = append(out, at(year, month, matches[len(matches)+entry.N]))
+			}
+		}
+	default:
+		// A month without the start day, e.g. the 31st, has no instance.
+		if defaultDay <= days {
+			out = append(out, at(year, month, defaultDay))
+		}
+	}
+	return out
+}
+
+func (r *Recurrence) matchesWeekday(weekday time.Weekday) bool {
+	for _, entry := range r.ByDay {
+		if entry.Weekday == weekday {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,175 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+// starts lists the occurrence starts of the single recurring event in lines,
+// after RECURRENCE-ID overrides are applied, within [from, to).
+func starts(t *testing.T, from, to time.Time, lines ...string) []time.Time {
+	t.Helper()
+	var out []time.Time
+	for _, event := range applyOverrides(parseEvents(t, lines...)) {
+		for _, occurrence := range event.Occurrences(from, to) {
+			out = append(out, occurrence.Start)
+		}
+	}
+	return out
+}
+
+func TestOccurrences(t *testing.T) {
+	local := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2026, month, day, hour, minute, 0, 0, time.Local)
+	}
+	daily := func(extra ...string) []string {
+		lines := []string{"BEGIN:VEVENT", "UID:daily", "DTSTART:20261012T100000", "DURATION:PT30M"}
+		return append(append(lines, extra...), "END:VEVENT")
+	}
+	week := [2]time.Time{local(10, 12, 0, 0), local(10, 19, 0, 0)}
+	cases := []struct {
+		name   string
+		lines  []string
+		window [2]time.Time
+		want   []time.Time
+	}{
+		{
+			name:   "count",
+			lines:  daily("RRULE:FREQ=DAILY;COUNT=2"),
+			window: week,
+			want:   []time.Time{local(10, 12, 10, 0), local(10, 13, 10, 0)},
+		},
+		{
+			name:   "exdate still counts toward count",
+			lines:  daily("RRULE:FREQ=DAILY;COUNT=3", "EXDATE:20261013T100000"),
+			window: week,
+			want:   []time.Time{local(10, 12, 10, 0), local(10, 14, 10, 0)},
+		},
+		{
+			name:   "until date-time is inclusive",
+			lines:  daily("RRULE:FREQ=DAILY;UNTIL=20261014T100000"),
+			window: week,
+			want:   []time.Time{local(10, 12, 10, 0), local(10, 13, 10, 0), local(10, 14, 10, 0)},
+		},
+		{
+			name:   "until date covers the whole day",
+			lines:  daily("RRULE:FREQ=DAILY;UNTIL=20261014"),
+			window: week,
+			want:   []time.Time{local(10, 12, 10, 0), local(10, 13, 10, 0), local(10, 14, 10, 0)},
+		},
+		{
+			name:   "until before the next instance",
+			lines:  daily("RRULE:FREQ=DAILY;UNTIL=20261014T095959"),
+			window: week,
+			want:   []time.Time{local(10, 12, 10, 0), local(10, 13, 10, 0)},
+		},
+		{
+			name:   "exdate list",
+			lines:  daily("RRULE:FREQ=DAILY;COUNT=4", "EXDATE:20261013T100000,20261015T100000"),
+			window: week,
+			want:   []time.Time{local(10, 12, 10, 0), local(10, 14, 10, 0)},
+		},
+		{
+			name:   "date-only exdate removes the timed instance",
+			lines:  daily("RRULE:FREQ=DAILY;COUNT=3", "EXDATE;VALUE=DATE:20261013"),
+			window: week,
+			want:   []time.Time{local(10, 12, 10, 0), local(10, 14, 10, 0)},
+		},
+		{
+			name:   "date-only exdate list",
+			lines:  daily("RRULE:FREQ=DAILY;COUNT=4", "EXDATE;VALUE=DATE:20261012,20261015"),
+			window: week,
+			want:   []time.Time{local(10, 13, 10, 0), local(10, 14, 10, 0)},
+		},
+		{
+			name: "recurrence-id moves one instance",
+			lines: append(daily("RRULE:FREQ=DAILY;COUNT=3"),
+				"BEGIN:VEVENT",
+				"UID:daily",
+				"RECURRENCE-ID:20261013T100000",
+				"DTSTART:20261013T150000",
+				"DURATION:PT30M",
+				"END:VEVENT",
+			),
+			window: week,
+			want:   []time.Time{local(10, 12, 10, 0), local(10, 14, 10, 0), local(10, 13, 15, 0)},
+		},
+		{
+			name:   "weekly byday",
+			lines:  daily("RRULE:FREQ=WEEKLY;BYDAY=MO,WE,FR"),
+			window: week,
+			want:   []time.Time{local(10, 12, 10, 0), local(10, 14, 10, 0), local(10, 16, 10, 0)},
+		},
+		{
+			name:   "monthly last friday",
+			lines:  daily("RRULE:FREQ=MONTHLY;BYDAY=-1FR"),
+			window: [2]time.Time{local(10, 1, 0, 0), local(12, 31, 0, 0)},
+			want:   []time.Time{local(10, 30, 10, 0), local(11, 27, 10, 0), local(12, 25, 10, 0)},
+		},
+		{
+			name: "seeks past a start long ago",
+			lines: []string{
+				"BEGIN:VEVENT", "UID:old", "DTSTART:19000101T100000", "DURATION:PT30M",
+				"RRULE:FREQ=DAILY;INTERVAL=2", "END:VEVENT",
+			},
+			window: [2]time.Time{local(10, 12, 0, 0), local(10, 16, 0, 0)},
+			want:   []time.Time{local(10, 13, 10, 0), local(10, 15, 10, 0)},
+		},
+		{
+			name: "seek keeps an instance running into the window",
+			lines: []string{
+				"BEGIN:VEVENT", "UID:late", "DTSTART:19000101T230000", "DURATION:PT2H",
+				"RRULE:FREQ=DAILY", "END:VEVENT",
+			},
+			window: [2]time.Time{local(10, 13, 0, 30), local(10, 13, 1, 0)},
+			want:   []time.Time{local(10, 12, 23, 0)},
+		},
+		{
+			name: "seeks yearly rules",
+			lines: []string{
+				"BEGIN:VEVENT", "UID:birthday", "DTSTART;VALUE=DATE:19000315",
+				"RRULE:FREQ=YEARLY", "END:VEVENT",
+			},
+			window: [2]time.Time{local(1, 1, 0, 0), local(12, 31, 0, 0)},
+			want:   []time.Time{local(3, 15, 0, 0)},
+		},
+	}
+	for _, tc := range cases {
+		got := starts(t, tc.window[0], tc.window[1], tc.lines...)
+		if len(got) != len(tc.want) {
+			t.Errorf("%s: starts = %v, want %v", tc.name, got, tc.want)
+			continue
+		}
+		for i := range got {
+			if !got[i].Equal(tc.want[i]) {
+				t.Errorf("%s: starts = %v, want %v", tc.name, got, tc.want)
+				break
+			}
+		}
+	}
+}
+
+func TestUntilDateFollowsStartZone(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("zone database unavailable: %v", err)
+	}
+	events := parseEvents(t,
+		"BEGIN:VEVENT",
+		"UID:ny",
+		"RRULE:FREQ=DAILY;UNTIL=20261014",
+		"DTSTART;TZID=America/New_York:20261012T210000",
+		"DURATION:PT1H",
+		"END:VEVENT",
+	)
+	if len(events) != 1 {
+		t.Fatalf("events = %d, want 1", len(events))
+	}
+	if want := time.Date(2026, 10, 14, 23, 59, 59, 0, newYork); !events[0].Rule.Until.Equal(want) {
+		t.Fatalf("until = %s, want %s", events[0].Rule.Until, want)
+	}
+	got := events[0].Occurrences(time.Date(2026, 10, 1, 0, 0, 0, 0, newYork), time.Date(2026, 11, 1, 0, 0, 0, 0, newYork))
+	if len(got) != 3 || !got[2].Start.Equal(time.Date(2026, 10, 14, 21, 0, 0, 0, newYork)) {
+		t.Fatalf("occurrences = %v, want three through Oct 14 21:00 New York", got)
+	}
+}
@@ -0,0 +1,205 @@
+package calendar
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	envICSPath = "CALENDAR_ICS_PATH"
+	// lookahead is how far ahead the next meeting is searched for.
+	lookahead = 24 * time.Hour
+)
+
+// Source serves meetings from a local .ics file or a directory of them. Files
+// are re-read whenever their names, sizes or modification times change, so
+// edits by a sync tool show up on the next lookup.
+type Source struct {
+	mu          sync.Mutex
+	logger      *slog.Logger
+	defaultPath string
+	path        string
+	signature   string
+	files       int
+	events      []Event
+	loadedAt    time.Time
+	lastError   string
+}
+
+// Status describes the calendar and the meetings around a moment.
+type Status struct {
+	Path       string `json:"path,omitempty"`
+	Files      int    `json:"files"`
+	Events     int    `json:"events"`
+	LoadedAtMs int64  `json:"loaded_at_ms,omitempty"`
+	LastError  string `json:"last_error,omitempty"`
+	InMeeting  bool   `json:"in_meeting"`
+	// Current is the meeting under way, Next the next one to start within
+	// the lookahead.
+	Current       *Occurrence `json:"current,omitempty"`
+	Next          *Occurrence `json:"next,omitempty"`
+	NextInMinutes *float64    `json:"next_meeting_in_minutes,omitempty"`
+}
+
+// NewSource watches the path in CALENDAR_ICS_PATH, if any.
+func NewSource(logger *slog.Logger) *Source {
+	return &Source{logger: logger, defaultPath: strings.TrimSpace(os.Getenv(envICSPath))}
+}
+
+// Status reloads the calendar if it changed and reports the meetings at now.
+// A non-empty path replaces the CALENDAR_ICS_PATH default.
+func (s *Source) Status(path string, now time.Time) Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path = strings.TrimSpace(path)
+	if path == "" {
+		path = s.defaultPath
+	}
+	if path != s.path {
+		s.path = path
+		s.signature = ""
+		s.files = 0
+		s.events = nil
+		s.lastError = ""
+	}
+	status := Status{Path: path}
+	if path == "" {
+		return status
+	}
+	s.refreshLocked()
+
+	status.Files = s.files
+	status.Events = len(s.events)
+	status.LastError = s.lastError
+	if !s.loadedAt.IsZero() {
+		status.LoadedAtMs = s.loadedAt.UnixMilli()
+	}
+	var upcoming []Occurrence
+	for _, event := range s.events {
+		if !event.Busy || event.AllDay {
+			continue
+		}
+		for _, occurrence := range event.Occurrences(now.Add(-lookahead), now.Add(lookahead)) {
+			switch {
+			case !occurrence.Start.After(now) && occurrence.End.After(now):
+				if status.Current == nil || occurrence.Start.Before(status.Current.Start) {
+					current := occurrence
+					status.Current = &current
+				}
+			case occurrence.Start.After(now):
+				upcoming = append(upcoming, occurrence)
+			}
+		}
+	}
+	status.InMeeting = status.Current != nil
+	if len(upcoming) > 0 {
+		sort.Slice(upcoming, func(i, j int) bool { return upcoming[i].Start.Before(upcoming[j].Start) })
+		next := upcoming[0]
+		minutes := next.Start.Sub(now).Minutes()
+		status.Next = &next
+		status.NextInMinutes = &minutes
+	}
+	return status
+}
+
+func (s *Source) refreshLocked() {
+	files, signature, err := listFiles(s.path)
+	if err != nil {
+		if s.lastError == "" {
+			s.logger.Warn("stat calendar failed", slog.String("path", s.path), slog.Any("error", err))
+		}
+		s.lastError = err.Error()
+		s.signature = ""
+		return
+	}
+	if signature == s.signature {
+		return
+	}
+	s.signature = signature
+
+	var events []Event
+	var errs []string
+	for _, file := range files {
+		// A file with bad entries still contributes the events that parsed.
+		parsed, err := parseFile(file)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", filepath.Base(file), strings.ReplaceAll(err.Error(), "\n", "; ")))
+		}
+		events = append(events, parsed...)
+	}
+	s.events = applyOverrides(events)
+	s.files = len(files)
+	s.loadedAt = time.Now()
+	s.lastError = strings.Join(errs, "; ")
+	if s.lastError != "" {
+		s.logger.Warn("calendar files skipped", slog.String("path", s.path), slog.String("error", s.lastError))
+	}
+	s.logger.Info("calendar loaded",
+		slog.String("path", s.path),
+		slog.Int("files", len(files)),
+		slog.Int("events", len(s.events)))
+}
+
+// listFiles returns the .ics files at path and a signature that changes when
+// any of them is added, removed or modified.
+func listFiles(path string) ([]string, string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, "", err
+	}
+	files := []string{path}
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, "", err
+		}
+		files = files[:0]
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.EqualFold(filepath.Ext(entry.Name()), ".ics") {
+				files = append(files, filepath.Join(path, entry.Name()))
+			}
+		}
+	}
+	var signature strings.Builder
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, "", err
+		}
+		fmt.Fprintf(&signature, "%s|%d|%d\n", file, info.Size(), info.ModTime().UnixNano())
+	}
+	return files, signature.String(), nil
+}
+
+func parseFile(path string) ([]Event, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Parse(file)
+}
+
+// applyOverrides removes the occurrences that a RECURRENCE-ID event replaces
+// from the recurring event with the same UID.
+func applyOverrides(events []Event) []Event {
+	replaced := map[string][]time.Time{}
+	for _, event := range events {
+		if !event.RecurrenceID.IsZero() {
+			replaced[event.UID] = append(replaced[event.UID], event.RecurrenceID)
+		}
+	}
+	for i := range events {
+		if events[i].Rule != nil && events[i].RecurrenceID.IsZero() {
+			events[i].ExDates = append(events[i].ExDates, replaced[events[i].UID]...)
+		}
+	}
+	return events
+}
@@ -0,0 +1,32 @@
+package calendar
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSourceKeepsGoodEventsOfABadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "work.ics")
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT", "UID:standup", "SUMMARY:Standup", "DTSTART:20261012T100000", "DURATION:PT15M", "END:VEVENT",
+		"BEGIN:VEVENT", "UID:pulse", "DTSTART:20261012T090000", "RRULE:FREQ=HOURLY", "END:VEVENT",
+		"END:VCALENDAR",
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\r\n")), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	source := NewSource(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	status := source.Status(path, time.Date(2026, 10, 12, 10, 5, 0, 0, time.Local))
+	if status.Events != 1 || !status.InMeeting || status.Current == nil || status.Current.Summary != "Standup" {
+		t.Fatalf("status = %+v, want the standup under way", status)
+	}
+	if !strings.Contains(status.LastError, "work.ics") || !strings.Contains(status.LastError, `"pulse"`) {
+		t.Fatalf("last error = %q, want the skipped event", status.LastError)
+	}
+}
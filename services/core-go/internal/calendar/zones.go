@@ -0,0 +1,59 @@
+package calendar
+
+import (
+	"fmt"
+	"time"
+)
+
+// windowsZones maps the Windows time zone names that Outlook and Exchange
+// write into TZID to the IANA zone of their largest city.
+var windowsZones = map[string]string{
+	"UTC":                            "UTC",
+	"GMT Standard Time":              "Europe/London",
+	"W. Europe Standard Time":        "Europe/Berlin",
+	"Romance Standard Time":          "Europe/Paris",
+	"Central Europe Standard Time":   "Europe/Budapest",
+	"Central European Standard Time": "Europe/Warsaw",
+	"FLE Standard Time":              "Europe/Kiev",
+	"GTB Standard Time":              "Europe/Bucharest",
+	"Russian Standard Time":          "Europe/Moscow",
+	"Turkey Standard Time":           "Europe/Istanbul",
+	"Israel Standard Time":           "Asia/Jerusalem",
+	"Arabian Standard Time":          "Asia/Dubai",
+	"India Standard Time":            "Asia/Kolkata",
+	"China Standard Time":            "Asia/Shanghai",
+	"Taipei Standard Time":           "Asia/Taipei",
+	"Singapore Standard Time":        "Asia/Singapore",
+	"Tokyo Standard Time":            "Asia/Tokyo",
+	"Korea Standard Time":            "Asia/Seoul",
+	"AUS Eastern Standard Time":      "Australia/Sydney",
+	"E. Australia Standard Time":     "Australia/Brisbane",
+	"New Zealand Standard Time":      "Pacific/Auckland",
+	"Eastern Standard Time":          "America/New_York",
+	"Central Standard Time":          "America/Chicago",
+	"Mountain Standard Time":         "America/Denver",
+	"US Mountain Standard Time":      "America/Phoenix",
+	"Pacific Standard Time":          "America/Los_Angeles",
+	"Alaskan Standard Time":          "America/Anchorage",
+	"Hawaiian Standard Time":         "Pacific/Honolulu",
+	"Atlantic Standard Time":         "America/Halifax",
+	"SA Pacific Standard Time":       "America/Bogota",
+	"E. South America Standard Time": "America/Sao_Paulo",
+	"South Africa Standard Time":     "Africa/Johannesburg",
+	"Egypt Standard Time":            "Africa/Cairo",
+}
+
+// loadZone resolves a TZID, first as an IANA name and then as a Windows name.
+// An unknown zone is an error rather than a silent fall back to local time,
+// which would shift the event by the difference.
+func loadZone(tzid string) (*time.Location, error) {
+	if zone, err := time.LoadLocation(tzid); err == nil {
+		return zone, nil
+	}
+	if name, ok := windowsZones[tzid]; ok {
+		if zone, err := time.LoadLocation(name); err == nil {
+			return zone, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown TZID %q", tzid)
+}
@@ -0,0 +1,50 @@
+// Package clock lets the monitor, gateway and decision pipeline run against a
+// virtual time source so recorded days can be replayed faster than real time.
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// System is the wall clock.
+var System Clock = systemClock{}
+
+// Virtual only moves when told to.
+type Virtual struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func NewVirtual(start time.Time) *Virtual {
+	return &Virtual{now: start}
+}
+
+func (v *Virtual) Now() time.Time {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.now
+}
+
+func (v *Virtual) Set(t time.Time) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.now = t
+}
+
+func (v *Virtual) Advance(d time.Duration) time.Time {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.now = v.now.Add(d)
+	return v.now
+}
@@ -0,0 +1,66 @@
+package clock
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ParseRange parses a daily HH:MM-HH:MM window into minutes after midnight.
+// The window wraps midnight when start is after end.
+func ParseRange(value string) (int, int, error) {
+	parts := strings.Split(value, "-")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid time %q: want HH:MM-HH:MM", value)
+	}
+	var bounds [2]int
+	for i, part := range parts {
+		t, err := time.Parse("15:04", strings.TrimSpace(part))
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid time %q: want HH:MM-HH:MM", value)
+		}
+		bounds[i] = t.Hour()*60 + t.Minute()
+	}
+	return bounds[0], bounds[1], nil
+}
+
+// InRange reports whether t falls in the window from ParseRange.
+func InRange(t time.Time, start int, end int) bool {
+	minute := t.Hour()*60 + t.Minute()
+	if start <= end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end
+}
+
+// StartDay returns the weekday the occurrence of the window containing t
+// started on: the day before for the part of a wrapping window after midnight.
+func StartDay(t time.Time, start int, end int) time.Weekday {
+	if start > end && t.Hour()*60+t.Minute() < end {
+		return t.AddDate(0, 0, -1).Weekday()
+	}
+	return t.Weekday()
+}
+
+// ParseWeekday accepts English weekday names and their three-letter
+// abbreviations in any case.
+func ParseWeekday(value string) (time.Weekday, bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "sun", "sunday":
+		return time.Sunday, true
+	case "mon", "monday":
+		return time.Monday, true
+	case "tue", "tuesday":
+		return time.Tuesday, true
+	case "wed", "wednesday":
+		return time.Wednesday, true
+	case "thu", "thursday":
+		return time.Thursday, true
+	case "fri", "friday":
+		return time.Friday, true
+	case "sat", "saturday":
+		return time.Saturday, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,102 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRange(t *testing.T) {
+	cases := []struct {
+		value      string
+		start, end int
+		ok         bool
+	}{
+		{"09:00-17:30", 540, 1050, true},
+		{" 22:00 - 07:00 ", 1320, 420, true},
+		{"00:00-00:00", 0, 0, true},
+		{"9-17", 0, 0, false},
+		{"09:00", 0, 0, false},
+		{"09:00-25:00", 0, 0, false},
+		{"09:00-10:00-11:00", 0, 0, false},
+	}
+	for _, tc := range cases {
+		start, end, err := ParseRange(tc.value)
+		if (err == nil) != tc.ok {
+			t.Errorf("ParseRange(%q) error = %v, want ok %v", tc.value, err, tc.ok)
+			continue
+		}
+		if tc.ok && (start != tc.start || end != tc.end) {
+			t.Errorf("ParseRange(%q) = %d, %d, want %d, %d", tc.value, start, end, tc.start, tc.end)
+		}
+	}
+}
+
+func TestInRange(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2026, 10, 12, hour, minute, 0, 0, time.Local)
+	}
+	cases := []struct {
+		name       string
+		t          time.Time
+		start, end int
+		want       bool
+	}{
+		{"inside", at(12, 0), 540, 1050, true},
+		{"at start", at(9, 0), 540, 1050, true},
+		{"at end", at(17, 30), 540, 1050, false},
+		{"before", at(8, 59), 540, 1050, false},
+		{"wrapped evening", at(23, 0), 1320, 420, true},
+		{"wrapped morning", at(6, 59), 1320, 420, true},
+		{"wrapped daytime", at(12, 0), 1320, 420, false},
+		{"empty", at(12, 0), 720, 720, false},
+	}
+	for _, tc := range cases {
+		if got := InRange(tc.t, tc.start, tc.end); got != tc.want {
+			t.Errorf("%s: InRange = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestParseWeekday(t *testing.T) {
+	cases := []struct {
+		value string
+		want  time.Weekday
+		ok    bool
+	}{
+		{"mon", time.Monday, true},
+		{"Friday", time.Friday, true},
+		{" SUN ", time.Sunday, true},
+		{"sat", time.Saturday, true},
+		{"weekend", 0, false},
+		{"", 0, false},
+	}
+	for _, tc := range cases {
+		got, ok := ParseWeekday(tc.value)
+		if ok != tc.ok || got != tc.want {
+			t.Errorf("ParseWeekday(%q) = %v, %v, want %v, %v", tc.value, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestStartDay(t *testing.T) {
+	// 2026-10-16 is a Friday.
+	at := func(day, hour int) time.Time {
+		return time.Date(2026, 10, day, hour, 0, 0, 0, time.Local)
+	}
+	cases := []struct {
+		name       string
+		t          time.Time
+		start, end int
+		want       time.Weekday
+	}{
+		{"same-day window", at(16, 12), 540, 1050, time.Friday},
+		{"wrapped evening", at(16, 23), 1320, 420, time.Friday},
+		{"wrapped morning", at(17, 6), 1320, 420, time.Friday},
+		{"after a wrapped window", at(17, 8), 1320, 420, time.Saturday},
+	}
+	for _, tc := range cases {
+		if got := StartDay(tc.t, tc.start, tc.end); got != tc.want {
+			t.Errorf("%s: StartDay = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"always/core/internal/models"
+)
+
+// Title rules outrank app rules so "Chrome - YouTube" can be distracting while
+// Chrome itself stays neutral. Domains pushed by browser extensions are the
+// most precise signal and outrank both.
+var defaultFocusCategoryRules = []models.FocusCategoryRule{
+	{Category: models.CategoryDistracting, MatchField: models.CategoryMatchDomain, Pattern: "youtube.com", Priority: 30},
+	{Category: models.CategoryDistracting, MatchField: models.CategoryMatchDomain, Pattern: "bilibili.com", Priority: 30},
+	{Category: models.CategoryDistracting, MatchField: models.CategoryMatchDomain, Pattern: "reddit.com", Priority: 30},
+	{Category: models.CategoryDistracting, MatchField: models.CategoryMatchDomain, Pattern: "x.com", Priority: 30},
+	{Category: models.CategoryDistracting, MatchField: models.CategoryMatchDomain, Pattern: "weibo.com", Priority: 30},
+	{Category: models.CategoryProductive, MatchField: models.CategoryMatchDomain, Pattern: "github.com", Priority: 30},
+	{Category: models.CategoryProductive, MatchField: models.CategoryMatchDomain, Pattern: "stackoverflow.com", Priority: 30},
+	{Category: models.CategoryDistracting, MatchField: models.CategoryMatchWindowTitle, Pattern: `(?i)youtube|bilibili|twitter|x\.com|reddit|weibo|douyin|netflix`, Priority: 20},
+	{Category: models.CategoryProductive, MatchField: models.CategoryMatchWindowTitle, Pattern: `(?i)github|stack ?overflow|docs?\.|documentation|jira|confluence`, Priority: 10},
+	{Category: models.CategoryProductive, MatchField: models.CategoryMatchBundleID, Pattern: "com.microsoft.VSCode"},
+	{Category: models.CategoryProductive, MatchField: models.CategoryMatchBundleID, Pattern: "com.apple.dt.Xcode"},
+	{Category: models.CategoryProductive, MatchField: models.CategoryMatchBundleID, Pattern: "com.apple.Terminal"},
+	{Category: models.CategoryProductive, MatchField: models.CategoryMatchBundleID, Pattern: "com.googlecode.iterm2"},
+	{Category: models.CategoryProductive, MatchField: models.CategoryMatchAppName, Pattern: "Code"},
+	{Category: models.CategoryProductive, MatchField: models.CategoryMatchAppName, Pattern: "Visual Studio Code"},
+	{Category: models.CategoryProductive, MatchField: models.CategoryMatchAppName, Pattern: "GoLand"},
+	{Category: models.CategoryProductive, MatchField: models.CategoryMatchAppName, Pattern: "IntelliJ IDEA"},
+	{Category: models.CategoryProductive, MatchField: models.CategoryMatchAppName, Pattern: "Terminal"},
+	{Category: models.CategoryProductive, MatchField: models.CategoryMatchAppName, Pattern: "kitty"},
+	{Category: models.CategoryProductive, MatchField: models.CategoryMatchAppName, Pattern: "Alacritty"},
+	{Category: models.CategoryProductive, MatchField: models.CategoryMatchAppName, Pattern: "foot"},
+	{Category: models.CategoryDistracting, MatchField: models.CategoryMatchAppName, Pattern: "WeChat"},
+	{Category: models.CategoryDistracting, MatchField: models.CategoryMatchAppName, Pattern: "Discord"},
+	{Category: models.CategoryDistracting, MatchField: models.CategoryMatchAppName, Pattern: "Telegram"},
+	{Category: models.CategoryNeutral, MatchField: models.CategoryMatchAppName, Pattern: "Slack"},
+	{Category: models.CategoryNeutral, MatchField: models.CategoryMatchAppName, Pattern: "Mail"},
+	{Category: models.CategoryNeutral, MatchField: models.CategoryMatchAppName, Pattern: "Finder"},
+}
+
+func (s *Store) seedFocusCategoryRules() error {
+	for _, rule := range defaultFocusCategoryRules {
+		if _, err := s.InsertFocusCategoryRule(rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) ListFocusCategoryRules() ([]models.FocusCategoryRule, error) {
+	rows, err := s.db.Query(
+		`SELECT id, category, match_field, pattern, priority, created_at_ms
+		 FROM focus_category_rules ORDER BY priority DESC, id ASC`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list focus category rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []models.FocusCategoryRule
+	for rows.Next() {
+		var rule models.FocusCategoryRule
+		if err := rows.Scan(&rule.ID, &rule.Category, &rule.MatchField, &rule.Pattern, &rule.Priority, &rule.CreatedAtMs); err != nil {
+			return nil, fmt.Errorf("scan focus category rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("focus category rule rows: %w", err)
+	}
+	return rules, nil
+}
+
+func (s *Store) InsertFocusCategoryRule(rule models.FocusCategoryRule) (int64, error) {
+	createdAt := rule.CreatedAtMs
+	if createdAt == 0 {
+		createdAt = time.Now().UnixMilli()
+	}
+	result, err := s.db.Exec(
+		`INSERT INTO focus_category_rules (category, match_field, pattern, priority, created_at_ms)
+		 VALUES (?, ?, ?, ?, ?)`,
+		rule.Category,
+		rule.MatchField,
+		rule.Pattern,
+		rule.Priority,
+		createdAt,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("insert focus category rule: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("focus category rule id: %w", err)
+	}
+	return id, nil
+}
+
+func (s *Store) UpdateFocusCategoryRule(rule models.FocusCategoryRule) (bool, error) {
+	result, err := s.db.Exec(
+		`UPDATE focus_category_rules SET category = ?, match_field = ?, pattern = ?, priority = ? WHERE id = ?`,
+		rule.Category,
+		rule.MatchField,
+		rule.Pattern,
+		rule.Priority,
+		rule.ID,
+	)
+	if err != nil {
+		return false, fmt.Errorf("update focus category rule: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("update focus category rule: %w", err)
+	}
+	return affected > 0, nil
+}
+
+func (s *Store) DeleteFocusCategoryRule(id int64) (bool, error) {
+	result, err := s.db.Exec(`DELETE FROM focus_category_rules WHERE id = ?`, id)
+	if err != nil {
+		return false, fmt.Errorf("delete focus category rule: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("delete focus category rule: %w", err)
+	}
+	return affected > 0, nil
+}
+
+func (s *Store) GetFocusCategoryRule(id int64) (models.FocusCategoryRule, bool, error) {
+	row := s.db.QueryRow(
+		`SELECT id, category, match_field, pattern, priority, created_at_ms FROM focus_category_rules WHERE id = ?`,
+		id,
+	)
+	var rule models.FocusCategoryRule
+	if err := row.Scan(&rule.ID, &rule.Category, &rule.MatchField, &rule.Pattern, &rule.Priority, &rule.CreatedAtMs); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.FocusCategoryRule{}, false, nil
+		}
+		return models.FocusCategoryRule{}, false, fmt.Errorf("get focus category rule: %w", err)
+	}
+	return rule, true, nil
+}
@@ -0,0 +1,225 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+
+	"always/core/internal/models"
+)
+
+// compactGap is the largest gap between two events that still counts as one
+// continuous span.
+const compactGap = 1000
+
+// MergeFocusPeek folds the micro-switch peekID back into keepID, which becomes
+// the open event again.
+func (s *Store) MergeFocusPeek(keepID int64, peekID int64, peekMs int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin merge focus peek: %w", err)
+	}
+	defer tx.Rollback()
+	var mergedPeeks int
+	var mergedPeekMs int64
+	if err := tx.QueryRow(
+		`SELECT merged_peeks, merged_peek_ms FROM focus_events WHERE id = ?`,
+		peekID,
+	).Scan(&mergedPeeks, &mergedPeekMs); err != nil {
+		return fmt.Errorf("load focus peek: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM focus_events WHERE id = ?`, peekID); err != nil {
+		return fmt.Errorf("delete focus peek: %w", err)
+	}
+	if _, err := tx.Exec(
+		`UPDATE focus_events
+		 SET duration_ms = 0, merged_peeks = merged_peeks + ?, merged_peek_ms = merged_peek_ms + ?
+		 WHERE id = ?`,
+		mergedPeeks+1,
+		mergedPeekMs+peekMs,
+		keepID,
+	); err != nil {
+		return fmt.Errorf("reopen focus event: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit merge focus peek: %w", err)
+	}
+	return nil
+}
+
+// compactBatchSize bounds how many events one compaction transaction reads,
+// so the first pass over a long history does not hold the write lock for long.
+const compactBatchSize = 500
+
+// CompactFocusEvents rewrites local events that started in [sinceMs, untilMs)
+// into compact spans: back-to-back events of the same app are joined, and a
+// visit shorter than peekMs between two events of the same app is folded into
+// them and counted in merged_peeks. An event is only rewritten once the event
+// after it has started, so the open event is never touched; neither are the
+// events in keepIDs, which the caller still holds. The range is compacted in
+// batches of compactBatchSize events, one transaction each.
+func (s *Store) CompactFocusEvents(sinceMs int64, untilMs int64, peekMs int64, keepIDs ...int64) (models.FocusCompaction, error) {
+	result := models.FocusCompaction{SinceMs: sinceMs, UntilMs: untilMs}
+	held := map[int64]bool{}
+	for _, id := range keepIDs {
+		held[id] = true
+	}
+	cursorTs, cursorID := sinceMs, int64(0)
+	for {
+		batch, err := s.compactFocusBatch(cursorTs, cursorID, untilMs, peekMs, held)
+		if err != nil {
+			return result, err
+		}
+		result.Deleted += batch.deleted
+		result.MergedPeeks += batch.mergedPeeks
+		if !batch.full {
+			result.Scanned += batch.scanned
+			return result, nil
+		}
+		// The last kept event may still join the events of the next batch, so
+		// the next batch starts from it.
+		result.Scanned += batch.keep
+		if batch.keep == 0 && batch.deleted == 0 {
+			return result, nil
+		}
+		cursorTs, cursorID = batch.resumeTs, batch.resumeID
+	}
+}
+
+type compactBatch struct {
+	scanned     int
+	deleted     int
+	mergedPeeks int
+	full        bool
+	// keep is the index of the last kept event, where the next batch resumes.
+	keep     int
+	resumeTs int64
+	resumeID int64
+}
+
+func (s *Store) compactFocusBatch(cursorTs int64, cursorID int64, untilMs int64, peekMs int64, held map[int64]bool) (compactBatch, error) {
+	var batch compactBatch
+	tx, err := s.db.Begin()
+	if err != nil {
+		return batch, fmt.Errorf("begin compact focus events: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(
+		`SELECT `+focusEventColumns+`
+		 FROM focus_events
+		 WHERE source = ? AND (ts_ms > ? OR (ts_ms = ? AND id >= ?)) AND ts_ms < ?
+		 ORDER BY ts_ms ASC, id ASC
+		 LIMIT ?`,
+		models.FocusSourceLocal,
+		cursorTs,
+		cursorTs,
+		cursorID,
+		untilMs,
+		compactBatchSize,
+	)
+	if err != nil {
+		return batch, fmt.Errorf("query focus events: %w", err)
+	}
+	var events []models.FocusEvent
+	for rows.Next() {
+		event, err := scanFocusEvent(rows)
+		if err != nil {
+			rows.Close()
+			return batch, fmt.Errorf("scan focus event: %w", err)
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return batch, fmt.Errorf("iterate focus events: %w", err)
+	}
+	rows.Close()
+	batch.scanned = len(events)
+	batch.full = len(events) == compactBatchSize
+	if len(events) < 2 {
+		return batch, nil
+	}
+
+	// end resolves when events[i] stopped; false while it may still be open.
+	end := func(i int) (int64, bool) {
+		if events[i].DurationMs > 0 {
+			return events[i].TsMs + events[i].DurationMs, true
+		}
+		if i+1 < len(events) {
+			return max(events[i+1].TsMs, events[i].TsMs), true
+		}
+		return 0, false
+	}
+	same := func(a, b models.FocusEvent) bool {
+		return strings.EqualFold(a.AppName, b.AppName) && a.BundleID == b.BundleID
+	}
+
+	var deleted []int64
+	changed := map[int]bool{}
+	keep := 0
+	keepEnd, keepClosed := end(0)
+	for i := 1; i < len(events) && keepClosed; {
+		next := events[i]
+		nextEnd, nextClosed := end(i)
+		if !nextClosed {
+			break
+		}
+		contiguous := next.TsMs-keepEnd <= compactGap && !held[events[keep].ID] && !held[next.ID]
+		switch {
+		case contiguous && same(events[keep], next):
+			events[keep].MergedPeeks += next.MergedPeeks
+			events[keep].MergedPeekMs += next.MergedPeekMs
+			keepEnd = nextEnd
+			deleted = append(deleted, next.ID)
+			changed[keep] = true
+			i++
+			continue
+		case contiguous && nextEnd-next.TsMs < peekMs && i+1 < len(events):
+			back := events[i+1]
+			backEnd, backClosed := end(i + 1)
+			if backClosed && !held[back.ID] && same(events[keep], back) && back.TsMs-nextEnd <= compactGap {
+				events[keep].MergedPeeks += 1 + next.MergedPeeks + back.MergedPeeks
+				events[keep].MergedPeekMs += nextEnd - next.TsMs + next.MergedPeekMs + back.MergedPeekMs
+				keepEnd = backEnd
+				deleted = append(deleted, next.ID, back.ID)
+				changed[keep] = true
+				batch.mergedPeeks += 1 + next.MergedPeeks
+				i += 2
+				continue
+			}
+		}
+		if changed[keep] {
+			events[keep].DurationMs = keepEnd - events[keep].TsMs
+		}
+		keep, keepEnd, keepClosed = i, nextEnd, nextClosed
+		i++
+	}
+	if changed[keep] {
+		events[keep].DurationMs = keepEnd - events[keep].TsMs
+	}
+	batch.keep = keep
+	batch.resumeTs, batch.resumeID = events[keep].TsMs, events[keep].ID
+
+	for i := range changed {
+		event := events[i]
+		if _, err := tx.Exec(
+			`UPDATE focus_events SET duration_ms = ?, merged_peeks = ?, merged_peek_ms = ? WHERE id = ?`,
+			event.DurationMs,
+			event.MergedPeeks,
+			event.MergedPeekMs,
+			event.ID,
+		); err != nil {
+			return batch, fmt.Errorf("update compacted focus event: %w", err)
+		}
+	}
+	for _, id := range deleted {
+		if _, err := tx.Exec(`DELETE FROM focus_events WHERE id = ?`, id); err != nil {
+			return batch, fmt.Errorf("delete compacted focus event: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return batch, fmt.Errorf("commit compact focus events: %w", err)
+	}
+	batch.deleted = len(deleted)
+	return batch, nil
+}
@@ -0,0 +1,171 @@
+package db
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"always/core/internal/models"
+)
+
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+	store, err := Open(filepath.Join(t.TempDir(), "always.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { store.db.Close() })
+	return store
+}
+
+type span struct {
+	app  string
+	from time.Duration
+	// d of 0 leaves the event open.
+	d time.Duration
+}
+
+func insertSpans(t *testing.T, store *Store, spans ...span) []int64 {
+	t.Helper()
+	ids := make([]int64, 0, len(spans))
+	for _, s := range spans {
+		id, err := store.InsertFocusEvent(models.FocusEvent{
+			TsMs:       s.from.Milliseconds(),
+			AppName:    s.app,
+			DurationMs: s.d.Milliseconds(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func allFocusEvents(t *testing.T, store *Store) []models.FocusEvent {
+	t.Helper()
+	events, err := store.ListFocusEventsRange(0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return events
+}
+
+func TestCompactFocusEvents(t *testing.T) {
+	store := openTestStore(t)
+	insertSpans(t, store,
+		span{"Code", time.Hour, 5 * time.Minute},
+		span{"Code", time.Hour + 5*time.Minute, 5 * time.Minute},
+		// A two-second peek between two Code events.
+		span{"Slack", time.Hour + 10*time.Minute, 2 * time.Second},
+		span{"Code", time.Hour + 10*time.Minute + 2*time.Second, 10 * time.Minute},
+		// Idle gap: not contiguous.
+		span{"Code", time.Hour + 30*time.Minute, 5 * time.Minute},
+		span{"Slack", time.Hour + 35*time.Minute, 0},
+	)
+	result, err := store.CompactFocusEvents(0, (2 * time.Hour).Milliseconds(), (3 * time.Second).Milliseconds())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Scanned != 6 || result.Deleted != 3 || result.MergedPeeks != 1 {
+		t.Fatalf("result = %+v", result)
+	}
+	events := allFocusEvents(t, store)
+	if len(events) != 3 {
+		t.Fatalf("events = %+v", events)
+	}
+	first := events[0]
+	if first.DurationMs != (20*time.Minute+2*time.Second).Milliseconds() || first.MergedPeeks != 1 || first.MergedPeekMs != 2000 {
+		t.Fatalf("first = %+v", first)
+	}
+	if events[1].DurationMs != (5*time.Minute).Milliseconds() || events[2].DurationMs != 0 {
+		t.Fatalf("events = %+v", events)
+	}
+}
+
+func TestCompactFocusEventsKeepsHeldEvents(t *testing.T) {
+	store := openTestStore(t)
+	ids := insertSpans(t, store,
+		span{"Code", 0, time.Minute},
+		span{"Code", time.Minute, time.Minute},
+		span{"Code", 2 * time.Minute, time.Minute},
+		span{"Slack", 3 * time.Minute, 0},
+	)
+	result, err := store.CompactFocusEvents(0, time.Hour.Milliseconds(), 0, ids[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Deleted != 0 || len(allFocusEvents(t, store)) != 4 {
+		t.Fatalf("held event was compacted: %+v", result)
+	}
+}
+
+func TestCompactFocusEventsInBatches(t *testing.T) {
+	const n = 2*compactBatchSize + 7
+	store := openTestStore(t)
+	spans := make([]span, 0, n)
+	for i := range n {
+		spans = append(spans, span{"Code", time.Duration(i) * time.Second, time.Second})
+	}
+	insertSpans(t, store, spans...)
+	result, err := store.CompactFocusEvents(0, time.Hour.Milliseconds(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := allFocusEvents(t, store)
+	if result.Deleted != n-1 || len(events) != 1 || events[0].DurationMs != n*1000 {
+		t.Fatalf("result = %+v, events = %d, first = %+v", result, len(events), events[0])
+	}
+
+	// Nothing to join: every batch moves on and the pass ends.
+	store = openTestStore(t)
+	for i := range spans {
+		if i%2 == 1 {
+			spans[i].app = "Terminal"
+		}
+	}
+	insertSpans(t, store, spans...)
+	result, err = store.CompactFocusEvents(0, time.Hour.Milliseconds(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Deleted != 0 || result.Scanned != n {
+		t.Fatalf("result = %+v", result)
+	}
+}
+
+func TestConcurrentWritersWaitForLock(t *testing.T) {
+	store := openTestStore(t)
+	const writers = 8
+	var wg sync.WaitGroup
+	inserted := make(chan bool, writers)
+	errs := make(chan error, writers)
+	for range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, ok, err := store.InsertFocusSession(models.FocusSession{Name: "focus", TargetMinutes: 25, Status: models.SessionActive, StartedAtMs: 1})
+			if err != nil {
+				errs <- err
+				return
+			}
+			inserted <- ok
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	close(inserted)
+	for err := range errs {
+		t.Fatalf("insert: %v", err)
+	}
+	count := 0
+	for ok := range inserted {
+		if ok {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Fatalf("%d sessions started, want 1", count)
+	}
+}
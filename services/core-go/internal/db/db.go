@@ -24,6 +24,7 @@ CREATE TABLE IF NOT EXISTS event_logs (
   raw_action_json TEXT NOT NULL,
   final_action_json TEXT NOT NULL,
   gateway_decision_json TEXT NOT NULL,
+  gateway_cost REAL NOT NULL DEFAULT 0,
   policy_version TEXT NOT NULL,
   model_version TEXT NOT NULL,
   latency_ms INTEGER NOT NULL,
@@ -63,6 +64,23 @@ CREATE TABLE IF NOT EXISTS budget_usage (
   updated_at_ms INTEGER NOT NULL
 );
 
+CREATE TABLE IF NOT EXISTS action_usage (
+  action_type TEXT PRIMARY KEY,
+  last_ms INTEGER NOT NULL,
+  daily_day TEXT NOT NULL,
+  daily_count INTEGER NOT NULL,
+  ladder_level INTEGER NOT NULL DEFAULT 0,
+  ignored_streak INTEGER NOT NULL DEFAULT 0,
+  updated_at_ms INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS gateway_state (
+  id INTEGER PRIMARY KEY CHECK (id = 1),
+  version INTEGER NOT NULL,
+  payload TEXT NOT NULL,
+  updated_at_ms INTEGER NOT NULL
+);
+
 CREATE TABLE IF NOT EXISTS focus_events (
   id INTEGER PRIMARY KEY AUTOINCREMENT,
   ts_ms INTEGER NOT NULL,
@@ -70,13 +88,49 @@ CREATE TABLE IF NOT EXISTS focus_events (
   bundle_id TEXT,
   pid INTEGER,
   window_title TEXT,
+  duration_ms INTEGER NOT NULL DEFAULT 0,
+  source TEXT NOT NULL DEFAULT 'local',
+  url TEXT,
+  domain TEXT,
+  merged_peeks INTEGER NOT NULL DEFAULT 0,
+  merged_peek_ms INTEGER NOT NULL DEFAULT 0,
+  local_event_id INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS focus_category_rules (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  category TEXT NOT NULL,
+  match_field TEXT NOT NULL,
+  pattern TEXT NOT NULL,
+  priority INTEGER NOT NULL DEFAULT 0,
+  created_at_ms INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS idle_spans (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  ts_ms INTEGER NOT NULL,
   duration_ms INTEGER NOT NULL DEFAULT 0
 );
 
+CREATE TABLE IF NOT EXISTS focus_sessions (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  name TEXT NOT NULL,
+  task TEXT,
+  target_minutes INTEGER NOT NULL,
+  status TEXT NOT NULL,
+  started_at_ms INTEGER NOT NULL,
+  paused_at_ms INTEGER NOT NULL DEFAULT 0,
+  paused_ms INTEGER NOT NULL DEFAULT 0,
+  ended_at_ms INTEGER NOT NULL DEFAULT 0,
+  decision_request_id TEXT
+);
+
 CREATE UNIQUE INDEX IF NOT EXISTS idx_event_logs_request_id ON event_logs (request_id);
 CREATE INDEX IF NOT EXISTS idx_event_logs_created_at_ms ON event_logs (created_at_ms);
 CREATE INDEX IF NOT EXISTS idx_feedback_logs_request_id ON feedback_logs (request_id);
 CREATE INDEX IF NOT EXISTS idx_focus_events_ts_ms ON focus_events (ts_ms);
+CREATE INDEX IF NOT EXISTS idx_idle_spans_ts_ms ON idle_spans (ts_ms);
+CREATE INDEX IF NOT EXISTS idx_focus_sessions_started_at_ms ON focus_sessions (started_at_ms);
 
 CREATE TABLE IF NOT EXISTS profiles (
   key TEXT PRIMARY KEY,
@@ -122,17 +176,29 @@ func Open(path string) (*Store, error) {
 	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
 		return nil, fmt.Errorf("create db dir: %w", err)
 	}
-	db, err := sql.Open("sqlite", path)
+	// Writers from the monitor, compaction and HTTP handlers share the file;
+	// wait for the lock instead of failing with SQLITE_BUSY.
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)")
 	if err != nil {
 		return nil, fmt.Errorf("open sqlite: %w", err)
 	}
+	seedCategories, err := tableMissing(db, "focus_category_rules")
+	if err != nil {
+		return nil, fmt.Errorf("inspect schema: %w", err)
+	}
 	if _, err := db.Exec(schema); err != nil {
 		return nil, fmt.Errorf("migrate schema: %w", err)
 	}
 	if err := applyMigrations(db); err != nil {
 		return nil, fmt.Errorf("apply migrations: %w", err)
 	}
-	return &Store{db: db}, nil
+	store := &Store{db: db}
+	if seedCategories {
+		if err := store.seedFocusCategoryRules(); err != nil {
+			return nil, fmt.Errorf("seed focus categories: %w", err)
+		}
+	}
+	return store, nil
 }
 
 func (s *Store) DB() *sql.DB {
@@ -173,12 +239,42 @@ func applyMigrations(db *sql.DB) error {
 			return err
 		}
 	}
-	if err := addColumnIfMissing(db, "focus_events", "window_title TEXT"); err != nil {
+	if err := addColumnIfMissing(db, "event_logs", "gateway_cost REAL NOT NULL DEFAULT 0"); err != nil {
 		return err
 	}
+	for _, column := range []string{
+		"ladder_level INTEGER NOT NULL DEFAULT 0",
+		"ignored_streak INTEGER NOT NULL DEFAULT 0",
+	} {
+		if err := addColumnIfMissing(db, "action_usage", column); err != nil {
+			return err
+		}
+	}
+	focusColumns := []string{
+		"window_title TEXT",
+		"source TEXT NOT NULL DEFAULT 'local'",
+		"url TEXT",
+		"domain TEXT",
+		"merged_peeks INTEGER NOT NULL DEFAULT 0",
+		"merged_peek_ms INTEGER NOT NULL DEFAULT 0",
+		"local_event_id INTEGER NOT NULL DEFAULT 0",
+	}
+	for _, column := range focusColumns {
+		if err := addColumnIfMissing(db, "focus_events", column); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+func tableMissing(db *sql.DB, table string) (bool, error) {
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name=?", table).Scan(&count); err != nil {
+		return false, err
+	}
+	return count == 0, nil
+}
+
 func addColumnIfMissing(db *sql.DB, table, columnDef string) error {
 	_, err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", table, columnDef))
 	if err != nil {
@@ -303,15 +399,21 @@ func (s *Store) InsertDecision(entry models.DecisionLogEntry) error {
 		modelVersion = "stub"
 	}
 
+	var gatewayCost float64
+	if entry.GatewayDecision.Pricing != nil {
+		gatewayCost = entry.GatewayDecision.Pricing.Cost
+	}
+
 	_, err = s.db.Exec(
-		`INSERT INTO event_logs (request_id, context_json, action_json, raw_action_json, final_action_json, gateway_decision_json, policy_version, model_version, latency_ms, created_at, created_at_ms)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		`INSERT INTO event_logs (request_id, context_json, action_json, raw_action_json, final_action_json, gateway_decision_json, gateway_cost, policy_version, model_version, latency_ms, created_at, created_at_ms)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		entry.RequestID,
 		string(ctxJSON),
 		string(finalActionJSON),
 		string(rawActionJSON),
 		string(finalActionJSON),
 		string(gatewayDecisionJSON),
+		gatewayCost,
 		policyVersion,
 		modelVersion,
 		entry.LatencyMs,
@@ -336,6 +438,34 @@ func (s *Store) DecisionExists(reqID string) (bool, error) {
 	return true, nil
 }
 
+// GetDecisionActions returns the proposed and the final action logged for a
+// request.
+func (s *Store) GetDecisionActions(reqID string) (models.Action, models.Action, bool, error) {
+	var raw, final models.Action
+	var rawJSON, finalJSON sql.NullString
+	err := s.db.QueryRow(
+		`SELECT raw_action_json, final_action_json FROM event_logs WHERE request_id = ? LIMIT 1`,
+		reqID,
+	).Scan(&rawJSON, &finalJSON)
+	if errors.Is(err, sql.ErrNoRows) {
+		return raw, final, false, nil
+	}
+	if err != nil {
+		return raw, final, false, fmt.Errorf("query decision actions: %w", err)
+	}
+	if rawJSON.Valid && rawJSON.String != "" {
+		if err := json.Unmarshal([]byte(rawJSON.String), &raw); err != nil {
+			return raw, final, false, fmt.Errorf("decode raw action: %w", err)
+		}
+	}
+	if finalJSON.Valid && finalJSON.String != "" {
+		if err := json.Unmarshal([]byte(finalJSON.String), &final); err != nil {
+			return raw, final, false, fmt.Errorf("decode final action: %w", err)
+		}
+	}
+	return raw, final, true, nil
+}
+
 func (s *Store) RecordFeedback(reqID, feedback string) error {
 	_, err := s.db.Exec(
 		`UPDATE event_logs SET user_feedback = ? WHERE request_id = ?`,
@@ -375,6 +505,61 @@ func (s *Store) RecordImplicitFeedback(reqID string, feedbackType string, feedba
 	return nil
 }
 
+// ListFeedbackSince returns explicit feedback from feedback_logs and implicit
+// feedback from implicit_feedback_events, each counted once, oldest first.
+func (s *Store) ListFeedbackSince(sinceMs int64) ([]models.FeedbackSample, error) {
+	rows, err := s.db.Query(
+		`SELECT feedback, created_at_ms, 0 FROM feedback_logs
+		 WHERE created_at_ms >= ?
+		 UNION ALL
+		 SELECT feedback_type, created_at_ms, 1 FROM implicit_feedback_events
+		 WHERE created_at_ms >= ?
+		 ORDER BY created_at_ms`,
+		sinceMs,
+		sinceMs,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query feedback: %w", err)
+	}
+	defer rows.Close()
+	var samples []models.FeedbackSample
+	for rows.Next() {
+		var raw string
+		var implicit bool
+		var sample models.FeedbackSample
+		if err := rows.Scan(&raw, &sample.CreatedAtMs, &implicit); err != nil {
+			return nil, fmt.Errorf("scan feedback: %w", err)
+		}
+		feedbackType, _, _ := strings.Cut(raw, ":")
+		sample.Feedback = models.FeedbackType(strings.ToUpper(strings.TrimSpace(feedbackType)))
+		switch sample.Feedback {
+		case models.FeedbackIgnored, models.FeedbackClosed, models.FeedbackOpen:
+			// feedback_logs repeats implicit feedback; count the dedicated row.
+			if !implicit {
+				continue
+			}
+		}
+		samples = append(samples, sample)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate feedback: %w", err)
+	}
+	return samples, nil
+}
+
+func (s *Store) GetProfile(key string) (string, float64, bool, error) {
+	var value string
+	var confidence float64
+	err := s.db.QueryRow(`SELECT value, COALESCE(confidence, 1) FROM profiles WHERE key = ?`, key).Scan(&value, &confidence)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", 0, false, nil
+		}
+		return "", 0, false, fmt.Errorf("get profile: %w", err)
+	}
+	return value, confidence, true, nil
+}
+
 func (s *Store) ListLogs(limit int) ([]models.EventLog, error) {
 	return s.ListLogsRange(limit, 0, 0)
 }
@@ -560,6 +745,18 @@ func (s *Store) GetSetting(key string) (string, bool, error) {
 	return value, true, nil
 }
 
+func (s *Store) DeleteSetting(key string) (bool, error) {
+	result, err := s.db.Exec(`DELETE FROM user_settings WHERE key = ?`, key)
+	if err != nil {
+		return false, fmt.Errorf("delete setting: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("delete setting: %w", err)
+	}
+	return affected > 0, nil
+}
+
 func (s *Store) GetBudgetUsage() (models.BudgetUsage, error) {
 	row := s.db.QueryRow(
 		`SELECT daily_day, daily_used, hourly_hour, hourly_used FROM budget_usage WHERE id = 1`,
@@ -583,26 +780,27 @@ func (s *Store) GetBudgetUsage() (models.BudgetUsage, error) {
 }
 
 func (s *Store) SetBudgetUsage(usage models.BudgetUsage) error {
-	updatedAt := time.Now().UnixMilli()
-	_, err := s.db.Exec(
-		`INSERT INTO budget_usage (id, daily_day, daily_used, hourly_hour, hourly_used, updated_at_ms)
-		 VALUES (1, ?, ?, ?, ?, ?)
-		 ON CONFLICT(id) DO UPDATE SET
-		   daily_day = excluded.daily_day,
-		   daily_used = excluded.daily_used,
-		   hourly_hour = excluded.hourly_hour,
-		   hourly_used = excluded.hourly_used,
-		   updated_at_ms = excluded.updated_at_ms`,
-		usage.DailyDay,
-		usage.DailyUsed,
-		usage.HourlyHour,
-		usage.HourlyUsed,
-		updatedAt,
-	)
+	return upsertBudgetUsage(s.db, usage)
+}
+
+func (s *Store) ListActionUsage() ([]models.ActionUsage, error) {
+	rows, err := s.db.Query(`SELECT action_type, last_ms, daily_day, daily_count, ladder_level, ignored_streak FROM action_usage ORDER BY action_type`)
 	if err != nil {
-		return fmt.Errorf("upsert budget usage: %w", err)
+		return nil, fmt.Errorf("query action usage: %w", err)
 	}
-	return nil
+	defer rows.Close()
+	var usage []models.ActionUsage
+	for rows.Next() {
+		var item models.ActionUsage
+		if err := rows.Scan(&item.ActionType, &item.LastMs, &item.DailyDay, &item.DailyCount, &item.LadderLevel, &item.IgnoredStreak); err != nil {
+			return nil, fmt.Errorf("scan action usage: %w", err)
+		}
+		usage = append(usage, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate action usage: %w", err)
+	}
+	return usage, nil
 }
 
 func (s *Store) loadLegacyBudgetUsage() (models.BudgetUsage, error) {
@@ -620,16 +818,50 @@ func (s *Store) loadLegacyBudgetUsage() (models.BudgetUsage, error) {
 	return usage, nil
 }
 
+const focusEventColumns = `id, ts_ms, app_name, COALESCE(bundle_id, ''), COALESCE(pid, 0), COALESCE(window_title, ''), duration_ms, source, COALESCE(url, ''), COALESCE(domain, ''), merged_peeks, merged_peek_ms, local_event_id`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanFocusEvent(row rowScanner) (models.FocusEvent, error) {
+	var event models.FocusEvent
+	err := row.Scan(
+		&event.ID,
+		&event.TsMs,
+		&event.AppName,
+		&event.BundleID,
+		&event.PID,
+		&event.WindowTitle,
+		&event.DurationMs,
+		&event.Source,
+		&event.URL,
+		&event.Domain,
+		&event.MergedPeeks,
+		&event.MergedPeekMs,
+		&event.LocalEventID,
+	)
+	return event, err
+}
+
 func (s *Store) InsertFocusEvent(event models.FocusEvent) (int64, error) {
+	source := event.Source
+	if source == "" {
+		source = models.FocusSourceLocal
+	}
 	result, err := s.db.Exec(
-		`INSERT INTO focus_events (ts_ms, app_name, bundle_id, pid, window_title, duration_ms)
-		 VALUES (?, ?, ?, ?, ?, ?)`,
+		`INSERT INTO focus_events (ts_ms, app_name, bundle_id, pid, window_title, duration_ms, source, url, domain, local_event_id)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		event.TsMs,
 		event.AppName,
 		event.BundleID,
 		event.PID,
 		event.WindowTitle,
 		event.DurationMs,
+		source,
+		event.URL,
+		event.Domain,
+		event.LocalEventID,
 	)
 	if err != nil {
 		return 0, fmt.Errorf("insert focus event: %w", err)
@@ -665,13 +897,16 @@ func (s *Store) UpdateFocusWindowTitle(id int64, title string) error {
 	return nil
 }
 
+// LatestFocusEvent returns the newest locally polled event; ingested events
+// never become the monitor's current event.
 func (s *Store) LatestFocusEvent() (models.FocusEvent, bool, error) {
 	row := s.db.QueryRow(
-		`SELECT id, ts_ms, app_name, COALESCE(bundle_id, ''), COALESCE(pid, 0), COALESCE(window_title, ''), duration_ms
-		 FROM focus_events ORDER BY ts_ms DESC, id DESC LIMIT 1`,
+		`SELECT `+focusEventColumns+`
+		 FROM focus_events WHERE source = ? ORDER BY ts_ms DESC, id DESC LIMIT 1`,
+		models.FocusSourceLocal,
 	)
-	var event models.FocusEvent
-	if err := row.Scan(&event.ID, &event.TsMs, &event.AppName, &event.BundleID, &event.PID, &event.WindowTitle, &event.DurationMs); err != nil {
+	event, err := scanFocusEvent(row)
+	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return models.FocusEvent{}, false, nil
 		}
@@ -685,7 +920,7 @@ func (s *Store) ListFocusEvents(limit int) ([]models.FocusEvent, error) {
 		limit = 200
 	}
 	rows, err := s.db.Query(
-		`SELECT id, ts_ms, app_name, COALESCE(bundle_id, ''), COALESCE(pid, 0), COALESCE(window_title, ''), duration_ms
+		`SELECT `+focusEventColumns+`
 		 FROM focus_events ORDER BY ts_ms DESC, id DESC LIMIT ?`,
 		limit,
 	)
@@ -696,8 +931,8 @@ func (s *Store) ListFocusEvents(limit int) ([]models.FocusEvent, error) {
 
 	var events []models.FocusEvent
 	for rows.Next() {
-		var event models.FocusEvent
-		if err := rows.Scan(&event.ID, &event.TsMs, &event.AppName, &event.BundleID, &event.PID, &event.WindowTitle, &event.DurationMs); err != nil {
+		event, err := scanFocusEvent(rows)
+		if err != nil {
 			return nil, fmt.Errorf("scan focus event: %w", err)
 		}
 		events = append(events, event)
@@ -708,17 +943,131 @@ func (s *Store) ListFocusEvents(limit int) ([]models.FocusEvent, error) {
 	return events, nil
 }
 
-func (s *Store) FocusMetrics(windowMs int64) (models.FocusMetrics, error) {
+// ListFocusEventsRange returns events overlapping [sinceMs, untilMs) in
+// ascending order. The last event that started at or before sinceMs is
+// included so spans crossing the lower bound can be clipped by the caller.
+// Ingested events shadowed by a local one are left out so aggregates count
+// that time once.
+func (s *Store) ListFocusEventsRange(sinceMs int64, untilMs int64) ([]models.FocusEvent, error) {
+	if sinceMs < 0 {
+		sinceMs = 0
+	}
+	query := `SELECT ` + focusEventColumns + `
+		 FROM focus_events
+		 WHERE local_event_id = 0
+		   AND ts_ms >= COALESCE((SELECT MAX(ts_ms) FROM focus_events WHERE local_event_id = 0 AND ts_ms <= ?), ?)`
+	args := []any{sinceMs, sinceMs}
+	if untilMs > 0 {
+		query += " AND ts_ms < ?"
+		args = append(args, untilMs)
+	}
+	query += " ORDER BY ts_ms ASC, id ASC"
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list focus events range: %w", err)
+	}
+	defer rows.Close()
+
+	var events []models.FocusEvent
+	for rows.Next() {
+		event, err := scanFocusEvent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan focus event: %w", err)
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("focus rows: %w", err)
+	}
+	return events, nil
+}
+
+// FocusEventExists reports whether an identical event from source was already
+// ingested, so client retries are idempotent.
+func (s *Store) FocusEventExists(source string, tsMs int64, appName string, url string) (bool, error) {
+	row := s.db.QueryRow(
+		`SELECT 1 FROM focus_events WHERE source = ? AND ts_ms = ? AND app_name = ? AND COALESCE(url, '') = ? LIMIT 1`,
+		source,
+		tsMs,
+		appName,
+		url,
+	)
+	var exists int
+	if err := row.Scan(&exists); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("check focus event: %w", err)
+	}
+	return true, nil
+}
+
+// FindOverlappingLocalEvent returns the latest locally polled event for
+// appName whose span overlaps [startMs, endMs); an empty appName matches any
+// app. Open events are treated as running until nowMs.
+func (s *Store) FindOverlappingLocalEvent(appName string, startMs int64, endMs int64, nowMs int64) (models.FocusEvent, bool, error) {
+	row := s.db.QueryRow(
+		`SELECT `+focusEventColumns+`
+		 FROM focus_events
+		 WHERE source = ? AND (? = '' OR app_name = ? COLLATE NOCASE) AND ts_ms < ?
+		   AND (CASE WHEN duration_ms > 0 THEN ts_ms + duration_ms ELSE ? END) > ?
+		 ORDER BY ts_ms DESC, id DESC LIMIT 1`,
+		models.FocusSourceLocal,
+		appName,
+		appName,
+		endMs,
+		nowMs,
+		startMs,
+	)
+	event, err := scanFocusEvent(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.FocusEvent{}, false, nil
+		}
+		return models.FocusEvent{}, false, fmt.Errorf("find overlapping focus event: %w", err)
+	}
+	return event, true, nil
+}
+
+// SetFocusEventLink attaches url and domain to an event that has no url yet
+// or already carries the same one. It reports false when the event is linked
+// to a different url and was left unchanged.
+func (s *Store) SetFocusEventLink(id int64, url string, domain string) (bool, error) {
+	result, err := s.db.Exec(
+		`UPDATE focus_events
+		 SET url = CASE WHEN COALESCE(url, '') = '' THEN ? ELSE url END,
+		     domain = CASE WHEN COALESCE(domain, '') = '' THEN ? ELSE domain END
+		 WHERE id = ? AND COALESCE(url, '') IN ('', ?)`,
+		url,
+		domain,
+		id,
+		url,
+	)
+	if err != nil {
+		return false, fmt.Errorf("update focus event link: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("update focus event link: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// FocusMetrics summarises the windowMs before nowMs; the open event runs
+// until nowMs. Ingested events shadowed by a local one are not counted.
+func (s *Store) FocusMetrics(nowMs int64, windowMs int64) (models.FocusMetrics, error) {
 	if windowMs <= 0 {
 		windowMs = int64((10 * time.Minute).Milliseconds())
 	}
-	sinceMs := time.Now().UnixMilli() - windowMs
+	sinceMs := nowMs - windowMs
 	if sinceMs < 0 {
 		sinceMs = 0
 	}
 	rows, err := s.db.Query(
-		`SELECT ts_ms, duration_ms FROM focus_events WHERE ts_ms >= ? ORDER BY ts_ms ASC`,
+		`SELECT ts_ms, duration_ms FROM focus_events WHERE local_event_id = 0 AND ts_ms >= ? AND ts_ms <= ? ORDER BY ts_ms ASC`,
 		sinceMs,
+		nowMs,
 	)
 	if err != nil {
 		return models.FocusMetrics{}, fmt.Errorf("query focus metrics: %w", err)
@@ -742,7 +1091,6 @@ func (s *Store) FocusMetrics(windowMs int64) (models.FocusMetrics, error) {
 	}
 
 	var totalMs int64
-	nowMs := time.Now().UnixMilli()
 	for i, event := range events {
 		if event.durationMs > 0 {
 			totalMs += event.durationMs
@@ -772,6 +1120,30 @@ func (s *Store) FocusMetrics(windowMs int64) (models.FocusMetrics, error) {
 	}, nil
 }
 
+func (s *Store) InsertIdleSpan(tsMs int64) (int64, error) {
+	result, err := s.db.Exec(`INSERT INTO idle_spans (ts_ms, duration_ms) VALUES (?, 0)`, tsMs)
+	if err != nil {
+		return 0, fmt.Errorf("insert idle span: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("idle span id: %w", err)
+	}
+	return id, nil
+}
+
+func (s *Store) UpdateIdleDuration(id int64, durationMs int64) error {
+	_, err := s.db.Exec(
+		`UPDATE idle_spans SET duration_ms = ? WHERE id = ?`,
+		durationMs,
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("update idle duration: %w", err)
+	}
+	return nil
+}
+
 func (s *Store) InsertFocusStateSnapshot(snapshot models.FocusStateSnapshot) error {
 	_, err := s.db.Exec(
 		`INSERT INTO focus_state_snapshots (ts_ms, focus_state, switch_count, no_progress_ms, focus_minutes, app_name, window_title)
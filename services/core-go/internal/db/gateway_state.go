@@ -0,0 +1,142 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"always/core/internal/models"
+)
+
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// gatewayStatePayload is the part of models.GatewayState without a table of
+// its own.
+type gatewayStatePayload struct {
+	ModeBudgets        map[models.Mode]float64 `json:"mode_budgets"`
+	LastUpdateMs       map[models.Mode]int64   `json:"last_update_ms"`
+	LastInterventionMs int64                   `json:"last_intervention_ms"`
+}
+
+// LoadGatewayState returns the saved gateway state. found is false when no
+// state record exists yet; usage saved by older versions is still returned.
+func (s *Store) LoadGatewayState() (models.GatewayState, bool, error) {
+	state := models.GatewayState{Version: models.GatewayStateVersion}
+	usage, err := s.GetBudgetUsage()
+	if err != nil {
+		return state, false, err
+	}
+	state.Usage = usage
+	if state.Actions, err = s.ListActionUsage(); err != nil {
+		return state, false, err
+	}
+
+	var version int
+	var raw string
+	err = s.db.QueryRow(`SELECT version, payload FROM gateway_state WHERE id = 1`).Scan(&version, &raw)
+	if errors.Is(err, sql.ErrNoRows) {
+		return state, false, nil
+	}
+	if err != nil {
+		return state, false, fmt.Errorf("query gateway state: %w", err)
+	}
+	if version > models.GatewayStateVersion {
+		return state, false, fmt.Errorf("gateway state version %d is newer than %d", version, models.GatewayStateVersion)
+	}
+	var payload gatewayStatePayload
+	if err := json.Unmarshal([]byte(raw), &payload); err != nil {
+		return state, false, fmt.Errorf("decode gateway state: %w", err)
+	}
+	state.ModeBudgets = payload.ModeBudgets
+	state.LastUpdateMs = payload.LastUpdateMs
+	state.LastInterventionMs = payload.LastInterventionMs
+	return state, true, nil
+}
+
+// SaveGatewayState writes the state record, budget usage and per-action usage
+// in one transaction.
+func (s *Store) SaveGatewayState(state models.GatewayState) error {
+	payload, err := json.Marshal(gatewayStatePayload{
+		ModeBudgets:        state.ModeBudgets,
+		LastUpdateMs:       state.LastUpdateMs,
+		LastInterventionMs: state.LastInterventionMs,
+	})
+	if err != nil {
+		return fmt.Errorf("encode gateway state: %w", err)
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin save gateway state: %w", err)
+	}
+	defer tx.Rollback()
+
+	updatedAt := time.Now().UnixMilli()
+	if _, err := tx.Exec(
+		`INSERT INTO gateway_state (id, version, payload, updated_at_ms)
+		 VALUES (1, ?, ?, ?)
+		 ON CONFLICT(id) DO UPDATE SET
+		   version = excluded.version,
+		   payload = excluded.payload,
+		   updated_at_ms = excluded.updated_at_ms`,
+		models.GatewayStateVersion,
+		string(payload),
+		updatedAt,
+	); err != nil {
+		return fmt.Errorf("upsert gateway state: %w", err)
+	}
+	if err := upsertBudgetUsage(tx, state.Usage); err != nil {
+		return err
+	}
+	for _, usage := range state.Actions {
+		if _, err := tx.Exec(
+			`INSERT INTO action_usage (action_type, last_ms, daily_day, daily_count, ladder_level, ignored_streak, updated_at_ms)
+			 VALUES (?, ?, ?, ?, ?, ?, ?)
+			 ON CONFLICT(action_type) DO UPDATE SET
+			   last_ms = excluded.last_ms,
+			   daily_day = excluded.daily_day,
+			   daily_count = excluded.daily_count,
+			   ladder_level = excluded.ladder_level,
+			   ignored_streak = excluded.ignored_streak,
+			   updated_at_ms = excluded.updated_at_ms`,
+			usage.ActionType,
+			usage.LastMs,
+			usage.DailyDay,
+			usage.DailyCount,
+			usage.LadderLevel,
+			usage.IgnoredStreak,
+			updatedAt,
+		); err != nil {
+			return fmt.Errorf("upsert action usage: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit gateway state: %w", err)
+	}
+	return nil
+}
+
+func upsertBudgetUsage(db execer, usage models.BudgetUsage) error {
+	_, err := db.Exec(
+		`INSERT INTO budget_usage (id, daily_day, daily_used, hourly_hour, hourly_used, updated_at_ms)
+		 VALUES (1, ?, ?, ?, ?, ?)
+		 ON CONFLICT(id) DO UPDATE SET
+		   daily_day = excluded.daily_day,
+		   daily_used = excluded.daily_used,
+		   hourly_hour = excluded.hourly_hour,
+		   hourly_used = excluded.hourly_used,
+		   updated_at_ms = excluded.updated_at_ms`,
+		usage.DailyDay,
+		usage.DailyUsed,
+		usage.HourlyHour,
+		usage.HourlyUsed,
+		time.Now().UnixMilli(),
+	)
+	if err != nil {
+		return fmt.Errorf("upsert budget usage: %w", err)
+	}
+	return nil
+}
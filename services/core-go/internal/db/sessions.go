@@ -0,0 +1,160 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"always/core/internal/models"
+)
+
+const focusSessionColumns = `id, name, COALESCE(task, ''), target_minutes, status, started_at_ms, paused_at_ms, paused_ms, ended_at_ms, COALESCE(decision_request_id, '')`
+
+func scanFocusSession(row rowScanner) (models.FocusSession, error) {
+	var session models.FocusSession
+	err := row.Scan(
+		&session.ID,
+		&session.Name,
+		&session.Task,
+		&session.TargetMinutes,
+		&session.Status,
+		&session.StartedAtMs,
+		&session.PausedAtMs,
+		&session.PausedMs,
+		&session.EndedAtMs,
+		&session.DecisionRequestID,
+	)
+	return session, err
+}
+
+// InsertFocusSession stores session unless another one is still active or
+// paused; the check and the insert are one statement so concurrent starts
+// cannot both succeed. It reports false when nothing was inserted.
+func (s *Store) InsertFocusSession(session models.FocusSession) (int64, bool, error) {
+	result, err := s.db.Exec(
+		`INSERT INTO focus_sessions (name, task, target_minutes, status, started_at_ms)
+		 SELECT ?, ?, ?, ?, ?
+		 WHERE NOT EXISTS (SELECT 1 FROM focus_sessions WHERE status != ?)`,
+		session.Name,
+		session.Task,
+		session.TargetMinutes,
+		session.Status,
+		session.StartedAtMs,
+		models.SessionEnded,
+	)
+	if err != nil {
+		return 0, false, fmt.Errorf("insert focus session: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, false, fmt.Errorf("insert focus session: %w", err)
+	}
+	if affected == 0 {
+		return 0, false, nil
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, false, fmt.Errorf("focus session id: %w", err)
+	}
+	return id, true, nil
+}
+
+func (s *Store) UpdateFocusSession(session models.FocusSession) error {
+	_, err := s.db.Exec(
+		`UPDATE focus_sessions
+		 SET status = ?, paused_at_ms = ?, paused_ms = ?, ended_at_ms = ?, decision_request_id = ?
+		 WHERE id = ?`,
+		session.Status,
+		session.PausedAtMs,
+		session.PausedMs,
+		session.EndedAtMs,
+		session.DecisionRequestID,
+		session.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("update focus session: %w", err)
+	}
+	return nil
+}
+
+// EndFocusSession stores the ended session unless it has already ended; the
+// check and the update are one statement so concurrent ends cannot both
+// succeed. It reports false when nothing was updated.
+func (s *Store) EndFocusSession(session models.FocusSession) (bool, error) {
+	result, err := s.db.Exec(
+		`UPDATE focus_sessions
+		 SET status = ?, paused_at_ms = ?, paused_ms = ?, ended_at_ms = ?
+		 WHERE id = ? AND status != ?`,
+		models.SessionEnded,
+		session.PausedAtMs,
+		session.PausedMs,
+		session.EndedAtMs,
+		session.ID,
+		models.SessionEnded,
+	)
+	if err != nil {
+		return false, fmt.Errorf("end focus session: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("end focus session: %w", err)
+	}
+	return affected > 0, nil
+}
+
+func (s *Store) GetFocusSession(id int64) (models.FocusSession, bool, error) {
+	row := s.db.QueryRow(`SELECT `+focusSessionColumns+` FROM focus_sessions WHERE id = ?`, id)
+	session, err := scanFocusSession(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.FocusSession{}, false, nil
+		}
+		return models.FocusSession{}, false, fmt.Errorf("get focus session: %w", err)
+	}
+	return session, true, nil
+}
+
+// CurrentFocusSession returns the session that is active or paused, if any.
+func (s *Store) CurrentFocusSession() (models.FocusSession, bool, error) {
+	row := s.db.QueryRow(
+		`SELECT `+focusSessionColumns+`
+		 FROM focus_sessions WHERE status != ? ORDER BY started_at_ms DESC, id DESC LIMIT 1`,
+		models.SessionEnded,
+	)
+	session, err := scanFocusSession(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.FocusSession{}, false, nil
+		}
+		return models.FocusSession{}, false, fmt.Errorf("current focus session: %w", err)
+	}
+	return session, true, nil
+}
+
+func (s *Store) ListFocusSessions(limit int) ([]models.FocusSession, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	rows, err := s.db.Query(
+		`SELECT `+focusSessionColumns+`
+		 FROM focus_sessions ORDER BY started_at_ms DESC, id DESC LIMIT ?`,
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list focus sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []models.FocusSession
+	for rows.Next() {
+		session, err := scanFocusSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan focus session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("focus session rows: %w", err)
+	}
+	return sessions, nil
+}
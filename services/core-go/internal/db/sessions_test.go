@@ -0,0 +1,51 @@
+package db
+
+import (
+	"sync"
+	"testing"
+
+	"always/core/internal/models"
+)
+
+func TestEndFocusSessionOnlyOnce(t *testing.T) {
+	store := openTestStore(t)
+	id, ok, err := store.InsertFocusSession(models.FocusSession{Name: "focus", TargetMinutes: 25, Status: models.SessionActive, StartedAtMs: 1})
+	if err != nil || !ok {
+		t.Fatalf("insert = %v, %v", ok, err)
+	}
+	const enders = 8
+	var wg sync.WaitGroup
+	ended := make(chan bool, enders)
+	errs := make(chan error, enders)
+	for i := range enders {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok, err := store.EndFocusSession(models.FocusSession{ID: id, EndedAtMs: int64(1000 + i)})
+			if err != nil {
+				errs <- err
+				return
+			}
+			ended <- ok
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	close(ended)
+	for err := range errs {
+		t.Fatalf("end: %v", err)
+	}
+	count := 0
+	for ok := range ended {
+		if ok {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Fatalf("%d ends succeeded, want 1", count)
+	}
+	session, _, err := store.GetFocusSession(id)
+	if err != nil || session.Status != models.SessionEnded || session.EndedAtMs < 1000 {
+		t.Fatalf("session = %+v, %v", session, err)
+	}
+}
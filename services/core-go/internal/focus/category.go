@@ -0,0 +1,140 @@
+package focus
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"always/core/internal/models"
+)
+
+// Categorizer maps focus events to productive / neutral / distracting using
+// rules ordered by priority. Events no rule matches are neutral.
+type Categorizer struct {
+	rules []compiledCategoryRule
+}
+
+type compiledCategoryRule struct {
+	rule  models.FocusCategoryRule
+	title *regexp.Regexp
+}
+
+func NewCategorizer(rules []models.FocusCategoryRule) *Categorizer {
+	c := &Categorizer{}
+	for _, rule := range rules {
+		compiled := compiledCategoryRule{rule: rule}
+		if rule.MatchField == models.CategoryMatchWindowTitle {
+			re, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				continue
+			}
+			compiled.title = re
+		}
+		c.rules = append(c.rules, compiled)
+	}
+	return c
+}
+
+func ValidateCategoryRule(rule models.FocusCategoryRule) error {
+	switch rule.Category {
+	case models.CategoryProductive, models.CategoryNeutral, models.CategoryDistracting:
+	default:
+		return fmt.Errorf("invalid category")
+	}
+	if strings.TrimSpace(rule.Pattern) == "" {
+		return fmt.Errorf("pattern required")
+	}
+	switch rule.MatchField {
+	case models.CategoryMatchAppName, models.CategoryMatchBundleID, models.CategoryMatchDomain:
+	case models.CategoryMatchWindowTitle:
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern: %v", err)
+		}
+	default:
+		return fmt.Errorf("invalid match_field")
+	}
+	return nil
+}
+
+func (c *Categorizer) Categorize(event models.FocusEvent) string {
+	if c == nil {
+		return models.CategoryNeutral
+	}
+	for _, compiled := range c.rules {
+		if compiled.matches(event) {
+			return compiled.rule.Category
+		}
+	}
+	return models.CategoryNeutral
+}
+
+func (r compiledCategoryRule) matches(event models.FocusEvent) bool {
+	switch r.rule.MatchField {
+	case models.CategoryMatchAppName:
+		return event.AppName != "" && strings.EqualFold(event.AppName, r.rule.Pattern)
+	case models.CategoryMatchBundleID:
+		return event.BundleID != "" && strings.EqualFold(event.BundleID, r.rule.Pattern)
+	case models.CategoryMatchWindowTitle:
+		return r.title != nil && event.WindowTitle != "" && r.title.MatchString(event.WindowTitle)
+	case models.CategoryMatchDomain:
+		domain := strings.ToLower(event.Domain)
+		pattern := strings.ToLower(r.rule.Pattern)
+		return domain != "" && (domain == pattern || strings.HasSuffix(domain, "."+pattern))
+	default:
+		return false
+	}
+}
+
+// Annotate fills Category on each event in place.
+func (c *Categorizer) Annotate(events []models.FocusEvent) {
+	for i := range events {
+		events[i].Category = c.Categorize(events[i])
+	}
+}
+
+// CategoryDurations sums the time spent per category inside [sinceMs, untilMs).
+// Events must be in ascending order, as returned by Store.ListFocusEventsRange.
+func CategoryDurations(events []models.FocusEvent, c *Categorizer, sinceMs int64, untilMs int64) map[string]int64 {
+	totals := map[string]int64{
+		models.CategoryProductive:  0,
+		models.CategoryNeutral:     0,
+		models.CategoryDistracting: 0,
+	}
+	for i, event := range events {
+		start, end := clipSpan(event.TsMs, EventEnd(events, i, untilMs), sinceMs, untilMs)
+		if end > start {
+			totals[c.Categorize(event)] += end - start
+		}
+	}
+	return totals
+}
+
+// EventEnd resolves when events[i] stopped, the same way Store.FocusMetrics
+// does: recorded duration first, then the next event's start, then nowMs for
+// the still-open event.
+func EventEnd(events []models.FocusEvent, i int, nowMs int64) int64 {
+	event := events[i]
+	if event.DurationMs > 0 {
+		return event.TsMs + event.DurationMs
+	}
+	if i+1 < len(events) {
+		if next := events[i+1].TsMs; next > event.TsMs {
+			return next
+		}
+		return event.TsMs
+	}
+	if nowMs > event.TsMs {
+		return nowMs
+	}
+	return event.TsMs
+}
+
+func clipSpan(start, end, sinceMs, untilMs int64) (int64, int64) {
+	if start < sinceMs {
+		start = sinceMs
+	}
+	if untilMs > 0 && end > untilMs {
+		end = untilMs
+	}
+	return start, end
+}
@@ -0,0 +1,97 @@
+package focus
+
+import (
+	"testing"
+
+	"always/core/internal/models"
+)
+
+func TestCategorize(t *testing.T) {
+	rules := []models.FocusCategoryRule{
+		{Category: models.CategoryDistracting, MatchField: models.CategoryMatchDomain, Pattern: "youtube.com", Priority: 30},
+		{Category: models.CategoryDistracting, MatchField: models.CategoryMatchWindowTitle, Pattern: `(?i)youtube`, Priority: 20},
+		{Category: models.CategoryProductive, MatchField: models.CategoryMatchBundleID, Pattern: "com.microsoft.VSCode"},
+		{Category: models.CategoryProductive, MatchField: models.CategoryMatchAppName, Pattern: "Code"},
+		{Category: models.CategoryNeutral, MatchField: models.CategoryMatchWindowTitle, Pattern: `(`},
+	}
+	c := NewCategorizer(rules)
+	tests := []struct {
+		name  string
+		event models.FocusEvent
+		want  string
+	}{
+		{name: "app name ignores case", event: models.FocusEvent{AppName: "code"}, want: models.CategoryProductive},
+		{name: "bundle id", event: models.FocusEvent{AppName: "Electron", BundleID: "com.microsoft.vscode"}, want: models.CategoryProductive},
+		{name: "title outranks app", event: models.FocusEvent{AppName: "Code", WindowTitle: "YouTube - Code"}, want: models.CategoryDistracting},
+		{name: "subdomain", event: models.FocusEvent{AppName: "Chrome", Domain: "m.YouTube.com"}, want: models.CategoryDistracting},
+		{name: "lookalike domain", event: models.FocusEvent{AppName: "Chrome", Domain: "notyoutube.com"}, want: models.CategoryNeutral},
+		{name: "no match", event: models.FocusEvent{AppName: "Finder"}, want: models.CategoryNeutral},
+	}
+	for _, tt := range tests {
+		if got := c.Categorize(tt.event); got != tt.want {
+			t.Errorf("%s: category = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+	var none *Categorizer
+	if got := none.Categorize(models.FocusEvent{AppName: "Code"}); got != models.CategoryNeutral {
+		t.Errorf("nil categorizer = %s", got)
+	}
+}
+
+func TestValidateCategoryRule(t *testing.T) {
+	tests := []struct {
+		rule models.FocusCategoryRule
+		ok   bool
+	}{
+		{rule: models.FocusCategoryRule{Category: models.CategoryProductive, MatchField: models.CategoryMatchAppName, Pattern: "Code"}, ok: true},
+		{rule: models.FocusCategoryRule{Category: models.CategoryDistracting, MatchField: models.CategoryMatchWindowTitle, Pattern: `(?i)you\s*tube`}, ok: true},
+		{rule: models.FocusCategoryRule{Category: "fun", MatchField: models.CategoryMatchAppName, Pattern: "Code"}},
+		{rule: models.FocusCategoryRule{Category: models.CategoryNeutral, MatchField: models.CategoryMatchAppName, Pattern: "  "}},
+		{rule: models.FocusCategoryRule{Category: models.CategoryNeutral, MatchField: "pid", Pattern: "1"}},
+		{rule: models.FocusCategoryRule{Category: models.CategoryNeutral, MatchField: models.CategoryMatchWindowTitle, Pattern: "("}},
+	}
+	for _, tt := range tests {
+		if err := ValidateCategoryRule(tt.rule); (err == nil) != tt.ok {
+			t.Errorf("ValidateCategoryRule(%+v) = %v, want ok=%v", tt.rule, err, tt.ok)
+		}
+	}
+}
+
+func TestCategoryDurations(t *testing.T) {
+	c := NewCategorizer([]models.FocusCategoryRule{
+		{Category: models.CategoryProductive, MatchField: models.CategoryMatchAppName, Pattern: "Code"},
+		{Category: models.CategoryDistracting, MatchField: models.CategoryMatchAppName, Pattern: "Discord"},
+	})
+	events := []models.FocusEvent{
+		{TsMs: 0, AppName: "Code", DurationMs: 10_000},
+		{TsMs: 10_000, AppName: "Discord"},
+		{TsMs: 25_000, AppName: "Finder"},
+		{TsMs: 30_000, AppName: "Code"},
+	}
+	got := CategoryDurations(events, c, 5_000, 40_000)
+	want := map[string]int64{
+		models.CategoryProductive:  5_000 + 10_000,
+		models.CategoryDistracting: 15_000,
+		models.CategoryNeutral:     5_000,
+	}
+	for category, ms := range want {
+		if got[category] != ms {
+			t.Errorf("%s = %d, want %d", category, got[category], ms)
+		}
+	}
+}
+
+func TestSeededCategoryRules(t *testing.T) {
+	store := openTestStore(t)
+	rules, err := store.ListFocusCategoryRules()
+	if err != nil || len(rules) == 0 {
+		t.Fatalf("seeded rules = %d, %v", len(rules), err)
+	}
+	c := NewCategorizer(rules)
+	if got := c.Categorize(models.FocusEvent{AppName: "Google Chrome", WindowTitle: "Lo-fi - YouTube"}); got != models.CategoryDistracting {
+		t.Errorf("youtube title = %s", got)
+	}
+	if got := c.Categorize(models.FocusEvent{AppName: "Google Chrome", Domain: "github.com", WindowTitle: "netflix/repo"}); got != models.CategoryProductive {
+		t.Errorf("github domain = %s, domains should outrank titles", got)
+	}
+}
@@ -0,0 +1,145 @@
+package focus
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"always/core/internal/models"
+)
+
+const (
+	settingMicroSwitchSeconds = "focus_micro_switch_seconds"
+	defaultMicroSwitch        = 3 * time.Second
+	compactionInterval        = 15 * time.Minute
+	compactionRetry           = time.Minute
+)
+
+// SetMicroSwitchThreshold changes how short a visit must be to be merged back
+// into the surrounding event. Zero disables merging.
+func (m *Monitor) SetMicroSwitchThreshold(threshold time.Duration) {
+	if threshold < 0 {
+		threshold = 0
+	}
+	m.mu.Lock()
+	m.microSwitch = threshold
+	m.mu.Unlock()
+}
+
+func (m *Monitor) loadMicroSwitchThreshold() {
+	value, ok, err := m.store.GetSetting(settingMicroSwitchSeconds)
+	if err != nil {
+		m.logger.Error("load micro-switch threshold failed", slog.Any("error", err))
+		return
+	}
+	if !ok {
+		return
+	}
+	seconds, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || seconds < 0 {
+		return
+	}
+	m.SetMicroSwitchThreshold(time.Duration(seconds) * time.Second)
+}
+
+// mergeMicroSwitch handles returning to the previous app after a visit shorter
+// than the micro-switch threshold: the visit is folded into the previous
+// event, which is reopened, and neither switch is counted. It reports whether
+// the snapshot was consumed.
+func (m *Monitor) mergeMicroSwitch(snapshot FocusSnapshot, nowMs int64) bool {
+	m.mu.Lock()
+	prev, last := m.prev, m.last
+	if m.microSwitch <= 0 || !m.hasLast || !m.hasPrev || prev.ID == 0 || last.ID == 0 ||
+		sameApp(snapshot, last) || !sameApp(snapshot, prev) ||
+		nowMs-last.TsMs >= m.microSwitch.Milliseconds() {
+		m.mu.Unlock()
+		return false
+	}
+	m.rememberRawTitleLocked(snapshot)
+	if snapshot.WindowTitle != "" {
+		m.lastWindowTitle = snapshot.WindowTitle
+		m.lastTitleStored = false
+	}
+	if n := len(m.switches); n > 0 && m.switches[n-1] == last.TsMs {
+		m.switches = m.switches[:n-1]
+	}
+	if n := len(m.visits); n > 1 && m.visits[n-1].StartMs == last.TsMs {
+		m.visits = m.visits[:n-1]
+		m.visits[n-2].EndMs = 0
+	}
+	prev.DurationMs = 0
+	prev.MergedPeeks++
+	m.last = prev
+	m.prev = models.FocusEvent{}
+	m.hasPrev = false
+	m.mu.Unlock()
+
+	if err := m.store.MergeFocusPeek(prev.ID, last.ID, nowMs-last.TsMs); err != nil {
+		m.logger.Error("merge focus micro-switch failed", slog.Any("error", err))
+	}
+	return true
+}
+
+// compactLoop periodically rewrites stored events older than the switch
+// window into compact spans while monitoring is enabled. The first pass covers
+// all history; a failed pass is retried sooner than the regular interval.
+func (m *Monitor) compactLoop(ctx context.Context) {
+	var sinceMs int64
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		wait := compactionInterval
+		if m.Enabled() {
+			if result, err := m.Compact(sinceMs); err != nil {
+				m.logger.Error("compact focus events failed", slog.Any("error", err), slog.Duration("retry_in", compactionRetry))
+				wait = compactionRetry
+			} else {
+				// Re-scan one window back so spans cut at the last boundary can join.
+				sinceMs = max(result.UntilMs-m.currentSwitchWindow().Milliseconds(), 0)
+			}
+		}
+		timer.Reset(wait)
+	}
+}
+
+// Compact runs one compaction pass over events that started between sinceMs
+// and one switch window ago. The monitor's open and previous events are left
+// alone, since a micro-switch may still fold one into the other.
+func (m *Monitor) Compact(sinceMs int64) (models.FocusCompaction, error) {
+	m.mu.RLock()
+	threshold := m.microSwitch
+	window := m.switchWindow
+	var held []int64
+	if m.hasLast {
+		held = append(held, m.last.ID)
+	}
+	if m.hasPrev {
+		held = append(held, m.prev.ID)
+	}
+	m.mu.RUnlock()
+	untilMs := m.clock.Now().UnixMilli() - window.Milliseconds()
+	result, err := m.store.CompactFocusEvents(sinceMs, untilMs, threshold.Milliseconds(), held...)
+	if err != nil {
+		return result, err
+	}
+	if result.Deleted > 0 {
+		m.logger.Info("focus events compacted",
+			slog.Int("scanned", result.Scanned),
+			slog.Int("deleted", result.Deleted),
+			slog.Int("merged_peeks", result.MergedPeeks))
+	}
+	return result, nil
+}
+
+func (m *Monitor) currentSwitchWindow() time.Duration {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.switchWindow
+}
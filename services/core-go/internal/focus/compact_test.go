@@ -0,0 +1,86 @@
+package focus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"always/core/internal/models"
+)
+
+func TestCompactLeavesHeldEvents(t *testing.T) {
+	m, store, clk := newReplayHarness(t, nil)
+	if _, err := store.InsertFocusEvent(models.FocusEvent{
+		TsMs:       testStart.Add(-10 * time.Minute).UnixMilli(),
+		AppName:    "Code",
+		PID:        1,
+		DurationMs: (10 * time.Minute).Milliseconds(),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	observeAt(m, clk, 0, FocusSnapshot{AppName: "Code", PID: 1})
+	observeAt(m, clk, 20*time.Minute, FocusSnapshot{AppName: "Slack", PID: 2})
+
+	clk.Set(testStart.Add(20*time.Minute + time.Second))
+	result, err := m.Compact(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Deleted != 0 || len(focusEvents(t, store)) != 3 {
+		t.Fatalf("compaction touched the monitor's previous event: %+v", result)
+	}
+
+	// The Slack visit is still short enough to fold back into the Code event.
+	observeAt(m, clk, 20*time.Minute+2*time.Second, FocusSnapshot{AppName: "Code", PID: 1})
+	events := focusEvents(t, store)
+	if len(events) != 2 || events[1].MergedPeeks != 1 || events[0].MergedPeeks != 0 {
+		t.Fatalf("events = %+v", events)
+	}
+}
+
+func TestCompactLoopRunsOnlyWhileEnabled(t *testing.T) {
+	m, store, clk := newReplayHarness(t, nil)
+	for i := range 3 {
+		if _, err := store.InsertFocusEvent(models.FocusEvent{
+			TsMs:       testStart.Add(time.Duration(i) * time.Minute).UnixMilli(),
+			AppName:    "Code",
+			DurationMs: time.Minute.Milliseconds(),
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	clk.Set(testStart.Add(time.Hour))
+
+	runLoop := func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			m.compactLoop(ctx)
+			close(done)
+		}()
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("compaction loop did not stop")
+		}
+	}
+
+	if err := m.SetEnabled(false); err != nil {
+		t.Fatal(err)
+	}
+	runLoop()
+	if events := focusEvents(t, store); len(events) != 3 {
+		t.Fatalf("disabled monitor compacted: %+v", events)
+	}
+
+	if err := m.SetEnabled(true); err != nil {
+		t.Fatal(err)
+	}
+	runLoop()
+	// Re-enabling reloads the newest event as the monitor's own, so it stays.
+	if events := focusEvents(t, store); len(events) != 2 || events[0].DurationMs != (2*time.Minute).Milliseconds() {
+		t.Fatalf("events = %+v", events)
+	}
+}
@@ -0,0 +1,133 @@
+package focus
+
+import (
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"always/core/internal/models"
+)
+
+// handleIdle closes the open focus event once the user has been idle longer
+// than the threshold and reports whether the snapshot should be ignored. The
+// first active snapshot afterwards ends the idle span and starts a fresh event
+// that does not count as a switch.
+func (m *Monitor) handleIdle(snapshot FocusSnapshot, nowMs int64) bool {
+	m.mu.RLock()
+	threshold := m.idleThreshold
+	idle := m.idle
+	m.mu.RUnlock()
+
+	if threshold > 0 && snapshot.IdleMs >= threshold.Milliseconds() {
+		if !idle {
+			m.beginIdle(nowMs - snapshot.IdleMs)
+		}
+		return true
+	}
+	if idle {
+		m.endIdle(nowMs)
+	}
+	return false
+}
+
+func (m *Monitor) beginIdle(startMs int64) {
+	m.mu.Lock()
+	last := m.last
+	hasLast := m.hasLast
+	m.last = models.FocusEvent{}
+	m.hasLast = false
+	m.hasPrev = false
+	m.endVisitLocked(startMs)
+	m.idle = true
+	m.idleStartMs = startMs
+	m.idleSpanID = 0
+	m.mu.Unlock()
+
+	if hasLast && last.ID != 0 && last.DurationMs == 0 {
+		duration := startMs - last.TsMs
+		if duration < 0 {
+			duration = 0
+		}
+		if err := m.store.UpdateFocusDuration(last.ID, duration); err != nil {
+			m.logger.Error("close focus event on idle failed", slog.Any("error", err))
+		}
+	}
+
+	id, err := m.store.InsertIdleSpan(startMs)
+	if err != nil {
+		m.logger.Error("insert idle span failed", slog.Any("error", err))
+		return
+	}
+	m.mu.Lock()
+	m.idleSpanID = id
+	m.mu.Unlock()
+	m.logger.Info("focus idle detected", slog.Int64("idle_since_ms", startMs))
+}
+
+func (m *Monitor) endIdle(nowMs int64) {
+	m.mu.Lock()
+	if !m.idle {
+		m.mu.Unlock()
+		return
+	}
+	spanID := m.idleSpanID
+	duration := nowMs - m.idleStartMs
+	if duration < 0 {
+		duration = 0
+	}
+	m.idle = false
+	m.idleStartMs = 0
+	m.idleSpanID = 0
+	m.lastTitleChange = nowMs
+	m.noProgress = false
+	m.mu.Unlock()
+
+	if spanID != 0 {
+		if err := m.store.UpdateIdleDuration(spanID, duration); err != nil {
+			m.logger.Error("close idle span failed", slog.Any("error", err))
+		}
+	}
+	m.logger.Info("focus idle ended", slog.Int64("idle_ms", duration))
+}
+
+// Idle reports whether the user is currently away and for how long.
+func (m *Monitor) Idle() (bool, time.Duration) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if !m.idle {
+		return false, 0
+	}
+	elapsedMs := m.clock.Now().UnixMilli() - m.idleStartMs
+	if elapsedMs < 0 {
+		elapsedMs = 0
+	}
+	return true, time.Duration(elapsedMs) * time.Millisecond
+}
+
+// SetIdleThreshold changes how long input must be absent before the user is
+// considered away. Zero disables idle detection.
+func (m *Monitor) SetIdleThreshold(threshold time.Duration) {
+	if threshold < 0 {
+		threshold = 0
+	}
+	m.mu.Lock()
+	m.idleThreshold = threshold
+	m.mu.Unlock()
+}
+
+func (m *Monitor) loadIdleThreshold() {
+	value, ok, err := m.store.GetSetting(settingFocusIdleThreshold)
+	if err != nil {
+		m.logger.Error("load idle threshold failed", slog.Any("error", err))
+		return
+	}
+	if !ok {
+		return
+	}
+	seconds, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || seconds < 0 {
+		return
+	}
+	m.SetIdleThreshold(time.Duration(seconds) * time.Second)
+}
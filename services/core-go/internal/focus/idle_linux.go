@@ -0,0 +1,100 @@
+//go:build linux
+
+package focus
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const idleProbeTimeout = 2 * time.Second
+
+// idleSource reports how long the user has been away from keyboard and mouse.
+type idleSource interface {
+	IdleMs() (int64, error)
+}
+
+func selectIdleSource(backend windowBackend) idleSource {
+	if backend.Name() == "x11" {
+		if path, err := exec.LookPath("xprintidle"); err == nil {
+			return xprintidleSource{path: path}
+		}
+	}
+	if path, err := exec.LookPath("loginctl"); err == nil {
+		session := os.Getenv("XDG_SESSION_ID")
+		if session == "" {
+			session = "self"
+		}
+		return logindIdleSource{path: path, session: session}
+	}
+	return nil
+}
+
+// xprintidleSource reads the XScreenSaver extension idle counter.
+type xprintidleSource struct {
+	path string
+}
+
+func (x xprintidleSource) IdleMs() (int64, error) {
+	output, err := runIdleProbe(x.path)
+	if err != nil {
+		return 0, err
+	}
+	idle, err := strconv.ParseInt(strings.TrimSpace(output), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse xprintidle output: %w", err)
+	}
+	return idle, nil
+}
+
+// logindIdleSource uses the session IdleHint that compositors and screen
+// lockers report to systemd-logind.
+type logindIdleSource struct {
+	path    string
+	session string
+}
+
+func (l logindIdleSource) IdleMs() (int64, error) {
+	output, err := runIdleProbe(l.path, "show-session", l.session, "-p", "IdleHint", "-p", "IdleSinceHint")
+	if err != nil {
+		return 0, err
+	}
+	return parseLogindIdle(output, time.Now())
+}
+
+func parseLogindIdle(output string, now time.Time) (int64, error) {
+	values := map[string]string{}
+	for _, line := range strings.Split(output, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if ok {
+			values[key] = value
+		}
+	}
+	if values["IdleHint"] != "yes" {
+		return 0, nil
+	}
+	sinceUs, err := strconv.ParseInt(values["IdleSinceHint"], 10, 64)
+	if err != nil || sinceUs <= 0 {
+		return 0, fmt.Errorf("parse IdleSinceHint %q", values["IdleSinceHint"])
+	}
+	idle := now.UnixMilli() - sinceUs/1000
+	if idle < 0 {
+		idle = 0
+	}
+	return idle, nil
+}
+
+func runIdleProbe(path string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), idleProbeTimeout)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, path, args...).Output()
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	return string(output), nil
+}
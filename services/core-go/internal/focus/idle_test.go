@@ -0,0 +1,60 @@
+package focus
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIdleClosesEventAndResumes(t *testing.T) {
+	m, store, clk := newReplayHarness(t, map[string]string{settingFocusIdleThreshold: "300"})
+	observeAt(m, clk, 0, FocusSnapshot{AppName: "Code", PID: 1})
+	// Ten minutes in the user has not touched anything for six.
+	observeAt(m, clk, 10*time.Minute, FocusSnapshot{AppName: "Code", PID: 1, IdleMs: (6 * time.Minute).Milliseconds()})
+
+	idle, away := m.Idle()
+	if !idle || away != 6*time.Minute {
+		t.Fatalf("idle = %v, %s; want true, 6m", idle, away)
+	}
+	events := focusEvents(t, store)
+	if len(events) != 1 || events[0].DurationMs != (4*time.Minute).Milliseconds() {
+		t.Fatalf("events = %+v, want the open event closed at the idle start", events)
+	}
+
+	// Further idle snapshots are ignored.
+	observeAt(m, clk, 11*time.Minute, FocusSnapshot{AppName: "Code", PID: 1, IdleMs: (7 * time.Minute).Milliseconds()})
+	if got := len(focusEvents(t, store)); got != 1 {
+		t.Fatalf("idle snapshot recorded an event, have %d", got)
+	}
+
+	observeAt(m, clk, 15*time.Minute, FocusSnapshot{AppName: "Code", PID: 1})
+	if idle, _ := m.Idle(); idle {
+		t.Fatal("still idle after input")
+	}
+	events = focusEvents(t, store)
+	if len(events) != 2 || events[1].TsMs != testStart.Add(15*time.Minute).UnixMilli() {
+		t.Fatalf("events = %+v, want a fresh event on return", events)
+	}
+	if m.SwitchCount() != 0 {
+		t.Fatalf("returning from idle counted %d switches", m.SwitchCount())
+	}
+
+	var spanStart, spanDuration int64
+	if err := store.DB().QueryRow(`SELECT ts_ms, duration_ms FROM idle_spans`).Scan(&spanStart, &spanDuration); err != nil {
+		t.Fatalf("idle span: %v", err)
+	}
+	if spanStart != testStart.Add(4*time.Minute).UnixMilli() || spanDuration != (11*time.Minute).Milliseconds() {
+		t.Fatalf("idle span = %d, %d", spanStart, spanDuration)
+	}
+}
+
+func TestIdleThresholdZeroDisables(t *testing.T) {
+	m, store, clk := newReplayHarness(t, map[string]string{settingFocusIdleThreshold: "0"})
+	observeAt(m, clk, 0, FocusSnapshot{AppName: "Code", PID: 1})
+	observeAt(m, clk, time.Hour, FocusSnapshot{AppName: "Code", PID: 1, IdleMs: time.Hour.Milliseconds()})
+	if idle, _ := m.Idle(); idle {
+		t.Fatal("idle detected with the threshold disabled")
+	}
+	if events := focusEvents(t, store); len(events) != 1 || events[0].DurationMs != 0 {
+		t.Fatalf("events = %+v, want the event left open", events)
+	}
+}
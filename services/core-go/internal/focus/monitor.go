@@ -1,12 +1,17 @@
 package focus
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"log/slog"
+	"os"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"always/core/internal/clock"
 	"always/core/internal/db"
 	"always/core/internal/models"
 )
@@ -15,11 +20,16 @@ const (
 	defaultPollInterval   = time.Second
 	defaultSwitchWindow   = 10 * time.Minute
 	defaultNoProgressHold = 45 * time.Minute
+	defaultIdleThreshold  = 5 * time.Minute
 )
 
 var ErrUnsupported = errors.New("focus monitor unsupported")
 
-const settingFocusMonitorEnabled = "focus_monitor_enabled"
+const (
+	settingFocusMonitorEnabled = "focus_monitor_enabled"
+	settingFocusProviderCmd    = "focus_provider_cmd"
+	settingFocusIdleThreshold  = "focus_idle_threshold_seconds"
+)
 
 type FocusSnapshot struct {
 	TsMs        int64
@@ -27,9 +37,13 @@ type FocusSnapshot struct {
 	BundleID    string
 	PID         int
 	WindowTitle string
+	// IdleMs is the time since the last keyboard or mouse input, 0 when the
+	// provider cannot tell.
+	IdleMs int64
 }
 
 type provider interface {
+	Name() string
 	Current() (FocusSnapshot, error)
 }
 
@@ -37,64 +51,189 @@ type Monitor struct {
 	store    *db.Store
 	logger   *slog.Logger
 	interval time.Duration
-	provider provider
+	clock    clock.Clock
 
 	enabled atomic.Bool
 
 	mu              sync.RWMutex
+	provider        provider
+	health          models.FocusProviderHealth
+	streamCancel    context.CancelFunc
+	stop            context.CancelFunc
 	last            models.FocusEvent
 	hasLast         bool
+	prev            models.FocusEvent
+	hasPrev         bool
+	microSwitch     time.Duration
 	lastWindowTitle string
+	// lastTitleStored is set while lastWindowTitle was restored from the
+	// store, where titles are already redacted.
+	lastTitleStored bool
 	switchWindow    time.Duration
 	switches        []int64
+	visits          []Visit
 	lastTitleChange int64
 	noProgressHold  time.Duration
 	noProgress      bool
+	idleThreshold   time.Duration
+	idle            bool
+	idleStartMs     int64
+	idleSpanID      int64
+	redactor        *Redactor
+	rawTitles       []models.FocusEvent
 }
 
 func NewMonitor(store *db.Store, logger *slog.Logger, interval time.Duration) *Monitor {
 	if interval <= 0 {
 		interval = defaultPollInterval
 	}
-	prov, err := newProvider(logger)
-	if err != nil {
-		logger.Warn("focus provider unavailable", slog.Any("error", err))
-	}
-	return &Monitor{
+	m := &Monitor{
 		store:          store,
 		logger:         logger,
 		interval:       interval,
-		provider:       prov,
+		clock:          clock.System,
 		switchWindow:   defaultSwitchWindow,
 		noProgressHold: defaultNoProgressHold,
+		idleThreshold:  defaultIdleThreshold,
+		microSwitch:    defaultMicroSwitch,
 	}
+	prov, err := m.resolveProvider()
+	if err != nil {
+		logger.Warn("focus provider unavailable", slog.Any("error", err))
+	}
+	m.setProvider(prov)
+	return m
 }
 
-func (m *Monitor) Start() {
+// resolveProvider prefers the focus_provider_cmd setting, then the
+// FOCUS_PROVIDER_CMD environment variable, then the platform provider.
+func (m *Monitor) resolveProvider() (provider, error) {
+	command := ""
+	if value, ok, err := m.store.GetSetting(settingFocusProviderCmd); err != nil {
+		m.logger.Error("load focus provider setting failed", slog.Any("error", err))
+	} else if ok {
+		command = strings.TrimSpace(value)
+	}
+	if command == "" {
+		command = strings.TrimSpace(os.Getenv(envProviderCmd))
+	}
+	if command != "" && command != ProviderCommandBuiltin {
+		return newCommandProvider(command)
+	}
+	return newProvider(m.logger)
+}
+
+// SetProviderCommand swaps the active provider for an external command, or
+// back to the platform provider when command is ProviderCommandBuiltin.
+func (m *Monitor) SetProviderCommand(command string) error {
+	command = strings.TrimSpace(command)
+	var prov provider
+	var err error
+	if command == "" || command == ProviderCommandBuiltin {
+		prov, err = newProvider(m.logger)
+	} else {
+		prov, err = newCommandProvider(command)
+	}
+	if err != nil {
+		return fmt.Errorf("set focus provider: %w", err)
+	}
+	m.setProvider(prov)
+	enabled, err := m.loadEnabledSetting()
+	if err != nil {
+		return err
+	}
+	return m.SetEnabled(enabled)
+}
+
+func (m *Monitor) setProvider(prov provider) {
+	m.stopStream()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.provider = prov
+	m.health = models.FocusProviderHealth{}
+	if prov != nil {
+		m.health.Name = prov.Name()
+	}
+}
+
+func (m *Monitor) currentProvider() provider {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.provider
+}
+
+// ProviderHealth reports the outcome of recent polls, or nil when no provider
+// is configured.
+func (m *Monitor) ProviderHealth() *models.FocusProviderHealth {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	if m.provider == nil {
+		return nil
+	}
+	health := m.health
+	return &health
+}
+
+func (m *Monitor) recordPoll(err error) {
+	nowMs := m.clock.Now().UnixMilli()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err != nil {
+		m.health.Healthy = false
+		m.health.LastError = err.Error()
+		m.health.LastErrorMs = nowMs
+		m.health.ConsecutiveFailures++
 		return
 	}
+	m.health.Healthy = true
+	m.health.LastSuccessMs = nowMs
+	m.health.ConsecutiveFailures = 0
+}
+
+func (m *Monitor) Start() {
+	m.loadRedactionRules()
+	m.loadIdleThreshold()
+	m.loadThresholds()
 	enabled, err := m.loadEnabledSetting()
 	if err != nil {
 		m.logger.Error("load focus setting failed", slog.Any("error", err))
 	}
-	m.enabled.Store(enabled)
-	if enabled {
+	m.enabled.Store(enabled && m.currentProvider() != nil)
+	if m.Enabled() {
 		m.loadLastEvent()
 	}
-	go m.loop()
+	ctx, cancel := context.WithCancel(context.Background())
+	m.mu.Lock()
+	m.stop = cancel
+	m.mu.Unlock()
+	go m.loop(ctx)
+	go m.compactLoop(ctx)
+}
+
+// Stop ends polling, streaming and background compaction started by Start.
+func (m *Monitor) Stop() {
+	m.mu.RLock()
+	stop := m.stop
+	m.mu.RUnlock()
+	if stop != nil {
+		stop()
+	}
+	m.stopStream()
 }
 
 func (m *Monitor) Enabled() bool {
-	return m.provider != nil && m.enabled.Load()
+	return m.currentProvider() != nil && m.enabled.Load()
 }
 
 func (m *Monitor) SetEnabled(enabled bool) error {
-	if m.provider == nil {
+	if m.currentProvider() == nil {
 		m.enabled.Store(false)
 		return ErrUnsupported
 	}
 	previous := m.enabled.Swap(enabled)
+	if !enabled {
+		m.stopStream()
+	}
 	if previous && !enabled {
 		m.closeCurrentEvent()
 	}
@@ -118,12 +257,17 @@ func (m *Monitor) Current() (models.FocusCurrent, bool, error) {
 	}
 	focusMs := event.DurationMs
 	if focusMs == 0 {
-		focusMs = time.Now().UnixMilli() - event.TsMs
+		focusMs = m.clock.Now().UnixMilli() - event.TsMs
 	}
 	if focusMs < 0 {
 		focusMs = 0
 	}
+	m.mu.RLock()
 	title := m.lastWindowTitle
+	if !m.lastTitleStored {
+		title = m.redactor.Redact(event.AppName, event.BundleID, title)
+	}
+	m.mu.RUnlock()
 	if title == "" {
 		title = event.WindowTitle
 	}
@@ -137,15 +281,33 @@ func (m *Monitor) Current() (models.FocusCurrent, bool, error) {
 	}, true, nil
 }
 
-func (m *Monitor) loop() {
+func (m *Monitor) loop(ctx context.Context) {
 	ticker := time.NewTicker(m.interval)
 	defer ticker.Stop()
 
-	for range ticker.C {
-		if !m.Enabled() {
+	streaming := envBool(envFocusStream, true)
+	var streamRetryAt time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		prov := m.currentProvider()
+		if prov == nil || !m.Enabled() {
+			continue
+		}
+		if sp, ok := prov.(streamProvider); ok && streaming && m.clock.Now().After(streamRetryAt) {
+			if !m.superviseStream(sp) {
+				streamRetryAt = m.clock.Now().Add(streamFallbackPeriod)
+				m.logger.Warn("focus stream unavailable, falling back to polling",
+					slog.String("provider", sp.Name()),
+					slog.Duration("retry_in", streamFallbackPeriod))
+			}
 			continue
 		}
-		snapshot, err := m.provider.Current()
+		snapshot, err := prov.Current()
+		m.recordPoll(err)
 		if err != nil {
 			m.logger.Warn("focus poll failed", slog.Any("error", err))
 			continue
@@ -160,10 +322,18 @@ func (m *Monitor) loop() {
 func (m *Monitor) handleSnapshot(snapshot FocusSnapshot) {
 	nowMs := snapshot.TsMs
 	if nowMs == 0 {
-		nowMs = time.Now().UnixMilli()
+		nowMs = m.clock.Now().UnixMilli()
+	}
+	if m.handleIdle(snapshot, nowMs) {
+		return
+	}
+	if m.mergeMicroSwitch(snapshot, nowMs) {
+		return
 	}
 
 	m.mu.Lock()
+	m.rememberRawTitleLocked(snapshot)
+	redactor := m.redactor
 	last := m.last
 	hasLast := m.hasLast
 	prevTitle := m.lastWindowTitle
@@ -173,8 +343,17 @@ func (m *Monitor) handleSnapshot(snapshot FocusSnapshot) {
 		currentTitle = prevTitle
 	}
 	titleChanged := currentTitle != "" && currentTitle != prevTitle
+	// A title carried over from a restored event is already redacted.
+	storedTitle := snapshotTitle == "" && m.lastTitleStored
+	redact := func(title string) string {
+		if storedTitle {
+			return title
+		}
+		return redactor.Redact(snapshot.AppName, snapshot.BundleID, title)
+	}
 	if currentTitle != "" {
 		m.lastWindowTitle = currentTitle
+		m.lastTitleStored = storedTitle
 	}
 	if titleChanged || m.lastTitleChange == 0 {
 		m.lastTitleChange = nowMs
@@ -186,10 +365,18 @@ func (m *Monitor) handleSnapshot(snapshot FocusSnapshot) {
 	var updateTitle string
 	if titleChanged && same && last.ID != 0 {
 		updateTitleID = last.ID
-		updateTitle = currentTitle
+		updateTitle = redact(currentTitle)
 		last.WindowTitle = currentTitle
 		m.last = last
 	}
+	if !hasLast || !same {
+		m.startVisitLocked(Visit{
+			AppName:     snapshot.AppName,
+			BundleID:    snapshot.BundleID,
+			WindowTitle: redact(currentTitle),
+			StartMs:     nowMs,
+		})
+	}
 	if hasLast && !same {
 		m.switches = append(m.switches, nowMs)
 		m.pruneSwitchesLocked(nowMs)
@@ -222,6 +409,7 @@ func (m *Monitor) handleSnapshot(snapshot FocusSnapshot) {
 		if err := m.store.UpdateFocusDuration(last.ID, duration); err != nil {
 			m.logger.Error("update focus duration failed", slog.Any("error", err))
 		}
+		last.DurationMs = duration
 	}
 
 	newEvent := models.FocusEvent{
@@ -229,7 +417,7 @@ func (m *Monitor) handleSnapshot(snapshot FocusSnapshot) {
 		AppName:     snapshot.AppName,
 		BundleID:    snapshot.BundleID,
 		PID:         snapshot.PID,
-		WindowTitle: snapshotTitle,
+		WindowTitle: redactor.Redact(snapshot.AppName, snapshot.BundleID, snapshotTitle),
 		DurationMs:  0,
 	}
 	id, err := m.store.InsertFocusEvent(newEvent)
@@ -240,6 +428,8 @@ func (m *Monitor) handleSnapshot(snapshot FocusSnapshot) {
 	newEvent.ID = id
 
 	m.mu.Lock()
+	m.prev = last
+	m.hasPrev = hasLast
 	m.last = newEvent
 	m.hasLast = true
 	m.mu.Unlock()
@@ -248,22 +438,68 @@ func (m *Monitor) handleSnapshot(snapshot FocusSnapshot) {
 func (m *Monitor) SwitchCount() int {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
-	return len(m.switches)
+	cutoff := m.clock.Now().UnixMilli() - m.switchWindow.Milliseconds()
+	count := 0
+	for _, ts := range m.switches {
+		if ts >= cutoff {
+			count++
+		}
+	}
+	return count
 }
 
 func (m *Monitor) NoProgress() (bool, time.Duration) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
-	if !m.noProgress || m.lastTitleChange == 0 {
+	if m.lastTitleChange == 0 {
 		return false, 0
 	}
-	elapsedMs := time.Now().UnixMilli() - m.lastTitleChange
+	elapsedMs := m.clock.Now().UnixMilli() - m.lastTitleChange
 	if elapsedMs < 0 {
 		elapsedMs = 0
 	}
+	// Streaming providers only report changes, so the hold can elapse
+	// without a snapshot arriving to set noProgress.
+	if !m.noProgress && (!m.hasLast || elapsedMs < m.noProgressHold.Milliseconds()) {
+		return false, 0
+	}
 	return true, time.Duration(elapsedMs) * time.Millisecond
 }
 
+// RecentVisits returns the visits that ended within the switch window, oldest
+// first, including the current one.
+func (m *Monitor) RecentVisits() []Visit {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	cutoff := m.clock.Now().UnixMilli() - m.switchWindow.Milliseconds()
+	visits := make([]Visit, 0, len(m.visits))
+	for _, visit := range m.visits {
+		if visit.EndMs == 0 || visit.EndMs >= cutoff {
+			visits = append(visits, visit)
+		}
+	}
+	return visits
+}
+
+func (m *Monitor) startVisitLocked(visit Visit) {
+	m.endVisitLocked(visit.StartMs)
+	m.visits = append(m.visits, visit)
+	cutoff := visit.StartMs - m.switchWindow.Milliseconds()
+	idx := 0
+	for idx < len(m.visits) && m.visits[idx].EndMs != 0 && m.visits[idx].EndMs < cutoff {
+		idx++
+	}
+	if idx > 0 {
+		m.visits = m.visits[idx:]
+	}
+}
+
+func (m *Monitor) endVisitLocked(endMs int64) {
+	if n := len(m.visits); n > 0 && m.visits[n-1].EndMs == 0 {
+		m.visits[n-1].EndMs = max(endMs, m.visits[n-1].StartMs)
+	}
+}
+
 func (m *Monitor) pruneSwitchesLocked(nowMs int64) {
 	if len(m.switches) == 0 {
 		return
@@ -303,6 +539,7 @@ func (m *Monitor) loadLastEvent() {
 	m.hasLast = true
 	if event.WindowTitle != "" {
 		m.lastWindowTitle = event.WindowTitle
+		m.lastTitleStored = true
 	}
 	m.mu.Unlock()
 }
@@ -311,10 +548,13 @@ func (m *Monitor) clearLast() {
 	m.mu.Lock()
 	m.last = models.FocusEvent{}
 	m.hasLast = false
+	m.hasPrev = false
+	m.endVisitLocked(m.clock.Now().UnixMilli())
 	m.mu.Unlock()
 }
 
 func (m *Monitor) closeCurrentEvent() {
+	m.endIdle(m.clock.Now().UnixMilli())
 	m.mu.RLock()
 	last := m.last
 	hasLast := m.hasLast
@@ -325,7 +565,7 @@ func (m *Monitor) closeCurrentEvent() {
 		return
 	}
 
-	endMs := time.Now().UnixMilli()
+	endMs := m.clock.Now().UnixMilli()
 	duration := endMs - last.TsMs
 	if duration < 0 {
 		duration = 0
@@ -0,0 +1,75 @@
+package focus
+
+import (
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"always/core/internal/clock"
+	"always/core/internal/db"
+	"always/core/internal/models"
+)
+
+var testStart = time.Date(2026, 10, 12, 9, 0, 0, 0, time.Local)
+
+func openTestStore(t *testing.T) *db.Store {
+	t.Helper()
+	store, err := db.Open(filepath.Join(t.TempDir(), "always.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { store.DB().Close() })
+	return store
+}
+
+// newReplayHarness returns a replay monitor on a fresh store and the virtual
+// clock driving it.
+func newReplayHarness(t *testing.T, settings map[string]string) (*Monitor, *db.Store, *clock.Virtual) {
+	t.Helper()
+	store := openTestStore(t)
+	for key, value := range settings {
+		if err := store.UpsertSetting(key, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	clk := clock.NewVirtual(testStart)
+	m := NewReplayMonitor(store, slog.New(slog.NewTextHandler(io.Discard, nil)), clk)
+	return m, store, clk
+}
+
+// observeAt advances the clock to offset from testStart and feeds a snapshot.
+func observeAt(m *Monitor, clk *clock.Virtual, offset time.Duration, snapshot FocusSnapshot) {
+	now := testStart.Add(offset)
+	clk.Set(now)
+	snapshot.TsMs = now.UnixMilli()
+	m.Observe(snapshot)
+}
+
+func focusEvents(t *testing.T, store *db.Store) []models.FocusEvent {
+	t.Helper()
+	events, err := store.ListFocusEventsRange(0, testStart.Add(48*time.Hour).UnixMilli())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return events
+}
+
+func TestMonitorRecordsSwitches(t *testing.T) {
+	m, store, clk := newReplayHarness(t, nil)
+	observeAt(m, clk, 0, FocusSnapshot{AppName: "Code", PID: 1, WindowTitle: "a.go"})
+	observeAt(m, clk, time.Minute, FocusSnapshot{AppName: "Code", PID: 1, WindowTitle: "b.go"})
+	observeAt(m, clk, 5*time.Minute, FocusSnapshot{AppName: "Slack", PID: 2})
+
+	events := focusEvents(t, store)
+	if len(events) != 2 {
+		t.Fatalf("events = %+v, want 2", events)
+	}
+	if events[0].AppName != "Code" || events[0].DurationMs != (5*time.Minute).Milliseconds() {
+		t.Fatalf("first event = %+v", events[0])
+	}
+	if m.SwitchCount() != 1 {
+		t.Fatalf("switches = %d, want 1", m.SwitchCount())
+	}
+}
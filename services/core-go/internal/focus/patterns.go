@@ -0,0 +1,167 @@
+package focus
+
+import (
+	"strings"
+	"time"
+
+	"always/core/internal/models"
+)
+
+// pingPongSettle is how long the user must stay on one app before an
+// oscillation counts as over.
+const pingPongSettle = 2 * time.Minute
+
+// Visit is one uninterrupted stay on an app. EndMs is 0 while it lasts.
+type Visit struct {
+	AppName     string
+	BundleID    string
+	WindowTitle string
+	StartMs     int64
+	EndMs       int64
+}
+
+// PatternThresholds are the cut-offs DetectPatterns applies.
+type PatternThresholds struct {
+	// PingPongSwitches is how many alternations between the same two apps
+	// make a ping-pong.
+	PingPongSwitches int
+	// PeekDuration is the longest visit to a distracting app that still
+	// counts as a peek; PeekCount peeks make the pattern.
+	PeekDuration time.Duration
+	PeekCount    int
+	// ReturnLatency is how long the user may stay away from productive apps,
+	// currently or on average, before returns count as slow.
+	ReturnLatency time.Duration
+}
+
+// DetectPatterns looks for distraction patterns in visits, which must be in
+// order. Oscillating between two productive apps, such as an editor and a
+// terminal, is reported in the counters but is not a ping-pong; neither is
+// time spent idle, which breaks every pattern.
+func DetectPatterns(visits []Visit, c *Categorizer, nowMs int64, t PatternThresholds) models.FocusPatterns {
+	var patterns models.FocusPatterns
+	n := len(visits)
+	if n == 0 {
+		return patterns
+	}
+	categories := make([]string, n)
+	for i, visit := range visits {
+		categories[i] = c.Categorize(models.FocusEvent{
+			AppName:     visit.AppName,
+			BundleID:    visit.BundleID,
+			WindowTitle: visit.WindowTitle,
+		})
+	}
+
+	pingPong := false
+	if nowMs-visits[n-1].StartMs < pingPongSettle.Milliseconds() {
+		run := 1
+		for i := n - 1; i >= 1; i-- {
+			if !contiguous(visits[i-1], visits[i]) || strings.EqualFold(visits[i-1].AppName, visits[i].AppName) {
+				break
+			}
+			if i+1 < n && !strings.EqualFold(visits[i-1].AppName, visits[i+1].AppName) {
+				break
+			}
+			run++
+		}
+		if run >= 3 {
+			patterns.PingPongSwitches = run - 1
+			patterns.PingPongApps = []string{visits[n-2].AppName, visits[n-1].AppName}
+			healthy := categories[n-1] == models.CategoryProductive && categories[n-2] == models.CategoryProductive
+			pingPong = !healthy && patterns.PingPongSwitches >= t.PingPongSwitches
+		}
+	}
+
+	seen := map[string]bool{}
+	for i := 1; i+1 < n; i++ {
+		visit := visits[i]
+		if categories[i] != models.CategoryDistracting || !contiguous(visits[i-1], visit) || !contiguous(visit, visits[i+1]) {
+			continue
+		}
+		if visit.EndMs-visit.StartMs >= t.PeekDuration.Milliseconds() {
+			continue
+		}
+		patterns.PeekCount++
+		if key := strings.ToLower(visit.AppName); !seen[key] {
+			seen[key] = true
+			patterns.PeekApps = append(patterns.PeekApps, visit.AppName)
+		}
+	}
+
+	awayStart := int64(-1)
+	var totalLatency int64
+	for i := 1; i < n; i++ {
+		if !contiguous(visits[i-1], visits[i]) {
+			awayStart = -1
+			continue
+		}
+		wasProductive := categories[i-1] == models.CategoryProductive
+		isProductive := categories[i] == models.CategoryProductive
+		switch {
+		case wasProductive && !isProductive:
+			awayStart = visits[i].StartMs
+		case !wasProductive && isProductive && awayStart >= 0:
+			totalLatency += visits[i].StartMs - awayStart
+			patterns.Returns++
+			awayStart = -1
+		}
+	}
+	if patterns.Returns > 0 {
+		patterns.ReturnLatencyMs = totalLatency / int64(patterns.Returns)
+	}
+	if awayStart >= 0 && visits[n-1].EndMs == 0 {
+		patterns.AwayMs = max(nowMs-awayStart, 0)
+	}
+
+	latency := t.ReturnLatency.Milliseconds()
+	// Regular peeks also alternate between two apps; the narrower name wins.
+	switch {
+	case patterns.PeekCount >= t.PeekCount:
+		patterns.Pattern = models.FocusPatternPeek
+	case pingPong:
+		patterns.Pattern = models.FocusPatternPingPong
+	case patterns.AwayMs >= latency || (patterns.Returns >= 2 && patterns.ReturnLatencyMs >= latency):
+		patterns.Pattern = models.FocusPatternSlowReturn
+	}
+	return patterns
+}
+
+// VisitsFromEvents turns stored local focus events into visits, for when the
+// monitor is not running. The last event stays open if it has no duration.
+func VisitsFromEvents(events []models.FocusEvent, nowMs int64) []Visit {
+	local := make([]models.FocusEvent, 0, len(events))
+	for _, event := range events {
+		if event.Source == "" || event.Source == models.FocusSourceLocal {
+			local = append(local, event)
+		}
+	}
+	var visits []Visit
+	for i, event := range local {
+		end := EventEnd(local, i, nowMs)
+		if i == len(local)-1 && event.DurationMs == 0 {
+			end = 0
+		}
+		if n := len(visits); n > 0 {
+			last := &visits[n-1]
+			if strings.EqualFold(last.AppName, event.AppName) && last.EndMs != 0 && event.TsMs-last.EndMs <= stretchGap.Milliseconds() {
+				last.EndMs = end
+				continue
+			}
+		}
+		visits = append(visits, Visit{
+			AppName:     event.AppName,
+			BundleID:    event.BundleID,
+			WindowTitle: event.WindowTitle,
+			StartMs:     event.TsMs,
+			EndMs:       end,
+		})
+	}
+	return visits
+}
+
+// contiguous reports whether b started right after a ended, with no idle time
+// in between.
+func contiguous(a Visit, b Visit) bool {
+	return a.EndMs != 0 && b.StartMs-a.EndMs <= stretchGap.Milliseconds()
+}
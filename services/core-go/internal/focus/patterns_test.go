@@ -0,0 +1,154 @@
+package focus
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"always/core/internal/models"
+)
+
+var testPatternThresholds = PatternThresholds{
+	PingPongSwitches: 6,
+	PeekDuration:     30 * time.Second,
+	PeekCount:        3,
+	ReturnLatency:    3 * time.Minute,
+}
+
+type stay struct {
+	app string
+	// d of 0 leaves the visit open.
+	d time.Duration
+	// gap is idle time before the visit.
+	gap time.Duration
+}
+
+// visitSeq lays stays end to end from testStart and returns the visits and
+// the end of the last closed one.
+func visitSeq(stays ...stay) ([]Visit, int64) {
+	var visits []Visit
+	at := testStart.UnixMilli()
+	for _, s := range stays {
+		at += s.gap.Milliseconds()
+		visit := Visit{AppName: s.app, StartMs: at}
+		if s.d > 0 {
+			at += s.d.Milliseconds()
+			visit.EndMs = at
+		}
+		visits = append(visits, visit)
+	}
+	return visits, at
+}
+
+func alternate(a, b string, n int, d time.Duration) []stay {
+	stays := make([]stay, 0, n+1)
+	for i := range n {
+		app := a
+		if i%2 == 1 {
+			app = b
+		}
+		stays = append(stays, stay{app: app, d: d})
+	}
+	return stays
+}
+
+func TestDetectPatterns(t *testing.T) {
+	c := NewCategorizer([]models.FocusCategoryRule{
+		{Category: models.CategoryProductive, MatchField: models.CategoryMatchAppName, Pattern: "Code"},
+		{Category: models.CategoryProductive, MatchField: models.CategoryMatchAppName, Pattern: "Terminal"},
+		{Category: models.CategoryDistracting, MatchField: models.CategoryMatchAppName, Pattern: "Slack"},
+	})
+	tests := []struct {
+		name  string
+		stays []stay
+		// since is how long after the last stay started detection runs.
+		since    time.Duration
+		pattern  string
+		switches int
+		peeks    int
+		returns  int
+	}{
+		{
+			name:     "ping pong with chat",
+			stays:    append(alternate("Code", "Slack", 7, 40*time.Second), stay{app: "Slack"}),
+			since:    10 * time.Second,
+			pattern:  models.FocusPatternPingPong,
+			switches: 7,
+			returns:  3,
+		},
+		{
+			name:     "editor and terminal is healthy",
+			stays:    append(alternate("Code", "Terminal", 7, 40*time.Second), stay{app: "Terminal"}),
+			since:    10 * time.Second,
+			switches: 7,
+		},
+		{
+			name:    "ping pong settles",
+			stays:   append(alternate("Slack", "Code", 7, 40*time.Second), stay{app: "Code"}),
+			since:   3 * time.Minute,
+			returns: 3,
+		},
+		{
+			name: "peeks",
+			stays: []stay{
+				{app: "Code", d: 5 * time.Minute}, {app: "Slack", d: 10 * time.Second},
+				{app: "Code", d: 3 * time.Minute}, {app: "Slack", d: 10 * time.Second},
+				{app: "Code", d: 3 * time.Minute}, {app: "Slack", d: 10 * time.Second},
+				{app: "Code"},
+			},
+			since: time.Minute,
+			// Regular peeks also alternate; the narrower pattern wins.
+			pattern:  models.FocusPatternPeek,
+			switches: 6,
+			peeks:    3,
+			returns:  3,
+		},
+		{
+			name:    "slow return",
+			stays:   []stay{{app: "Code", d: 10 * time.Minute}, {app: "Slack"}},
+			since:   5 * time.Minute,
+			pattern: models.FocusPatternSlowReturn,
+		},
+		{
+			name: "idle breaks every pattern",
+			stays: []stay{
+				{app: "Code", d: 5 * time.Minute}, {app: "Slack", d: 10 * time.Second, gap: time.Minute},
+				{app: "Code", d: 3 * time.Minute, gap: time.Minute}, {app: "Slack", d: 10 * time.Second, gap: time.Minute},
+				{app: "Code", d: 3 * time.Minute, gap: time.Minute}, {app: "Slack", gap: time.Minute},
+			},
+			since: 10 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			visits, lastStart := visitSeq(tt.stays...)
+			got := DetectPatterns(visits, c, lastStart+tt.since.Milliseconds(), testPatternThresholds)
+			if got.Pattern != tt.pattern || got.PingPongSwitches != tt.switches || got.PeekCount != tt.peeks || got.Returns != tt.returns {
+				t.Fatalf("patterns = %+v", got)
+			}
+		})
+	}
+	if got := DetectPatterns(nil, c, testStart.UnixMilli(), testPatternThresholds); !reflect.DeepEqual(got, models.FocusPatterns{}) {
+		t.Fatalf("no visits = %+v", got)
+	}
+}
+
+func TestVisitsFromEvents(t *testing.T) {
+	at := func(minute int) int64 { return testStart.Add(time.Duration(minute) * time.Minute).UnixMilli() }
+	events := []models.FocusEvent{
+		{AppName: "Code", TsMs: at(0), DurationMs: (2 * time.Minute).Milliseconds()},
+		{AppName: "code", TsMs: at(2), DurationMs: time.Minute.Milliseconds()},
+		{AppName: "Figma", TsMs: at(3), Source: "laptop", DurationMs: time.Minute.Milliseconds()},
+		{AppName: "Slack", TsMs: at(3)},
+		{AppName: "Code", TsMs: at(5)},
+	}
+	got := VisitsFromEvents(events, at(9))
+	want := []Visit{
+		{AppName: "Code", StartMs: at(0), EndMs: at(3)},
+		{AppName: "Slack", StartMs: at(3), EndMs: at(5)},
+		{AppName: "Code", StartMs: at(5)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("visits = %+v\nwant %+v", got, want)
+	}
+}
@@ -0,0 +1,228 @@
+package focus
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	envProviderCmd       = "FOCUS_PROVIDER_CMD"
+	envProviderTimeoutMs = "FOCUS_PROVIDER_TIMEOUT_MS"
+	envProviderStream    = "FOCUS_PROVIDER_STREAM"
+
+	// streamFlag asks a helper to stay running and print one JSON object per
+	// focus change instead of exiting after a single sample.
+	streamFlag = "--stream"
+
+	defaultProviderTimeout = 2 * time.Second
+	maxAppNameLength       = 256
+	maxWindowTitleLength   = 1024
+	maxClockSkew           = 24 * time.Hour
+
+	// ProviderCommandBuiltin restores the platform provider when stored in the
+	// focus_provider_cmd setting.
+	ProviderCommandBuiltin = "builtin"
+)
+
+var (
+	ErrInvalidProviderOutput = errors.New("invalid focus provider output")
+	errStreamClosed          = errors.New("focus helper closed its output")
+)
+
+// cmdOutput is the JSON contract shared by focusd and external providers.
+type cmdOutput struct {
+	TsMs        int64  `json:"ts_ms"`
+	AppName     string `json:"app_name"`
+	BundleID    string `json:"bundle_id"`
+	PID         int    `json:"pid"`
+	WindowTitle string `json:"window_title"`
+	IdleMs      int64  `json:"idle_ms"`
+}
+
+// execProvider runs an arbitrary executable once per poll and parses its
+// stdout as cmdOutput.
+type execProvider struct {
+	path    string
+	args    []string
+	timeout time.Duration
+}
+
+// streamingExecProvider is an execProvider whose command honours streamFlag.
+type streamingExecProvider struct {
+	*execProvider
+}
+
+func newCommandProvider(command string) (provider, error) {
+	prov, err := newExecProvider(command, providerTimeout())
+	if err != nil {
+		return nil, err
+	}
+	if envBool(envProviderStream, false) {
+		return streamingExecProvider{prov}, nil
+	}
+	return prov, nil
+}
+
+func newExecProvider(command string, timeout time.Duration) (*execProvider, error) {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return nil, errors.New("focus provider command is empty")
+	}
+	path, err := exec.LookPath(fields[0])
+	if err != nil {
+		return nil, fmt.Errorf("focus provider command: %w", err)
+	}
+	if timeout <= 0 {
+		timeout = defaultProviderTimeout
+	}
+	return &execProvider{path: path, args: fields[1:], timeout: timeout}, nil
+}
+
+// ValidateProviderCommand reports whether command can be used as the
+// focus_provider_cmd setting.
+func ValidateProviderCommand(command string) error {
+	if strings.TrimSpace(command) == ProviderCommandBuiltin {
+		return nil
+	}
+	_, err := newExecProvider(command, 0)
+	return err
+}
+
+func (e *execProvider) Name() string {
+	return "command:" + e.path
+}
+
+func (e *execProvider) Current() (FocusSnapshot, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, e.path, e.args...).Output()
+	if ctx.Err() == context.DeadlineExceeded {
+		return FocusSnapshot{}, fmt.Errorf("focus provider timed out after %s", e.timeout)
+	}
+	if err != nil {
+		return FocusSnapshot{}, fmt.Errorf("focus provider failed: %w", err)
+	}
+	return decodeCmdOutput(output, time.Now())
+}
+
+func (s streamingExecProvider) Stream(ctx context.Context, emit func(FocusSnapshot, error)) error {
+	args := append(append([]string{}, s.args...), streamFlag)
+	return streamCommand(ctx, s.path, args, emit)
+}
+
+// streamCommand runs a helper until it exits or ctx is cancelled, decoding
+// each stdout line as cmdOutput. Malformed lines are reported through emit
+// without stopping the stream.
+func streamCommand(ctx context.Context, path string, args []string, emit func(FocusSnapshot, error)) error {
+	cmd := exec.CommandContext(ctx, path, args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("focus helper stdout: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("start focus helper: %w", err)
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		emit(decodeCmdOutput(line, time.Now()))
+	}
+	scanErr := scanner.Err()
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("focus helper exited: %w", err)
+	}
+	if scanErr != nil {
+		return fmt.Errorf("read focus helper: %w", scanErr)
+	}
+	return errStreamClosed
+}
+
+func decodeCmdOutput(output []byte, now time.Time) (FocusSnapshot, error) {
+	trimmed := bytes.TrimSpace(output)
+	if len(trimmed) == 0 {
+		return FocusSnapshot{}, fmt.Errorf("%w: empty output", ErrInvalidProviderOutput)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(trimmed))
+	var parsed cmdOutput
+	if err := decoder.Decode(&parsed); err != nil {
+		return FocusSnapshot{}, fmt.Errorf("%w: %v", ErrInvalidProviderOutput, err)
+	}
+	if decoder.More() {
+		return FocusSnapshot{}, fmt.Errorf("%w: trailing data after JSON object", ErrInvalidProviderOutput)
+	}
+	if err := validateCmdOutput(parsed, now); err != nil {
+		return FocusSnapshot{}, err
+	}
+	return FocusSnapshot{
+		TsMs:        parsed.TsMs,
+		AppName:     strings.TrimSpace(parsed.AppName),
+		BundleID:    strings.TrimSpace(parsed.BundleID),
+		PID:         parsed.PID,
+		WindowTitle: truncateRunes(parsed.WindowTitle, maxWindowTitleLength),
+		IdleMs:      parsed.IdleMs,
+	}, nil
+}
+
+func validateCmdOutput(parsed cmdOutput, now time.Time) error {
+	if parsed.PID < 0 {
+		return fmt.Errorf("%w: negative pid", ErrInvalidProviderOutput)
+	}
+	if parsed.IdleMs < 0 {
+		return fmt.Errorf("%w: negative idle_ms", ErrInvalidProviderOutput)
+	}
+	if len(parsed.AppName) > maxAppNameLength {
+		return fmt.Errorf("%w: app_name too long", ErrInvalidProviderOutput)
+	}
+	if parsed.AppName == "" && (parsed.BundleID != "" || parsed.WindowTitle != "") {
+		return fmt.Errorf("%w: app_name required", ErrInvalidProviderOutput)
+	}
+	if parsed.TsMs != 0 {
+		ts := time.UnixMilli(parsed.TsMs)
+		if ts.Before(now.Add(-maxClockSkew)) || ts.After(now.Add(maxClockSkew)) {
+			return fmt.Errorf("%w: ts_ms out of range", ErrInvalidProviderOutput)
+		}
+	}
+	return nil
+}
+
+func truncateRunes(value string, limit int) string {
+	runes := []rune(value)
+	if len(runes) <= limit {
+		return value
+	}
+	return string(runes[:limit])
+}
+
+func envBool(key string, fallback bool) bool {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv(key))) {
+	case "1", "true", "yes", "on":
+		return true
+	case "0", "false", "no", "off":
+		return false
+	default:
+		return fallback
+	}
+}
+
+func providerTimeout() time.Duration {
+	if raw := os.Getenv(envProviderTimeoutMs); raw != "" {
+		if parsed, err := strconv.Atoi(raw); err == nil && parsed > 0 {
+			return time.Duration(parsed) * time.Millisecond
+		}
+	}
+	return defaultProviderTimeout
+}
@@ -0,0 +1,155 @@
+package focus
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"always/core/internal/clock"
+)
+
+// writeHelper writes an executable shell script standing in for focusd or an
+// external provider.
+func writeHelper(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "helper.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func testMonitor() *Monitor {
+	return &Monitor{logger: slog.New(slog.NewTextHandler(io.Discard, nil)), clock: clock.System}
+}
+
+func TestExecProviderCurrent(t *testing.T) {
+	ok := writeHelper(t, `echo '{"app_name":"Terminal","pid":3}'`)
+	prov, err := newExecProvider(ok+" --sample", time.Second)
+	if err != nil {
+		t.Fatalf("new provider: %v", err)
+	}
+	if len(prov.args) != 1 || prov.args[0] != "--sample" {
+		t.Fatalf("args = %v", prov.args)
+	}
+	snapshot, err := prov.Current()
+	if err != nil || snapshot.AppName != "Terminal" || snapshot.PID != 3 {
+		t.Fatalf("current = %+v, %v", snapshot, err)
+	}
+
+	slow, err := newExecProvider(writeHelper(t, "exec sleep 5"), 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("new provider: %v", err)
+	}
+	if _, err := slow.Current(); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("err = %v, want timeout", err)
+	}
+
+	failing, _ := newExecProvider(writeHelper(t, "exit 3"), time.Second)
+	if _, err := failing.Current(); err == nil {
+		t.Fatal("expected an error from a failing helper")
+	}
+
+	invalid, _ := newExecProvider(writeHelper(t, "echo not-json"), time.Second)
+	if _, err := invalid.Current(); !errors.Is(err, ErrInvalidProviderOutput) {
+		t.Fatalf("err = %v, want ErrInvalidProviderOutput", err)
+	}
+}
+
+func TestValidateProviderCommand(t *testing.T) {
+	if err := ValidateProviderCommand(ProviderCommandBuiltin); err != nil {
+		t.Fatalf("builtin: %v", err)
+	}
+	if err := ValidateProviderCommand("   "); err == nil {
+		t.Fatal("empty command accepted")
+	}
+	if err := ValidateProviderCommand("/definitely/not/here --flag"); err == nil {
+		t.Fatal("missing executable accepted")
+	}
+	if err := ValidateProviderCommand(writeHelper(t, "true")); err != nil {
+		t.Fatalf("executable rejected: %v", err)
+	}
+}
+
+func TestProviderHealth(t *testing.T) {
+	m := testMonitor()
+	if m.ProviderHealth() != nil {
+		t.Fatal("health reported without a provider")
+	}
+	prov, err := newExecProvider(writeHelper(t, "true"), time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.setProvider(prov)
+	m.recordPoll(errors.New("boom"))
+	m.recordPoll(errors.New("boom"))
+	health := m.ProviderHealth()
+	if health.Name != prov.Name() || health.Healthy || health.ConsecutiveFailures != 2 || health.LastError != "boom" {
+		t.Fatalf("health = %+v", health)
+	}
+	m.recordPoll(nil)
+	health = m.ProviderHealth()
+	if !health.Healthy || health.ConsecutiveFailures != 0 || health.LastSuccessMs == 0 {
+		t.Fatalf("health after success = %+v", health)
+	}
+}
+
+func TestDecodeCmdOutput(t *testing.T) {
+	now := time.UnixMilli(1_760_000_000_000)
+	tests := []struct {
+		name   string
+		output string
+		want   FocusSnapshot
+		err    bool
+	}{
+		{
+			name:   "full object",
+			output: `{"ts_ms":1760000000000,"app_name":" Code ","bundle_id":"com.microsoft.VSCode","pid":12,"window_title":"main.go","idle_ms":500}`,
+			want:   FocusSnapshot{TsMs: 1_760_000_000_000, AppName: "Code", BundleID: "com.microsoft.VSCode", PID: 12, WindowTitle: "main.go", IdleMs: 500},
+		},
+		{name: "nothing focused", output: `{}` + "\n", want: FocusSnapshot{}},
+		{name: "extra fields ignored", output: `{"app_name":"Code","extra":1}`, want: FocusSnapshot{AppName: "Code"}},
+		{name: "empty", output: "  \n", err: true},
+		{name: "not json", output: "Code", err: true},
+		{name: "trailing object", output: `{"app_name":"a"} {"app_name":"b"}`, err: true},
+		{name: "negative pid", output: `{"app_name":"a","pid":-1}`, err: true},
+		{name: "negative idle", output: `{"app_name":"a","idle_ms":-1}`, err: true},
+		{name: "title without app", output: `{"window_title":"x"}`, err: true},
+		{name: "app name too long", output: `{"app_name":"` + strings.Repeat("a", maxAppNameLength+1) + `"}`, err: true},
+		{name: "timestamp too old", output: `{"app_name":"a","ts_ms":1}`, err: true},
+		{name: "timestamp in the future", output: `{"app_name":"a","ts_ms":1760200000000}`, err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeCmdOutput([]byte(tt.output), now)
+			if tt.err {
+				if !errors.Is(err, ErrInvalidProviderOutput) {
+					t.Fatalf("err = %v, want ErrInvalidProviderOutput", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("snapshot = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeCmdOutputTruncatesTitle(t *testing.T) {
+	title := strings.Repeat("界", maxWindowTitleLength+10)
+	got, err := decodeCmdOutput([]byte(`{"app_name":"a","window_title":"`+title+`"}`), time.Now())
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if n := len([]rune(got.WindowTitle)); n != maxWindowTitleLength {
+		t.Fatalf("title has %d runes, want %d", n, maxWindowTitleLength)
+	}
+}
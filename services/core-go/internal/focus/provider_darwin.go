@@ -3,12 +3,13 @@
 package focus
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"time"
 )
 
 type cmdProvider struct {
@@ -16,14 +17,6 @@ type cmdProvider struct {
 	logger     *slog.Logger
 }
 
-type cmdOutput struct {
-	TsMs        int64  `json:"ts_ms"`
-	AppName     string `json:"app_name"`
-	BundleID    string `json:"bundle_id"`
-	PID         int    `json:"pid"`
-	WindowTitle string `json:"window_title"`
-}
-
 func newProvider(logger *slog.Logger) (provider, error) {
 	binaryPath, err := ensureFocusBinary(logger)
 	if err != nil {
@@ -32,23 +25,25 @@ func newProvider(logger *slog.Logger) (provider, error) {
 	return &cmdProvider{binaryPath: binaryPath, logger: logger}, nil
 }
 
+func (c *cmdProvider) Name() string {
+	return "focusd"
+}
+
 func (c *cmdProvider) Current() (FocusSnapshot, error) {
 	cmd := exec.Command(c.binaryPath)
 	output, err := cmd.Output()
 	if err != nil {
 		return FocusSnapshot{}, fmt.Errorf("focusd failed: %w", err)
 	}
-	var parsed cmdOutput
-	if err := json.Unmarshal(output, &parsed); err != nil {
+	snapshot, err := decodeCmdOutput(output, time.Now())
+	if err != nil {
 		return FocusSnapshot{}, fmt.Errorf("decode focusd output: %w", err)
 	}
-	return FocusSnapshot{
-		TsMs:        parsed.TsMs,
-		AppName:     parsed.AppName,
-		BundleID:    parsed.BundleID,
-		PID:         parsed.PID,
-		WindowTitle: parsed.WindowTitle,
-	}, nil
+	return snapshot, nil
+}
+
+func (c *cmdProvider) Stream(ctx context.Context, emit func(FocusSnapshot, error)) error {
+	return streamCommand(ctx, c.binaryPath, []string{streamFlag}, emit)
 }
 
 func ensureFocusBinary(logger *slog.Logger) (string, error) {
@@ -58,12 +53,15 @@ func ensureFocusBinary(logger *slog.Logger) (string, error) {
 	}
 	repoRoot := filepath.Clean(filepath.Join(wd, "..", ".."))
 	sourcePath := filepath.Join(repoRoot, "cmd", "focusd", "main.swift")
-	if _, err := os.Stat(sourcePath); err != nil {
+	sourceInfo, err := os.Stat(sourcePath)
+	if err != nil {
 		return "", fmt.Errorf("focusd source missing: %w", err)
 	}
 	binDir := filepath.Join(repoRoot, "services", "core-go", "bin")
 	binaryPath := filepath.Join(binDir, "focusd")
-	if _, err := os.Stat(binaryPath); err == nil {
+	// Rebuild when the source changed so older binaries pick up new modes
+	// such as --stream.
+	if binaryInfo, err := os.Stat(binaryPath); err == nil && !binaryInfo.ModTime().Before(sourceInfo.ModTime()) {
 		return binaryPath, nil
 	}
 	if err := os.MkdirAll(binDir, 0o755); err != nil {
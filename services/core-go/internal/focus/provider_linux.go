@@ -0,0 +1,103 @@
+//go:build linux
+
+package focus
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const envLinuxBackend = "FOCUS_LINUX_BACKEND"
+
+type windowBackend interface {
+	Name() string
+	ActiveWindow() (activeWindow, error)
+}
+
+type activeWindow struct {
+	AppName  string
+	AppID    string
+	PID      int
+	Title    string
+	WindowID string
+}
+
+type linuxProvider struct {
+	backend windowBackend
+	idle    idleSource
+	logger  *slog.Logger
+}
+
+func newProvider(logger *slog.Logger) (provider, error) {
+	backend, err := selectLinuxBackend()
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("focus provider selected", slog.String("backend", backend.Name()))
+	return &linuxProvider{backend: backend, idle: selectIdleSource(backend), logger: logger}, nil
+}
+
+func selectLinuxBackend() (windowBackend, error) {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv(envLinuxBackend))) {
+	case "x11":
+		return newX11Backend()
+	case "sway", "i3":
+		return newSwayBackend()
+	case "":
+	default:
+		return nil, fmt.Errorf("%w: unknown %s %q", ErrUnsupported, envLinuxBackend, os.Getenv(envLinuxBackend))
+	}
+
+	if os.Getenv("WAYLAND_DISPLAY") != "" || swaySocketPath() != "" {
+		if backend, err := newSwayBackend(); err == nil {
+			return backend, nil
+		}
+	}
+	if os.Getenv("DISPLAY") != "" {
+		return newX11Backend()
+	}
+	return nil, ErrUnsupported
+}
+
+func (p *linuxProvider) Name() string {
+	return p.backend.Name()
+}
+
+func (p *linuxProvider) Current() (FocusSnapshot, error) {
+	window, err := p.backend.ActiveWindow()
+	if err != nil {
+		return FocusSnapshot{}, fmt.Errorf("%s active window: %w", p.backend.Name(), err)
+	}
+	appName := window.AppName
+	if appName == "" && window.PID > 0 {
+		appName = processName(window.PID)
+	}
+	snapshot := FocusSnapshot{
+		TsMs:        time.Now().UnixMilli(),
+		AppName:     appName,
+		BundleID:    window.AppID,
+		PID:         window.PID,
+		WindowTitle: window.Title,
+	}
+	if p.idle != nil {
+		// Idle probing is best effort; a failing probe must not hide focus.
+		if idleMs, err := p.idle.IdleMs(); err == nil {
+			snapshot.IdleMs = idleMs
+		} else {
+			p.logger.Debug("idle probe failed", slog.Any("error", err))
+		}
+	}
+	return snapshot, nil
+}
+
+func processName(pid int) string {
+	raw, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/comm")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(raw))
+}
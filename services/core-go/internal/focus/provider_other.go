@@ -1,4 +1,4 @@
-//go:build !darwin
+//go:build !darwin && !linux
 
 package focus
 
@@ -10,6 +10,10 @@ func newProvider(_ *slog.Logger) (provider, error) {
 	return nil, ErrUnsupported
 }
 
+func (unsupportedProvider) Name() string {
+	return "unsupported"
+}
+
 func (unsupportedProvider) Current() (FocusSnapshot, error) {
 	return FocusSnapshot{}, ErrUnsupported
 }
@@ -0,0 +1,201 @@
+package focus
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"always/core/internal/models"
+)
+
+const (
+	RedactDrop   = "drop"
+	RedactHash   = "hash"
+	RedactRegex  = "regex"
+	RedactDomain = "domain"
+
+	settingTitleRedactionRules = "title_redaction_rules"
+	recentRawTitleLimit        = 50
+)
+
+var domainPattern = regexp.MustCompile(`(?i)\b((?:[a-z0-9](?:[a-z0-9-]*[a-z0-9])?\.)+[a-z]{2,})\b`)
+
+// Redactor rewrites window titles before they are persisted or handed to the
+// AI context. Rules run in order; drop and hash end processing.
+type Redactor struct {
+	rules []compiledRedaction
+}
+
+type compiledRedaction struct {
+	rule    models.TitleRedactionRule
+	pattern *regexp.Regexp
+}
+
+// ParseRedactionRules decodes and validates the title_redaction_rules
+// setting.
+func ParseRedactionRules(raw string) ([]models.TitleRedactionRule, error) {
+	var rules []models.TitleRedactionRule
+	if strings.TrimSpace(raw) == "" {
+		return rules, nil
+	}
+	if err := json.Unmarshal([]byte(raw), &rules); err != nil {
+		return nil, fmt.Errorf("decode redaction rules: %w", err)
+	}
+	if _, err := NewRedactor(rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func NewRedactor(rules []models.TitleRedactionRule) (*Redactor, error) {
+	r := &Redactor{}
+	for i, rule := range rules {
+		compiled := compiledRedaction{rule: rule}
+		switch rule.Action {
+		case RedactDrop, RedactHash, RedactDomain:
+		case RedactRegex:
+			if rule.Pattern == "" {
+				return nil, fmt.Errorf("rule %d: pattern required for regex", i)
+			}
+			re, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: invalid pattern: %v", i, err)
+			}
+			compiled.pattern = re
+		default:
+			return nil, fmt.Errorf("rule %d: invalid action %q", i, rule.Action)
+		}
+		r.rules = append(r.rules, compiled)
+	}
+	return r, nil
+}
+
+// Redact returns the title to store for the given app.
+func (r *Redactor) Redact(appName, bundleID, title string) string {
+	if r == nil || title == "" {
+		return title
+	}
+	for _, compiled := range r.rules {
+		if !compiled.appliesTo(appName, bundleID) {
+			continue
+		}
+		switch compiled.rule.Action {
+		case RedactDrop:
+			return ""
+		case RedactHash:
+			return hashTitle(title)
+		case RedactDomain:
+			title = domainOnly(title)
+		case RedactRegex:
+			title = compiled.pattern.ReplaceAllString(title, compiled.rule.Replacement)
+		}
+		if title == "" {
+			return ""
+		}
+	}
+	return title
+}
+
+func (c compiledRedaction) appliesTo(appName, bundleID string) bool {
+	app := strings.TrimSpace(c.rule.App)
+	if app == "" || app == "*" {
+		return true
+	}
+	return strings.EqualFold(app, appName) || (bundleID != "" && strings.EqualFold(app, bundleID))
+}
+
+// hashTitle keeps titles comparable, so no-progress detection still sees
+// changes, without revealing their content.
+func hashTitle(title string) string {
+	sum := sha256.Sum256([]byte(title))
+	return "sha256:" + hex.EncodeToString(sum[:8])
+}
+
+func domainOnly(title string) string {
+	for _, field := range strings.Fields(title) {
+		if parsed, err := url.Parse(field); err == nil && parsed.Host != "" {
+			return strings.ToLower(parsed.Hostname())
+		}
+	}
+	if match := domainPattern.FindString(title); match != "" {
+		return strings.ToLower(match)
+	}
+	return ""
+}
+
+// SetRedactionRules replaces the rules used for new titles.
+func (m *Monitor) SetRedactionRules(rules []models.TitleRedactionRule) error {
+	redactor, err := NewRedactor(rules)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	m.redactor = redactor
+	m.mu.Unlock()
+	return nil
+}
+
+// RedactTitle applies the active rules to a title from any source.
+func (m *Monitor) RedactTitle(appName, bundleID, title string) string {
+	m.mu.RLock()
+	redactor := m.redactor
+	m.mu.RUnlock()
+	return redactor.Redact(appName, bundleID, title)
+}
+
+// RecentRawTitles returns the unredacted titles seen since startup, newest
+// first. They are kept in memory only so rules can be previewed.
+func (m *Monitor) RecentRawTitles() []models.FocusEvent {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]models.FocusEvent, 0, len(m.rawTitles))
+	for i := len(m.rawTitles) - 1; i >= 0; i-- {
+		out = append(out, m.rawTitles[i])
+	}
+	return out
+}
+
+func (m *Monitor) rememberRawTitleLocked(snapshot FocusSnapshot) {
+	if snapshot.WindowTitle == "" {
+		return
+	}
+	if n := len(m.rawTitles); n > 0 {
+		last := m.rawTitles[n-1]
+		if last.AppName == snapshot.AppName && last.WindowTitle == snapshot.WindowTitle {
+			return
+		}
+	}
+	m.rawTitles = append(m.rawTitles, models.FocusEvent{
+		TsMs:        snapshot.TsMs,
+		AppName:     snapshot.AppName,
+		BundleID:    snapshot.BundleID,
+		WindowTitle: snapshot.WindowTitle,
+	})
+	if len(m.rawTitles) > recentRawTitleLimit {
+		m.rawTitles = m.rawTitles[len(m.rawTitles)-recentRawTitleLimit:]
+	}
+}
+
+func (m *Monitor) loadRedactionRules() {
+	value, ok, err := m.store.GetSetting(settingTitleRedactionRules)
+	if err != nil {
+		m.logger.Error("load redaction rules failed", slog.Any("error", err))
+		return
+	}
+	if !ok {
+		return
+	}
+	rules, err := ParseRedactionRules(value)
+	if err != nil {
+		m.logger.Error("invalid redaction rules", slog.Any("error", err))
+		return
+	}
+	if err := m.SetRedactionRules(rules); err != nil {
+		m.logger.Error("apply redaction rules failed", slog.Any("error", err))
+	}
+}
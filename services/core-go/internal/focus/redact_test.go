@@ -0,0 +1,126 @@
+package focus
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"always/core/internal/models"
+)
+
+func TestRedact(t *testing.T) {
+	rules := []models.TitleRedactionRule{
+		{App: "1Password", Action: RedactDrop},
+		{App: "com.apple.mail", Action: RedactHash},
+		{App: "Google Chrome", Action: RedactDomain},
+		{App: "*", Action: RedactRegex, Pattern: `[\w.+-]+@[\w-]+\.[\w.]+`, Replacement: "<email>"},
+	}
+	r, err := NewRedactor(rules)
+	if err != nil {
+		t.Fatalf("new redactor: %v", err)
+	}
+	tests := []struct {
+		name, app, bundle, title, want string
+	}{
+		{name: "drop", app: "1password", title: "Bank login", want: ""},
+		{name: "hash by bundle id", app: "Mail", bundle: "com.apple.mail", title: "Offer letter", want: hashTitle("Offer letter")},
+		{name: "domain from url", app: "Google Chrome", title: "https://Docs.Example.com/private/doc - Google Chrome", want: "docs.example.com"},
+		{name: "domain from text", app: "Google Chrome", title: "Inbox (3) - mail.example.org", want: "mail.example.org"},
+		{name: "no domain drops", app: "Google Chrome", title: "New Tab", want: ""},
+		{name: "regex for every app", app: "Slack", title: "DM with bob@example.com", want: "DM with <email>"},
+		{name: "empty title", app: "Slack", title: "", want: ""},
+	}
+	for _, tt := range tests {
+		if got := r.Redact(tt.app, tt.bundle, tt.title); got != tt.want {
+			t.Errorf("%s: Redact = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	if h := hashTitle("a"); h == hashTitle("b") || !strings.HasPrefix(h, "sha256:") {
+		t.Errorf("hashTitle does not distinguish titles: %s", h)
+	}
+	var none *Redactor
+	if got := none.Redact("Slack", "", "title"); got != "title" {
+		t.Errorf("nil redactor changed the title: %q", got)
+	}
+}
+
+func TestParseRedactionRules(t *testing.T) {
+	tests := []struct {
+		raw string
+		ok  bool
+		n   int
+	}{
+		{raw: "", ok: true},
+		{raw: `[{"app":"Slack","action":"drop"}]`, ok: true, n: 1},
+		{raw: `[{"action":"regex","pattern":"\\d+","replacement":"#"}]`, ok: true, n: 1},
+		{raw: `[{"action":"regex"}]`},
+		{raw: `[{"action":"regex","pattern":"("}]`},
+		{raw: `[{"action":"shred"}]`},
+		{raw: `{"action":"drop"}`},
+	}
+	for _, tt := range tests {
+		rules, err := ParseRedactionRules(tt.raw)
+		if (err == nil) != tt.ok || len(rules) != tt.n {
+			t.Errorf("ParseRedactionRules(%s) = %d rules, %v; want ok=%v, %d rules", tt.raw, len(rules), err, tt.ok, tt.n)
+		}
+	}
+}
+
+func TestMonitorStoresRedactedTitles(t *testing.T) {
+	m, store, clk := newReplayHarness(t, map[string]string{
+		settingTitleRedactionRules: `[{"app":"Slack","action":"drop"},{"action":"regex","pattern":"secret-\\w+","replacement":"***"}]`,
+	})
+	observeAt(m, clk, 0, FocusSnapshot{AppName: "Slack", PID: 1, WindowTitle: "DM with boss"})
+	observeAt(m, clk, time.Minute, FocusSnapshot{AppName: "Code", PID: 2, WindowTitle: "secret-plan.md"})
+	observeAt(m, clk, 2*time.Minute, FocusSnapshot{AppName: "Code", PID: 2, WindowTitle: "secret-budget.md"})
+
+	events := focusEvents(t, store)
+	if len(events) != 2 || events[0].WindowTitle != "" || events[1].WindowTitle != "***.md" {
+		t.Fatalf("stored titles = %+v", events)
+	}
+	raw := m.RecentRawTitles()
+	if len(raw) != 3 || raw[0].WindowTitle != "secret-budget.md" {
+		t.Fatalf("raw titles = %+v, want the unredacted titles newest first", raw)
+	}
+}
+
+func TestRestoredTitleIsNotRedactedTwice(t *testing.T) {
+	for _, rule := range []string{
+		`[{"action":"hash"}]`,
+		`[{"action":"regex","pattern":"secret-\\w+","replacement":"secret-***"}]`,
+	} {
+		m, store, clk := newReplayHarness(t, map[string]string{settingTitleRedactionRules: rule})
+		observeAt(m, clk, 0, FocusSnapshot{AppName: "Code", PID: 1, WindowTitle: "secret-plan.md"})
+		live, ok, err := m.Current()
+		if err != nil || !ok {
+			t.Fatalf("%s: current = %v, %v", rule, ok, err)
+		}
+
+		// A restart restores the stored, already redacted title.
+		restarted := NewReplayMonitor(store, m.logger, clk)
+		restored, ok, err := restarted.Current()
+		if err != nil || !ok {
+			t.Fatalf("%s: current after restart = %v, %v", rule, ok, err)
+		}
+		if restored.WindowTitle != live.WindowTitle {
+			t.Fatalf("%s: title after restart = %q, want %q", rule, restored.WindowTitle, live.WindowTitle)
+		}
+
+		// A snapshot without a title keeps the restored one as it is.
+		observeAt(restarted, clk, time.Minute, FocusSnapshot{AppName: "Code", PID: 1})
+		if current, _, _ := restarted.Current(); current.WindowTitle != live.WindowTitle {
+			t.Fatalf("%s: title after an untitled snapshot = %q, want %q", rule, current.WindowTitle, live.WindowTitle)
+		}
+		events := focusEvents(t, store)
+		if len(events) != 1 || events[0].WindowTitle != live.WindowTitle {
+			t.Fatalf("%s: stored titles = %+v", rule, events)
+		}
+
+		// A live title is redacted again as usual.
+		observeAt(restarted, clk, 2*time.Minute, FocusSnapshot{AppName: "Code", PID: 1, WindowTitle: "secret-budget.md"})
+		want := restarted.redactor.Redact("Code", "", "secret-budget.md")
+		if current, _, _ := restarted.Current(); current.WindowTitle != want {
+			t.Fatalf("%s: live title after restart = %q, want %q", rule, current.WindowTitle, want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package focus
+
+import (
+	"log/slog"
+
+	"always/core/internal/clock"
+	"always/core/internal/db"
+)
+
+// replayProvider marks a monitor fed through Observe; it is never polled.
+type replayProvider struct{}
+
+func (replayProvider) Name() string {
+	return "replay"
+}
+
+func (replayProvider) Current() (FocusSnapshot, error) {
+	return FocusSnapshot{}, ErrUnsupported
+}
+
+// NewReplayMonitor returns an enabled monitor that reads time from clk and
+// only sees the snapshots passed to Observe. It loads the same settings as
+// Start but runs no polling loop, so a simulator can drive it step by step.
+func NewReplayMonitor(store *db.Store, logger *slog.Logger, clk clock.Clock) *Monitor {
+	m := &Monitor{
+		store:          store,
+		logger:         logger,
+		interval:       defaultPollInterval,
+		clock:          clk,
+		switchWindow:   defaultSwitchWindow,
+		noProgressHold: defaultNoProgressHold,
+		idleThreshold:  defaultIdleThreshold,
+		microSwitch:    defaultMicroSwitch,
+	}
+	m.setProvider(replayProvider{})
+	m.loadRedactionRules()
+	m.loadIdleThreshold()
+	m.loadThresholds()
+	m.enabled.Store(true)
+	m.loadLastEvent()
+	return m
+}
+
+// Observe records a snapshot as if a provider had reported it.
+func (m *Monitor) Observe(snapshot FocusSnapshot) {
+	if snapshot.TsMs == 0 {
+		snapshot.TsMs = m.clock.Now().UnixMilli()
+	}
+	m.recordPoll(nil)
+	if snapshot.AppName == "" {
+		return
+	}
+	m.handleSnapshot(snapshot)
+}
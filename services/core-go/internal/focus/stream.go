@@ -0,0 +1,104 @@
+package focus
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+const (
+	envFocusStream = "FOCUS_STREAM"
+
+	streamHealthyRun     = time.Minute
+	streamMaxFailures    = 5
+	streamFallbackPeriod = 10 * time.Minute
+)
+
+// Restart backoff bounds; variables so tests can shorten them.
+var (
+	streamBackoffMin = time.Second
+	streamBackoffMax = time.Minute
+)
+
+// streamProvider is implemented by providers whose helper can stay running
+// and push focus changes. The Monitor still polls Current when the stream
+// keeps failing.
+type streamProvider interface {
+	provider
+	Stream(ctx context.Context, emit func(FocusSnapshot, error)) error
+}
+
+// superviseStream keeps the helper running, restarting it with exponential
+// backoff. It returns true when stopped on purpose (provider swapped or
+// monitoring disabled) and false when the helper failed too often in a row and
+// the caller should fall back to polling.
+func (m *Monitor) superviseStream(sp streamProvider) bool {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.mu.Lock()
+	m.streamCancel = cancel
+	m.mu.Unlock()
+	defer func() {
+		cancel()
+		m.mu.Lock()
+		m.streamCancel = nil
+		m.mu.Unlock()
+	}()
+
+	m.logger.Info("focus stream starting", slog.String("provider", sp.Name()))
+	failures := 0
+	backoff := streamBackoffMin
+	for {
+		started := m.clock.Now()
+		err := sp.Stream(ctx, m.handleStreamed)
+		if ctx.Err() != nil {
+			return true
+		}
+		if m.clock.Now().Sub(started) >= streamHealthyRun {
+			failures = 0
+			backoff = streamBackoffMin
+		}
+		failures++
+		m.recordPoll(err)
+		m.logger.Warn("focus stream exited",
+			slog.String("provider", sp.Name()),
+			slog.Int("failures", failures),
+			slog.Duration("backoff", backoff),
+			slog.Any("error", err))
+		if failures >= streamMaxFailures {
+			return false
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return true
+		case <-timer.C:
+		}
+		backoff *= 2
+		if backoff > streamBackoffMax {
+			backoff = streamBackoffMax
+		}
+	}
+}
+
+func (m *Monitor) handleStreamed(snapshot FocusSnapshot, err error) {
+	m.recordPoll(err)
+	if err != nil {
+		m.logger.Warn("focus stream line rejected", slog.Any("error", err))
+		return
+	}
+	if snapshot.AppName == "" || !m.Enabled() {
+		return
+	}
+	m.handleSnapshot(snapshot)
+}
+
+func (m *Monitor) stopStream() {
+	m.mu.RLock()
+	cancel := m.streamCancel
+	m.mu.RUnlock()
+	if cancel != nil {
+		cancel()
+	}
+}
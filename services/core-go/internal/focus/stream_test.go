@@ -0,0 +1,110 @@
+package focus
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func shortBackoff(t *testing.T) {
+	t.Helper()
+	minBackoff, maxBackoff := streamBackoffMin, streamBackoffMax
+	streamBackoffMin, streamBackoffMax = time.Millisecond, 4*time.Millisecond
+	t.Cleanup(func() { streamBackoffMin, streamBackoffMax = minBackoff, maxBackoff })
+}
+
+func TestStreamCommandDecodesLines(t *testing.T) {
+	helper := writeHelper(t, `echo '{"app_name":"Code","pid":1}'; echo 'garbage'; echo; echo '{"app_name":"Slack","pid":2}'`)
+	var apps []string
+	var errs int
+	err := streamCommand(context.Background(), helper, nil, func(snapshot FocusSnapshot, err error) {
+		if err != nil {
+			errs++
+			return
+		}
+		apps = append(apps, snapshot.AppName)
+	})
+	if err != errStreamClosed {
+		t.Fatalf("err = %v, want errStreamClosed", err)
+	}
+	if len(apps) != 2 || apps[0] != "Code" || apps[1] != "Slack" || errs != 1 {
+		t.Fatalf("apps = %v, errors = %d", apps, errs)
+	}
+}
+
+func TestStreamingProviderPassesStreamFlag(t *testing.T) {
+	helper := writeHelper(t, `[ "$2" = --stream ] && echo "{\"app_name\":\"$1\"}"`)
+	prov, err := newExecProvider(helper+" flagged", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got string
+	streamingExecProvider{prov}.Stream(context.Background(), func(snapshot FocusSnapshot, err error) {
+		if err == nil {
+			got = snapshot.AppName
+		}
+	})
+	if got != "flagged" {
+		t.Fatalf("app = %q, want the helper to see --stream", got)
+	}
+}
+
+func TestSuperviseStreamRestartsThenFallsBack(t *testing.T) {
+	shortBackoff(t)
+	counter := filepath.Join(t.TempDir(), "runs")
+	helper := writeHelper(t, `echo run >> `+counter+`; exit 1`)
+	prov, err := newExecProvider(helper, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := testMonitor()
+	m.setProvider(prov)
+
+	if stopped := m.superviseStream(streamingExecProvider{prov}); stopped {
+		t.Fatal("superviseStream reported a deliberate stop, want fallback")
+	}
+	raw, _ := os.ReadFile(counter)
+	if runs := len(raw) / len("run\n"); runs != streamMaxFailures {
+		t.Fatalf("helper ran %d times, want %d", runs, streamMaxFailures)
+	}
+	health := m.ProviderHealth()
+	if health.Healthy || health.ConsecutiveFailures != streamMaxFailures {
+		t.Fatalf("health = %+v", health)
+	}
+}
+
+type blockingStream struct {
+	mu      sync.Mutex
+	started chan struct{}
+}
+
+func (b *blockingStream) Name() string                    { return "blocking" }
+func (b *blockingStream) Current() (FocusSnapshot, error) { return FocusSnapshot{}, nil }
+func (b *blockingStream) Stream(ctx context.Context, emit func(FocusSnapshot, error)) error {
+	b.mu.Lock()
+	close(b.started)
+	b.mu.Unlock()
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestSuperviseStreamStopsOnCancel(t *testing.T) {
+	m := testMonitor()
+	sp := &blockingStream{started: make(chan struct{})}
+	m.setProvider(sp)
+	done := make(chan bool)
+	go func() { done <- m.superviseStream(sp) }()
+	<-sp.started
+	m.stopStream()
+	select {
+	case stopped := <-done:
+		if !stopped {
+			t.Fatal("a cancelled stream should report a deliberate stop")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("superviseStream did not return after stopStream")
+	}
+}
@@ -0,0 +1,113 @@
+//go:build linux
+
+package focus
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"path/filepath"
+	"testing"
+)
+
+// serveSwayIPC answers every GET_TREE request on a unix socket with tree.
+func serveSwayIPC(t *testing.T, tree string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "sway-ipc.sock")
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				header := make([]byte, len(swayIPCMagic)+8)
+				if _, err := io.ReadFull(conn, header); err != nil {
+					return
+				}
+				if string(header[:len(swayIPCMagic)]) != swayIPCMagic ||
+					binary.LittleEndian.Uint32(header[len(swayIPCMagic)+4:]) != swayGetTree {
+					return
+				}
+				reply := make([]byte, len(swayIPCMagic)+8)
+				copy(reply, swayIPCMagic)
+				binary.LittleEndian.PutUint32(reply[len(swayIPCMagic):], uint32(len(tree)))
+				binary.LittleEndian.PutUint32(reply[len(swayIPCMagic)+4:], swayGetTree)
+				conn.Write(append(reply, tree...))
+			}()
+		}
+	}()
+	return path
+}
+
+func TestSwayActiveWindow(t *testing.T) {
+	tests := []struct {
+		name string
+		tree string
+		want activeWindow
+		err  error
+	}{
+		{
+			name: "wayland client",
+			tree: `{"type":"root","nodes":[{"type":"output","nodes":[{"type":"workspace","nodes":[
+				{"type":"con","name":"other","app_id":"foot","pid":10},
+				{"type":"con","name":"main.go - Code","app_id":"code","pid":42,"focused":true}]}]}]}`,
+			want: activeWindow{AppName: "code", AppID: "code", PID: 42, Title: "main.go - Code"},
+		},
+		{
+			name: "floating xwayland client",
+			tree: `{"type":"root","nodes":[{"type":"workspace","floating_nodes":[
+				{"type":"floating_con","focused":true,"pid":7,"window_properties":{"class":"Firefox","instance":"Navigator","title":"Docs"}}]}]}`,
+			want: activeWindow{AppName: "Firefox", AppID: "Navigator", PID: 7, Title: "Docs"},
+		},
+		{
+			name: "focused workspace only",
+			tree: `{"type":"root","nodes":[{"type":"workspace","focused":true,"nodes":[]}]}`,
+			err:  errNoActiveWindow,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := &swayBackend{socketPath: serveSwayIPC(t, tt.tree)}
+			got, err := backend.ActiveWindow()
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if got != tt.want {
+				t.Fatalf("window = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSwayActiveWindowNoSocket(t *testing.T) {
+	backend := &swayBackend{socketPath: filepath.Join(t.TempDir(), "missing.sock")}
+	if _, err := backend.ActiveWindow(); err == nil {
+		t.Fatal("expected an error without a socket")
+	}
+}
+
+func TestSelectLinuxBackendSway(t *testing.T) {
+	path := serveSwayIPC(t, `{}`)
+	t.Setenv(envLinuxBackend, "sway")
+	t.Setenv("SWAYSOCK", path)
+	backend, err := selectLinuxBackend()
+	if err != nil {
+		t.Fatalf("select: %v", err)
+	}
+	if backend.Name() != "sway" {
+		t.Fatalf("backend = %s, want sway", backend.Name())
+	}
+
+	t.Setenv(envLinuxBackend, "wayland-magic")
+	if _, err := selectLinuxBackend(); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("err = %v, want ErrUnsupported", err)
+	}
+}
@@ -0,0 +1,63 @@
+package focus
+
+import (
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	settingSwitchWindowMinutes   = "focus_switch_window_minutes"
+	settingNoProgressHoldMinutes = "focus_no_progress_hold_minutes"
+)
+
+// SetSwitchWindow changes how far back SwitchCount looks.
+func (m *Monitor) SetSwitchWindow(window time.Duration) {
+	if window <= 0 {
+		window = defaultSwitchWindow
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.switchWindow = window
+	m.pruneSwitchesLocked(m.clock.Now().UnixMilli())
+}
+
+// SetNoProgressHold changes how long a window title may stay unchanged before
+// NoProgress reports true. The latched flag is re-evaluated against the new
+// hold on the next snapshot or NoProgress call.
+func (m *Monitor) SetNoProgressHold(hold time.Duration) {
+	if hold <= 0 {
+		hold = defaultNoProgressHold
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.noProgressHold = hold
+	m.noProgress = false
+}
+
+func (m *Monitor) loadThresholds() {
+	if minutes, ok := m.loadMinutesSetting(settingSwitchWindowMinutes); ok {
+		m.SetSwitchWindow(time.Duration(minutes) * time.Minute)
+	}
+	if minutes, ok := m.loadMinutesSetting(settingNoProgressHoldMinutes); ok {
+		m.SetNoProgressHold(time.Duration(minutes) * time.Minute)
+	}
+	m.loadMicroSwitchThreshold()
+}
+
+func (m *Monitor) loadMinutesSetting(key string) (int, bool) {
+	value, ok, err := m.store.GetSetting(key)
+	if err != nil {
+		m.logger.Error("load focus threshold failed", slog.String("key", key), slog.Any("error", err))
+		return 0, false
+	}
+	if !ok {
+		return 0, false
+	}
+	minutes, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || minutes <= 0 {
+		return 0, false
+	}
+	return minutes, true
+}
@@ -0,0 +1,71 @@
+package focus
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSwitchWindow(t *testing.T) {
+	m, _, clk := newReplayHarness(t, map[string]string{settingSwitchWindowMinutes: "2"})
+	observeAt(m, clk, 0, FocusSnapshot{AppName: "Code", PID: 1})
+	observeAt(m, clk, time.Minute, FocusSnapshot{AppName: "Slack", PID: 2})
+	observeAt(m, clk, 2*time.Minute, FocusSnapshot{AppName: "Code", PID: 1})
+	observeAt(m, clk, 3*time.Minute, FocusSnapshot{AppName: "Slack", PID: 2})
+	if got := m.SwitchCount(); got != 3 {
+		t.Fatalf("switches = %d, want 3", got)
+	}
+	clk.Set(testStart.Add(4*time.Minute + 30*time.Second))
+	if got := m.SwitchCount(); got != 1 {
+		t.Fatalf("switches after 4m30s = %d, want 1", got)
+	}
+	// Nothing was pruned yet, so a wider window counts the older switches again.
+	m.SetSwitchWindow(10 * time.Minute)
+	if got := m.SwitchCount(); got != 3 {
+		t.Fatalf("switches after widening = %d, want 3", got)
+	}
+	observeAt(m, clk, 5*time.Minute, FocusSnapshot{AppName: "Code", PID: 1})
+	if got := m.SwitchCount(); got != 4 {
+		t.Fatalf("switches = %d, want 4", got)
+	}
+	m.SetSwitchWindow(0)
+	if m.switchWindow != defaultSwitchWindow {
+		t.Fatalf("window = %s, want default", m.switchWindow)
+	}
+}
+
+func TestNoProgressHold(t *testing.T) {
+	m, _, clk := newReplayHarness(t, map[string]string{settingNoProgressHoldMinutes: "5"})
+	snapshot := FocusSnapshot{AppName: "Code", PID: 1, WindowTitle: "main.go"}
+	observeAt(m, clk, 0, snapshot)
+	observeAt(m, clk, 4*time.Minute, snapshot)
+	if stuck, _ := m.NoProgress(); stuck {
+		t.Fatal("no progress before the hold elapsed")
+	}
+	observeAt(m, clk, 6*time.Minute, snapshot)
+	if stuck, held := m.NoProgress(); !stuck || held != 6*time.Minute {
+		t.Fatalf("no progress = %v %s, want true 6m", stuck, held)
+	}
+
+	m.SetNoProgressHold(10 * time.Minute)
+	if stuck, _ := m.NoProgress(); stuck {
+		t.Fatal("a longer hold should clear the latched flag")
+	}
+	clk.Set(testStart.Add(11 * time.Minute))
+	if stuck, _ := m.NoProgress(); !stuck {
+		t.Fatal("no progress after the new hold elapsed without a snapshot")
+	}
+	observeAt(m, clk, 12*time.Minute, FocusSnapshot{AppName: "Code", PID: 1, WindowTitle: "util.go"})
+	if stuck, _ := m.NoProgress(); stuck {
+		t.Fatal("a title change should reset no progress")
+	}
+}
+
+func TestLoadThresholdsIgnoresInvalidSettings(t *testing.T) {
+	m, _, _ := newReplayHarness(t, map[string]string{
+		settingSwitchWindowMinutes:   "-3",
+		settingNoProgressHoldMinutes: "soon",
+	})
+	if m.switchWindow != defaultSwitchWindow || m.noProgressHold != defaultNoProgressHold {
+		t.Fatalf("window = %s hold = %s, want defaults", m.switchWindow, m.noProgressHold)
+	}
+}
@@ -0,0 +1,215 @@
+package focus
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"always/core/internal/models"
+)
+
+// stretchGap is the largest gap between two events of the same app that still
+// counts as one uninterrupted stretch.
+const stretchGap = time.Second
+
+type usageSpan struct {
+	app      string
+	category string
+	start    int64
+	end      int64
+}
+
+type usageTotals struct {
+	totalMs    int64
+	apps       map[string]int64
+	categories map[string]int64
+	switches   int
+}
+
+func newUsageTotals() *usageTotals {
+	return &usageTotals{apps: map[string]int64{}, categories: map[string]int64{}}
+}
+
+func (u *usageTotals) add(span usageSpan, ms int64) {
+	u.totalMs += ms
+	u.apps[span.app] += ms
+	u.categories[span.category] += ms
+}
+
+func (u *usageTotals) usage() models.FocusUsage {
+	usage := models.FocusUsage{
+		TotalMinutes:    msToMinutes(u.totalMs),
+		AppMinutes:      make(map[string]float64, len(u.apps)),
+		CategoryMinutes: make(map[string]float64, len(u.categories)),
+		Switches:        u.switches,
+	}
+	for app, ms := range u.apps {
+		usage.AppMinutes[app] = msToMinutes(ms)
+	}
+	for category, ms := range u.categories {
+		usage.CategoryMinutes[category] = msToMinutes(ms)
+	}
+	return usage
+}
+
+// usageSpans clips events to [sinceMs, untilMs) with the same end rules as
+// Store.FocusMetrics and returns the start times of app switches in range.
+// Events must be ascending, as returned by Store.ListFocusEventsRange.
+func usageSpans(events []models.FocusEvent, c *Categorizer, sinceMs int64, untilMs int64) ([]usageSpan, []int64) {
+	var spans []usageSpan
+	var switches []int64
+	for i, event := range events {
+		if i > 0 && event.TsMs >= sinceMs && event.TsMs < untilMs && !strings.EqualFold(events[i-1].AppName, event.AppName) {
+			switches = append(switches, event.TsMs)
+		}
+		start, end := clipSpan(event.TsMs, EventEnd(events, i, untilMs), sinceMs, untilMs)
+		if end <= start {
+			continue
+		}
+		spans = append(spans, usageSpan{
+			app:      event.AppName,
+			category: c.Categorize(event),
+			start:    start,
+			end:      end,
+		})
+	}
+	return spans, switches
+}
+
+// forEachHour splits a span at local hour boundaries.
+func forEachHour(span usageSpan, loc *time.Location, fn func(t time.Time, ms int64)) {
+	for start := span.start; start < span.end; {
+		t := time.UnixMilli(start).In(loc)
+		hourStart := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, loc)
+		end := min(hourStart.Add(time.Hour).UnixMilli(), span.end)
+		if end <= start {
+			end = span.end
+		}
+		fn(t, end-start)
+		start = end
+	}
+}
+
+// BuildTimeline aggregates per-day app and category minutes and the longest
+// uninterrupted stretches on one app.
+func BuildTimeline(events []models.FocusEvent, c *Categorizer, sinceMs int64, untilMs int64, loc *time.Location, stretchLimit int) models.FocusTimeline {
+	spans, switches := usageSpans(events, c, sinceMs, untilMs)
+	days := map[string]*usageTotals{}
+	day := func(t time.Time) *usageTotals {
+		key := t.Format("2006-01-02")
+		if days[key] == nil {
+			days[key] = newUsageTotals()
+		}
+		return days[key]
+	}
+	for _, span := range spans {
+		forEachHour(span, loc, func(t time.Time, ms int64) {
+			day(t).add(span, ms)
+		})
+	}
+	for _, ts := range switches {
+		day(time.UnixMilli(ts).In(loc)).switches++
+	}
+
+	timeline := models.FocusTimeline{
+		SinceMs:          sinceMs,
+		UntilMs:          untilMs,
+		Days:             make([]models.FocusDayUsage, 0, len(days)),
+		LongestStretches: longestStretches(spans, stretchLimit),
+	}
+	for date, totals := range days {
+		timeline.Days = append(timeline.Days, models.FocusDayUsage{Date: date, FocusUsage: totals.usage()})
+	}
+	sort.Slice(timeline.Days, func(i, j int) bool { return timeline.Days[i].Date < timeline.Days[j].Date })
+	return timeline
+}
+
+// BuildHeatmap aggregates usage and switches by hour of day, plus one cell per
+// local date and hour that saw any activity.
+func BuildHeatmap(events []models.FocusEvent, c *Categorizer, sinceMs int64, untilMs int64, loc *time.Location) models.FocusHeatmap {
+	spans, switches := usageSpans(events, c, sinceMs, untilMs)
+	hours := make([]*usageTotals, 24)
+	for i := range hours {
+		hours[i] = newUsageTotals()
+	}
+	type cellKey struct {
+		date string
+		hour int
+	}
+	cells := map[cellKey]*models.FocusHeatmapCell{}
+	cell := func(t time.Time) *models.FocusHeatmapCell {
+		key := cellKey{date: t.Format("2006-01-02"), hour: t.Hour()}
+		if cells[key] == nil {
+			cells[key] = &models.FocusHeatmapCell{Date: key.date, Hour: key.hour}
+		}
+		return cells[key]
+	}
+	cellMs := map[*models.FocusHeatmapCell]int64{}
+	for _, span := range spans {
+		forEachHour(span, loc, func(t time.Time, ms int64) {
+			hours[t.Hour()].add(span, ms)
+			cellMs[cell(t)] += ms
+		})
+	}
+	for _, ts := range switches {
+		t := time.UnixMilli(ts).In(loc)
+		hours[t.Hour()].switches++
+		cell(t).Switches++
+	}
+
+	heatmap := models.FocusHeatmap{
+		SinceMs: sinceMs,
+		UntilMs: untilMs,
+		Hours:   make([]models.FocusHourUsage, 0, len(hours)),
+		Cells:   make([]models.FocusHeatmapCell, 0, len(cells)),
+	}
+	for hour, totals := range hours {
+		heatmap.Hours = append(heatmap.Hours, models.FocusHourUsage{Hour: hour, FocusUsage: totals.usage()})
+	}
+	for _, c := range cells {
+		c.Minutes = msToMinutes(cellMs[c])
+		heatmap.Cells = append(heatmap.Cells, *c)
+	}
+	sort.Slice(heatmap.Cells, func(i, j int) bool {
+		if heatmap.Cells[i].Date != heatmap.Cells[j].Date {
+			return heatmap.Cells[i].Date < heatmap.Cells[j].Date
+		}
+		return heatmap.Cells[i].Hour < heatmap.Cells[j].Hour
+	})
+	return heatmap
+}
+
+func longestStretches(spans []usageSpan, limit int) []models.FocusStretch {
+	var merged []usageSpan
+	for _, span := range spans {
+		if n := len(merged); n > 0 {
+			last := &merged[n-1]
+			if strings.EqualFold(last.app, span.app) && span.start-last.end <= stretchGap.Milliseconds() {
+				last.end = max(last.end, span.end)
+				continue
+			}
+		}
+		merged = append(merged, span)
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].end-merged[i].start > merged[j].end-merged[j].start
+	})
+	if limit > 0 && len(merged) > limit {
+		merged = merged[:limit]
+	}
+	stretches := make([]models.FocusStretch, 0, len(merged))
+	for _, span := range merged {
+		stretches = append(stretches, models.FocusStretch{
+			AppName:  span.app,
+			Category: span.category,
+			StartMs:  span.start,
+			EndMs:    span.end,
+			Minutes:  msToMinutes(span.end - span.start),
+		})
+	}
+	return stretches
+}
+
+func msToMinutes(ms int64) float64 {
+	return float64(ms) / 60000
+}
@@ -0,0 +1,96 @@
+package focus
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"always/core/internal/models"
+)
+
+func timelineFixture() ([]models.FocusEvent, *Categorizer, int64, int64) {
+	at := func(hour, minute int) int64 {
+		return time.Date(2026, 10, 12, hour, minute, 0, 0, time.UTC).UnixMilli()
+	}
+	events := []models.FocusEvent{
+		// Starts before the range; ends when the next event starts.
+		{AppName: "Code", TsMs: at(23, 0)},
+		{AppName: "code", TsMs: at(23, 30)},
+		{AppName: "Slack", TsMs: at(24, 30), DurationMs: (5 * time.Minute).Milliseconds()},
+		// Still open; runs until the end of the range.
+		{AppName: "Code", TsMs: at(24, 35)},
+	}
+	c := NewCategorizer([]models.FocusCategoryRule{
+		{Category: models.CategoryProductive, MatchField: models.CategoryMatchAppName, Pattern: "Code"},
+		{Category: models.CategoryDistracting, MatchField: models.CategoryMatchAppName, Pattern: "Slack"},
+	})
+	return events, c, at(23, 20), at(25, 0)
+}
+
+func TestBuildTimeline(t *testing.T) {
+	events, c, sinceMs, untilMs := timelineFixture()
+	timeline := BuildTimeline(events, c, sinceMs, untilMs, time.UTC, 2)
+
+	want := []models.FocusDayUsage{
+		{Date: "2026-10-12", FocusUsage: models.FocusUsage{
+			TotalMinutes:    40,
+			AppMinutes:      map[string]float64{"Code": 10, "code": 30},
+			CategoryMinutes: map[string]float64{models.CategoryProductive: 40},
+		}},
+		{Date: "2026-10-13", FocusUsage: models.FocusUsage{
+			TotalMinutes:    60,
+			AppMinutes:      map[string]float64{"code": 30, "Slack": 5, "Code": 25},
+			CategoryMinutes: map[string]float64{models.CategoryProductive: 55, models.CategoryDistracting: 5},
+			Switches:        2,
+		}},
+	}
+	if !reflect.DeepEqual(timeline.Days, want) {
+		t.Fatalf("days = %+v\nwant %+v", timeline.Days, want)
+	}
+
+	// Same-app events merge into one stretch; the limit keeps the longest.
+	stretches := timeline.LongestStretches
+	if len(stretches) != 2 || stretches[0].Minutes != 70 || stretches[0].StartMs != sinceMs || stretches[1].Minutes != 25 || stretches[1].EndMs != untilMs {
+		t.Fatalf("stretches = %+v", stretches)
+	}
+}
+
+func TestBuildHeatmap(t *testing.T) {
+	events, c, sinceMs, untilMs := timelineFixture()
+	heatmap := BuildHeatmap(events, c, sinceMs, untilMs, time.UTC)
+
+	if len(heatmap.Hours) != 24 {
+		t.Fatalf("hours = %d, want 24", len(heatmap.Hours))
+	}
+	if h := heatmap.Hours[23]; h.TotalMinutes != 40 || h.Switches != 0 {
+		t.Fatalf("hour 23 = %+v", h)
+	}
+	if h := heatmap.Hours[0]; h.TotalMinutes != 60 || h.Switches != 2 || h.CategoryMinutes[models.CategoryDistracting] != 5 {
+		t.Fatalf("hour 0 = %+v", h)
+	}
+	if h := heatmap.Hours[12]; h.TotalMinutes != 0 || len(h.AppMinutes) != 0 {
+		t.Fatalf("hour 12 = %+v", h)
+	}
+	want := []models.FocusHeatmapCell{
+		{Date: "2026-10-12", Hour: 23, Minutes: 40},
+		{Date: "2026-10-13", Hour: 0, Minutes: 60, Switches: 2},
+	}
+	if !reflect.DeepEqual(heatmap.Cells, want) {
+		t.Fatalf("cells = %+v", heatmap.Cells)
+	}
+}
+
+func TestForEachHourSplitsAtLocalHours(t *testing.T) {
+	loc := time.FixedZone("UTC+5:30", 5*3600+1800)
+	start := time.Date(2026, 10, 12, 9, 50, 0, 0, loc).UnixMilli()
+	span := usageSpan{start: start, end: start + (75 * time.Minute).Milliseconds()}
+	var hours []int
+	var minutes []int64
+	forEachHour(span, loc, func(t time.Time, ms int64) {
+		hours = append(hours, t.Hour())
+		minutes = append(minutes, ms/60000)
+	})
+	if !reflect.DeepEqual(hours, []int{9, 10, 11}) || !reflect.DeepEqual(minutes, []int64{10, 60, 5}) {
+		t.Fatalf("hours = %v minutes = %v", hours, minutes)
+	}
+}
@@ -0,0 +1,138 @@
+//go:build linux
+
+package focus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const xpropTimeout = 2 * time.Second
+
+var errNoActiveWindow = errors.New("no active window")
+
+// x11Backend reads EWMH properties through xprop so it works against any
+// X server, including Xvfb in CI, without linking against libX11.
+type x11Backend struct {
+	xpropPath string
+}
+
+func newX11Backend() (windowBackend, error) {
+	path, err := exec.LookPath("xprop")
+	if err != nil {
+		return nil, fmt.Errorf("%w: xprop not found: %v", ErrUnsupported, err)
+	}
+	return &x11Backend{xpropPath: path}, nil
+}
+
+func (x *x11Backend) Name() string {
+	return "x11"
+}
+
+func (x *x11Backend) ActiveWindow() (activeWindow, error) {
+	rootOut, err := x.run("-root", "_NET_ACTIVE_WINDOW")
+	if err != nil {
+		return activeWindow{}, err
+	}
+	windowID, err := parseActiveWindowID(rootOut)
+	if err != nil {
+		return activeWindow{}, err
+	}
+
+	propsOut, err := x.run("-id", windowID, "_NET_WM_PID", "WM_CLASS", "_NET_WM_NAME", "WM_NAME")
+	if err != nil {
+		return activeWindow{}, err
+	}
+	props := parseXpropProperties(propsOut)
+
+	window := activeWindow{WindowID: windowID}
+	if values := parseXpropStrings(props["WM_CLASS"]); len(values) > 0 {
+		window.AppID = values[0]
+		window.AppName = values[len(values)-1]
+	}
+	if pid, err := strconv.Atoi(strings.TrimSpace(props["_NET_WM_PID"])); err == nil {
+		window.PID = pid
+	}
+	if values := parseXpropStrings(props["_NET_WM_NAME"]); len(values) > 0 {
+		window.Title = values[0]
+	} else if values := parseXpropStrings(props["WM_NAME"]); len(values) > 0 {
+		window.Title = values[0]
+	}
+	return window, nil
+}
+
+func (x *x11Backend) run(args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), xpropTimeout)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, x.xpropPath, args...).Output()
+	if err != nil {
+		return "", fmt.Errorf("xprop %s: %w", strings.Join(args, " "), err)
+	}
+	return string(output), nil
+}
+
+// parseActiveWindowID extracts the id from output such as
+// "_NET_ACTIVE_WINDOW(WINDOW): window id # 0x3a00007".
+func parseActiveWindowID(output string) (string, error) {
+	idx := strings.LastIndex(output, "#")
+	if idx < 0 {
+		return "", errNoActiveWindow
+	}
+	fields := strings.Split(output[idx+1:], ",")
+	id := strings.TrimSpace(fields[0])
+	if id == "" || id == "0x0" {
+		return "", errNoActiveWindow
+	}
+	return id, nil
+}
+
+// parseXpropProperties maps property names to their raw values for lines of
+// the form "NAME(TYPE) = value". Properties xprop reports as "not found" are
+// omitted.
+func parseXpropProperties(output string) map[string]string {
+	props := map[string]string{}
+	for _, line := range strings.Split(output, "\n") {
+		eq := strings.Index(line, " = ")
+		if eq < 0 {
+			continue
+		}
+		name := line[:eq]
+		if paren := strings.Index(name, "("); paren >= 0 {
+			name = name[:paren]
+		}
+		props[strings.TrimSpace(name)] = strings.TrimSpace(line[eq+3:])
+	}
+	return props
+}
+
+// parseXpropStrings decodes a comma separated list of quoted xprop strings,
+// honouring backslash escapes.
+func parseXpropStrings(raw string) []string {
+	var values []string
+	var current strings.Builder
+	inQuote := false
+	escaped := false
+	for _, r := range raw {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && inQuote:
+			escaped = true
+		case r == '"':
+			if inQuote {
+				values = append(values, current.String())
+				current.Reset()
+			}
+			inQuote = !inQuote
+		case inQuote:
+			current.WriteRune(r)
+		}
+	}
+	return values
+}
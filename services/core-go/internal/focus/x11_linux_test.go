@@ -0,0 +1,96 @@
+//go:build linux
+
+package focus
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseActiveWindowID(t *testing.T) {
+	tests := []struct {
+		output string
+		want   string
+		err    error
+	}{
+		{output: "_NET_ACTIVE_WINDOW(WINDOW): window id # 0x3a00007\n", want: "0x3a00007"},
+		{output: "_NET_ACTIVE_WINDOW(WINDOW): window id # 0x3a00007, 0x0\n", want: "0x3a00007"},
+		{output: "_NET_ACTIVE_WINDOW(WINDOW): window id # 0x0\n", err: errNoActiveWindow},
+		{output: "_NET_ACTIVE_WINDOW:  not found.\n", err: errNoActiveWindow},
+	}
+	for _, tt := range tests {
+		got, err := parseActiveWindowID(tt.output)
+		if got != tt.want || !errors.Is(err, tt.err) {
+			t.Errorf("parseActiveWindowID(%q) = %q, %v; want %q, %v", tt.output, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestParseXpropStrings(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want []string
+	}{
+		{raw: `"navigator", "Firefox"`, want: []string{"navigator", "Firefox"}},
+		{raw: `"say \"hi\", ok"`, want: []string{`say "hi", ok`}},
+		{raw: `not a string`, want: nil},
+	}
+	for _, tt := range tests {
+		if got := parseXpropStrings(tt.raw); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseXpropStrings(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+// fakeXprop writes a script that prints canned xprop output for the root
+// window and for any window id.
+func fakeXprop(t *testing.T, root, window string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range map[string]string{"root.txt": root, "window.txt": window} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	script := filepath.Join(dir, "xprop")
+	body := "#!/bin/sh\nif [ \"$1\" = -root ]; then cat " + filepath.Join(dir, "root.txt") +
+		"; else cat " + filepath.Join(dir, "window.txt") + "; fi\n"
+	if err := os.WriteFile(script, []byte(body), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return script
+}
+
+func TestX11ActiveWindow(t *testing.T) {
+	xprop := fakeXprop(t,
+		"_NET_ACTIVE_WINDOW(WINDOW): window id # 0x1c00004\n",
+		"_NET_WM_PID(CARDINAL) = 4242\n"+
+			"WM_CLASS(STRING) = \"gnome-terminal-server\", \"Gnome-terminal\"\n"+
+			"_NET_WM_NAME(UTF8_STRING) = \"vim rules.go\"\n"+
+			"WM_NAME(STRING) = \"legacy\"\n")
+	backend := &x11Backend{xpropPath: xprop}
+	got, err := backend.ActiveWindow()
+	if err != nil {
+		t.Fatalf("active window: %v", err)
+	}
+	want := activeWindow{AppName: "Gnome-terminal", AppID: "gnome-terminal-server", PID: 4242, Title: "vim rules.go", WindowID: "0x1c00004"}
+	if got != want {
+		t.Fatalf("window = %+v, want %+v", got, want)
+	}
+}
+
+func TestX11ActiveWindowFallsBackToWMName(t *testing.T) {
+	xprop := fakeXprop(t,
+		"_NET_ACTIVE_WINDOW(WINDOW): window id # 0x1c00004\n",
+		"_NET_WM_PID:  not found.\nWM_CLASS(STRING) = \"xterm\", \"XTerm\"\n_NET_WM_NAME:  not found.\nWM_NAME(STRING) = \"bash\"\n")
+	got, err := (&x11Backend{xpropPath: xprop}).ActiveWindow()
+	if err != nil {
+		t.Fatalf("active window: %v", err)
+	}
+	if got.Title != "bash" || got.PID != 0 || got.AppName != "XTerm" {
+		t.Fatalf("window = %+v", got)
+	}
+}
@@ -0,0 +1,75 @@
+//go:build linux && xvfb
+
+package focus
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestX11AgainstXvfb runs the x11 backend against a real X server. It needs
+// Xvfb, xprop and xwininfo and runs with: go test -tags xvfb ./internal/focus
+func TestX11AgainstXvfb(t *testing.T) {
+	for _, tool := range []string{"Xvfb", "xprop", "xwininfo"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skipf("%s not installed", tool)
+		}
+	}
+	display := fmt.Sprintf(":%d", 90+os.Getpid()%9)
+	server := exec.Command("Xvfb", display, "-screen", "0", "640x480x24", "-nolisten", "tcp")
+	if err := server.Start(); err != nil {
+		t.Fatalf("start Xvfb: %v", err)
+	}
+	t.Cleanup(func() {
+		server.Process.Kill()
+		server.Wait()
+	})
+	t.Setenv("DISPLAY", display)
+
+	var rootID string
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(100 * time.Millisecond) {
+		out, err := exec.Command("xwininfo", "-root").Output()
+		if err == nil {
+			for _, field := range strings.Fields(string(out)) {
+				if strings.HasPrefix(field, "0x") {
+					rootID = field
+					break
+				}
+			}
+			break
+		}
+	}
+	if rootID == "" {
+		t.Fatal("Xvfb did not come up")
+	}
+
+	// Xvfb runs no window manager, so the root window stands in for the
+	// active window and carries the properties a client would set.
+	for _, args := range [][]string{
+		{"-root", "-f", "_NET_ACTIVE_WINDOW", "32x", "-set", "_NET_ACTIVE_WINDOW", rootID},
+		{"-root", "-f", "WM_CLASS", "8s", "-set", "WM_CLASS", "xvfb-test"},
+		{"-root", "-f", "_NET_WM_NAME", "8u", "-set", "_NET_WM_NAME", "Xvfb title"},
+		{"-root", "-f", "_NET_WM_PID", "32c", "-set", "_NET_WM_PID", "1234"},
+	} {
+		if out, err := exec.Command("xprop", args...).CombinedOutput(); err != nil {
+			t.Fatalf("xprop %v: %v: %s", args, err, out)
+		}
+	}
+
+	t.Setenv(envLinuxBackend, "x11")
+	backend, err := selectLinuxBackend()
+	if err != nil {
+		t.Fatalf("select backend: %v", err)
+	}
+	window, err := backend.ActiveWindow()
+	if err != nil {
+		t.Fatalf("active window: %v", err)
+	}
+	if window.AppName != "xvfb-test" || window.Title != "Xvfb title" || window.PID != 1234 {
+		t.Fatalf("window = %+v", window)
+	}
+}
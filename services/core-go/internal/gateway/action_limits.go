@@ -0,0 +1,125 @@
+package gateway
+
+import (
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"always/core/internal/models"
+)
+
+// ActionLimit caps one action type on top of the shared cooldown and budgets.
+// Zero values disable the corresponding check.
+type ActionLimit struct {
+	Cooldown   time.Duration
+	DailyLimit int
+}
+
+type actionLimitSettings struct {
+	cooldown   string
+	dailyLimit string
+}
+
+var actionLimitKeys = map[models.ActionType]actionLimitSettings{
+	models.ActionEncourage:     {"cooldown_encourage_seconds", "daily_limit_encourage"},
+	models.ActionTaskBreakdown: {"cooldown_task_breakdown_seconds", "daily_limit_task_breakdown"},
+	models.ActionRestReminder:  {"cooldown_rest_reminder_seconds", "daily_limit_rest_reminder"},
+	models.ActionReframe:       {"cooldown_reframe_seconds", "daily_limit_reframe"},
+}
+
+func DefaultActionLimits() map[models.ActionType]ActionLimit {
+	return map[models.ActionType]ActionLimit{
+		models.ActionRestReminder:  {Cooldown: 45 * time.Minute},
+		models.ActionTaskBreakdown: {DailyLimit: 3},
+	}
+}
+
+// ActionCooldownReason names the per-type cooldown that blocked an action,
+// e.g. cooldown_rest_reminder.
+func ActionCooldownReason(actionType models.ActionType) string {
+	return "cooldown_" + strings.ToLower(string(actionType))
+}
+
+// ActionLimitReason names the per-type daily limit that blocked an action,
+// e.g. daily_limit_task_breakdown.
+func ActionLimitReason(actionType models.ActionType) string {
+	return "daily_limit_" + strings.ToLower(string(actionType))
+}
+
+func (g *Gateway) loadActionLimitsLocked(cfg *Config) {
+	cfg.ActionLimits = DefaultActionLimits()
+	if g.store == nil {
+		return
+	}
+	for actionType, keys := range actionLimitKeys {
+		limit := cfg.ActionLimits[actionType]
+		if value, ok, err := g.store.GetSetting(keys.cooldown); err == nil && ok {
+			if parsed, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && parsed >= 0 {
+				limit.Cooldown = time.Duration(parsed) * time.Second
+			}
+		}
+		if value, ok, err := g.store.GetSetting(keys.dailyLimit); err == nil && ok {
+			if parsed, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && parsed >= 0 {
+				limit.DailyLimit = parsed
+			}
+		}
+		cfg.ActionLimits[actionType] = limit
+	}
+}
+
+// checkActionLimitLocked returns the reason an action type may not fire now.
+func (g *Gateway) checkActionLimitLocked(actionType models.ActionType, now time.Time) (string, bool) {
+	limit := g.config.ActionLimits[actionType]
+	usage, ok := g.actionUsage[actionType]
+	if !ok {
+		return "", false
+	}
+	if limit.Cooldown > 0 && usage.LastMs > 0 {
+		since := now.Sub(time.UnixMilli(usage.LastMs))
+		if since >= 0 && since < limit.Cooldown {
+			g.logger.Info("gateway action cooldown active",
+				slog.String("action_type", string(actionType)),
+				slog.Float64("since_last", since.Seconds()),
+				slog.Float64("cooldown", limit.Cooldown.Seconds()))
+			return ActionCooldownReason(actionType), true
+		}
+	}
+	if limit.DailyLimit > 0 && usage.DailyDay == now.Format("2006-01-02") && usage.DailyCount >= limit.DailyLimit {
+		g.logger.Info("gateway action daily limit reached",
+			slog.String("action_type", string(actionType)),
+			slog.Int("count", usage.DailyCount),
+			slog.Int("limit", limit.DailyLimit))
+		return ActionLimitReason(actionType), true
+	}
+	return "", false
+}
+
+func (g *Gateway) actionLimitInputsLocked(actionType models.ActionType, now time.Time) map[string]float64 {
+	limit := g.config.ActionLimits[actionType]
+	usage := g.actionUsage[actionType]
+	inputs := map[string]float64{
+		"cooldown_seconds": limit.Cooldown.Seconds(),
+		"daily_limit":      float64(limit.DailyLimit),
+	}
+	if usage.LastMs > 0 {
+		inputs["remaining_seconds"] = remainingSeconds(time.UnixMilli(usage.LastMs), limit.Cooldown, now)
+	}
+	if usage.DailyDay == now.Format("2006-01-02") {
+		inputs["daily_count"] = float64(usage.DailyCount)
+	}
+	return inputs
+}
+
+func (g *Gateway) recordActionLocked(actionType models.ActionType, now time.Time) {
+	usage := g.actionUsage[actionType]
+	usage.ActionType = actionType
+	day := now.Format("2006-01-02")
+	if usage.DailyDay != day {
+		usage.DailyDay = day
+		usage.DailyCount = 0
+	}
+	usage.DailyCount++
+	usage.LastMs = now.UnixMilli()
+	g.actionUsage[actionType] = usage
+}
@@ -0,0 +1,82 @@
+package gateway
+
+import (
+	"testing"
+	"time"
+
+	"always/core/internal/models"
+)
+
+func TestActionCooldownAddsToSharedCooldown(t *testing.T) {
+	g, _, clk := newTestGateway(t, nil)
+	ctx := testContext(models.ModeActive, nil)
+	evaluateAt := func(offset time.Duration, actionType models.ActionType) (models.Action, models.GatewayDecision) {
+		clk.Set(testStart.Add(offset))
+		return g.Evaluate(ctx, testAction(actionType))
+	}
+
+	if final, decision := evaluateAt(0, models.ActionEncourage); final.ActionType != models.ActionEncourage {
+		t.Fatalf("first encourage: %+v", decision)
+	}
+	// REST_REMINDER has its own cooldown but still waits for the shared one.
+	final, decision := evaluateAt(time.Minute, models.ActionRestReminder)
+	if final.ActionType != models.ActionAmbient || decision.Reason != ReasonCooldownActive {
+		t.Fatalf("rest reminder during shared cooldown: %s %+v", final.ActionType, decision)
+	}
+	if final, decision := evaluateAt(6*time.Minute, models.ActionRestReminder); final.ActionType != models.ActionRestReminder {
+		t.Fatalf("rest reminder after shared cooldown: %+v", decision)
+	}
+	final, decision = evaluateAt(20*time.Minute, models.ActionRestReminder)
+	if final.ActionType != models.ActionAmbient || decision.Reason != ActionCooldownReason(models.ActionRestReminder) {
+		t.Fatalf("rest reminder during its own cooldown: %s %+v", final.ActionType, decision)
+	}
+	if final, decision := evaluateAt(20*time.Minute+time.Second, models.ActionEncourage); final.ActionType != models.ActionEncourage {
+		t.Fatalf("encourage is not held by the rest reminder cooldown: %+v", decision)
+	}
+	if final, decision := evaluateAt(52*time.Minute, models.ActionRestReminder); final.ActionType != models.ActionRestReminder {
+		t.Fatalf("rest reminder after both cooldowns: %+v", decision)
+	}
+}
+
+func TestActionDailyLimit(t *testing.T) {
+	g, _, clk := newTestGateway(t, map[string]string{
+		"cooldown_seconds":           "0",
+		"daily_limit_task_breakdown": "2",
+	})
+	ctx := testContext(models.ModeActive, nil)
+	for i := range 3 {
+		clk.Set(testStart.Add(time.Duration(i) * time.Hour))
+		final, decision := g.Evaluate(ctx, testAction(models.ActionTaskBreakdown))
+		if i < 2 && final.ActionType != models.ActionTaskBreakdown {
+			t.Fatalf("breakdown %d: %+v", i, decision)
+		}
+		if i == 2 && (final.ActionType != models.ActionEncourage || decision.Reason != ActionLimitReason(models.ActionTaskBreakdown)) {
+			t.Fatalf("breakdown over the limit: %s %+v", final.ActionType, decision)
+		}
+	}
+	// The count restarts the next day.
+	clk.Set(testStart.Add(24 * time.Hour))
+	if final, decision := g.Evaluate(ctx, testAction(models.ActionTaskBreakdown)); final.ActionType != models.ActionTaskBreakdown {
+		t.Fatalf("breakdown next day: %+v", decision)
+	}
+}
+
+func TestCanInterveneAppliesActionLimits(t *testing.T) {
+	g, _, clk := newTestGateway(t, map[string]string{"cooldown_seconds": "0"})
+	ctx := testContext(models.ModeActive, nil)
+	if ok, reason := g.CanIntervene(ctx, 1, models.ActionRestReminder); !ok {
+		t.Fatalf("fresh gateway blocked: %s", reason)
+	}
+	g.Evaluate(ctx, testAction(models.ActionRestReminder))
+
+	clk.Set(testStart.Add(time.Minute))
+	if ok, reason := g.CanIntervene(ctx, 1, models.ActionRestReminder); ok || reason != ActionCooldownReason(models.ActionRestReminder) {
+		t.Fatalf("CanIntervene(REST_REMINDER) = %v, %q", ok, reason)
+	}
+	if ok, reason := g.CanIntervene(ctx, 1, models.ActionRestReminder, models.ActionEncourage); !ok {
+		t.Fatalf("one clear type should be enough: %s", reason)
+	}
+	if ok, reason := g.CanIntervene(ctx, 100); ok || reason != ReasonBudgetExhausted {
+		t.Fatalf("CanIntervene(100) = %v, %q", ok, reason)
+	}
+}
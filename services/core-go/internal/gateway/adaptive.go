@@ -0,0 +1,115 @@
+package gateway
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"time"
+
+	"always/core/internal/models"
+)
+
+const (
+	settingAdaptiveBudget     = "adaptive_budget_enabled"
+	profilePreferredBudget    = "preferred_intervention_budget"
+	defaultRecoveryRate       = 0.5 // Recover 1 point every 2 mins
+	adaptiveWindow            = 14 * 24 * time.Hour
+	adaptiveHalfLife          = 3 * 24 * time.Hour
+	adaptivePriorWeight       = 2.0
+	adaptiveShrink            = 5.0
+	adaptiveMinFactor         = 0.6
+	adaptiveMaxFactor         = 1.4
+	adaptiveRecoveryDampening = 0.5
+)
+
+// Adaptation explains how feedback currently scales the configured budgets.
+// Older feedback weighs less and drops out of the window, so the factors drift
+// back to 1 when the user stops giving feedback.
+type Adaptation struct {
+	Enabled        bool    `json:"enabled"`
+	Samples        int     `json:"samples"`
+	Weight         float64 `json:"weight"`
+	Positive       float64 `json:"positive"`
+	Negative       float64 `json:"negative"`
+	AcceptanceRate float64 `json:"acceptance_rate"`
+	Preference     string  `json:"preference,omitempty"`
+	BudgetFactor   float64 `json:"budget_factor"`
+	RecoveryFactor float64 `json:"recovery_factor"`
+	Explanation    string  `json:"explanation"`
+}
+
+func (g *Gateway) refreshAdaptationLocked(now time.Time) Adaptation {
+	adaptation := Adaptation{Enabled: true, BudgetFactor: 1, RecoveryFactor: 1}
+	if g.store == nil {
+		adaptation.Enabled = false
+		adaptation.Explanation = "自适应预算已关闭。"
+		return adaptation
+	}
+	if value, ok, err := g.store.GetSetting(settingAdaptiveBudget); err == nil && ok &&
+		strings.EqualFold(strings.TrimSpace(value), "false") {
+		adaptation.Enabled = false
+		adaptation.Explanation = "自适应预算已关闭。"
+		return adaptation
+	}
+
+	samples, err := g.store.ListFeedbackSince(now.Add(-adaptiveWindow).UnixMilli())
+	if err != nil {
+		g.logger.Warn("load feedback for adaptive budget failed", slog.Any("error", err))
+		adaptation.Explanation = "读取反馈失败，使用默认预算。"
+		return adaptation
+	}
+	prior := 0.5
+	if value, confidence, ok, err := g.store.GetProfile(profilePreferredBudget); err == nil && ok {
+		adaptation.Preference = value
+		switch value {
+		case "high":
+			prior += 0.15 * confidence
+		case "low":
+			prior -= 0.15 * confidence
+		}
+	}
+	adaptation = scoreFeedback(adaptation, samples, prior, now)
+	return adaptation
+}
+
+// scoreFeedback turns decayed acceptance into budget and recovery factors. The
+// preference profile only shifts the estimate; with little feedback the
+// factors stay close to 1.
+func scoreFeedback(adaptation Adaptation, samples []models.FeedbackSample, prior float64, now time.Time) Adaptation {
+	for _, sample := range samples {
+		var positive bool
+		switch sample.Feedback {
+		case models.FeedbackLike, models.FeedbackAdopted, models.FeedbackOpen:
+			positive = true
+		case models.FeedbackDislike, models.FeedbackIgnored, models.FeedbackClosed:
+		default:
+			continue
+		}
+		age := now.Sub(time.UnixMilli(sample.CreatedAtMs))
+		weight := math.Pow(0.5, max(age, 0).Hours()/adaptiveHalfLife.Hours())
+		if positive {
+			adaptation.Positive += weight
+		} else {
+			adaptation.Negative += weight
+		}
+		adaptation.Samples++
+	}
+	adaptation.Weight = adaptation.Positive + adaptation.Negative
+	if adaptation.Samples == 0 {
+		adaptation.Explanation = "近 14 天没有反馈，使用默认预算。"
+		return adaptation
+	}
+	adaptation.AcceptanceRate = (adaptation.Positive + adaptivePriorWeight*prior) / (adaptation.Weight + adaptivePriorWeight)
+	strength := adaptation.Weight / (adaptation.Weight + adaptiveShrink)
+	factor := 1 + (adaptation.AcceptanceRate-0.5)*2*(adaptiveMaxFactor-1)*strength
+	adaptation.BudgetFactor = min(max(factor, adaptiveMinFactor), adaptiveMaxFactor)
+	adaptation.RecoveryFactor = 1 + (adaptation.BudgetFactor-1)*adaptiveRecoveryDampening
+
+	explanation := fmt.Sprintf("近 14 天 %d 次反馈的接受率 %.0f%%", adaptation.Samples, adaptation.AcceptanceRate*100)
+	if adaptation.Preference != "" {
+		explanation += fmt.Sprintf("（偏好 %s）", adaptation.Preference)
+	}
+	adaptation.Explanation = explanation + fmt.Sprintf("，预算 ×%.2f，恢复速度 ×%.2f。", adaptation.BudgetFactor, adaptation.RecoveryFactor)
+	return adaptation
+}
@@ -0,0 +1,107 @@
+package gateway
+
+import (
+	"testing"
+	"time"
+
+	"always/core/internal/db"
+	"always/core/internal/models"
+)
+
+func feedbackAt(feedback models.FeedbackType, age time.Duration, n int) []models.FeedbackSample {
+	samples := make([]models.FeedbackSample, n)
+	for i := range samples {
+		samples[i] = models.FeedbackSample{Feedback: feedback, CreatedAtMs: testStart.Add(-age).UnixMilli()}
+	}
+	return samples
+}
+
+func TestScoreFeedback(t *testing.T) {
+	base := Adaptation{Enabled: true, BudgetFactor: 1, RecoveryFactor: 1}
+	tests := []struct {
+		name     string
+		samples  []models.FeedbackSample
+		prior    float64
+		min, max float64
+	}{
+		{name: "no feedback", prior: 0.5, min: 1, max: 1},
+		{name: "ignored types", samples: feedbackAt("MAYBE", 0, 10), prior: 0.5, min: 1, max: 1},
+		{name: "accepted", samples: feedbackAt(models.FeedbackAdopted, 0, 20), prior: 0.5, min: 1.2, max: adaptiveMaxFactor},
+		{name: "rejected", samples: feedbackAt(models.FeedbackDislike, 0, 20), prior: 0.5, min: adaptiveMinFactor, max: 0.8},
+		{name: "rejected long ago", samples: feedbackAt(models.FeedbackDislike, 13*24*time.Hour, 20), prior: 0.5, min: 0.9, max: 1},
+		{name: "one like", samples: feedbackAt(models.FeedbackLike, 0, 1), prior: 0.5, min: 1, max: 1.1},
+		{name: "bounded", samples: feedbackAt(models.FeedbackLike, 0, 1000), prior: 1, min: 1.39, max: adaptiveMaxFactor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := scoreFeedback(base, tt.samples, tt.prior, testStart)
+			if got.BudgetFactor < tt.min-1e-9 || got.BudgetFactor > tt.max+1e-9 {
+				t.Fatalf("budget factor = %v, want [%v, %v]: %+v", got.BudgetFactor, tt.min, tt.max, got)
+			}
+			if !approxEqual(got.RecoveryFactor, 1+(got.BudgetFactor-1)*adaptiveRecoveryDampening) {
+				t.Fatalf("recovery factor = %v for budget factor %v", got.RecoveryFactor, got.BudgetFactor)
+			}
+			if got.Explanation == "" {
+				t.Fatal("missing explanation")
+			}
+		})
+	}
+}
+
+func insertFeedback(t *testing.T, store *db.Store, feedback models.FeedbackType, implicit bool, at time.Time) {
+	t.Helper()
+	var err error
+	if implicit {
+		_, err = store.DB().Exec(`INSERT INTO implicit_feedback_events (request_id, feedback_type, feedback_text, created_at_ms) VALUES ('r', ?, '', ?)`, feedback, at.UnixMilli())
+	} else {
+		_, err = store.DB().Exec(`INSERT INTO feedback_logs (request_id, feedback, created_at, created_at_ms) VALUES ('r', ?, '', ?)`, feedback, at.UnixMilli())
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAdaptiveBudgetFromStoredFeedback(t *testing.T) {
+	g, store, clk := newTestGateway(t, nil)
+	for i := range 10 {
+		insertFeedback(t, store, models.FeedbackIgnored, true, testStart.Add(-time.Duration(i)*time.Hour))
+		insertFeedback(t, store, models.FeedbackDislike, false, testStart.Add(-time.Duration(i)*time.Hour))
+	}
+	// Implicit feedback repeated in feedback_logs is counted once.
+	insertFeedback(t, store, models.FeedbackIgnored, false, testStart)
+
+	state := g.State()
+	adaptation := state.Adaptation
+	if !adaptation.Enabled || adaptation.Samples != 20 || adaptation.BudgetFactor >= 1 {
+		t.Fatalf("adaptation = %+v", adaptation)
+	}
+	if active := state.Budgets[models.ModeActive]; !approxEqual(active.Max, 10*adaptation.BudgetFactor) {
+		t.Fatalf("active max = %v, factor %v", active.Max, adaptation.BudgetFactor)
+	}
+	if !approxEqual(state.RecoveryPerMin, defaultRecoveryRate*adaptation.RecoveryFactor) {
+		t.Fatalf("recovery = %v, factor %v", state.RecoveryPerMin, adaptation.RecoveryFactor)
+	}
+
+	// A stated preference for more help shifts the estimate up.
+	if _, err := store.DB().Exec(`INSERT INTO profiles (key, value, confidence, updated_at_ms) VALUES (?, 'high', 1, 0)`, profilePreferredBudget); err != nil {
+		t.Fatal(err)
+	}
+	preferred := g.State().Adaptation
+	if preferred.Preference != "high" || preferred.BudgetFactor <= adaptation.BudgetFactor {
+		t.Fatalf("preference ignored: %+v vs %+v", preferred, adaptation)
+	}
+
+	// Feedback leaving the window drops out and the budgets return to normal.
+	clk.Set(testStart.Add(adaptiveWindow + 10*time.Hour))
+	if decayed := g.State(); decayed.Adaptation.Samples != 0 || decayed.Budgets[models.ModeActive].Max != 10 {
+		t.Fatalf("after the window = %+v", decayed.Adaptation)
+	}
+
+	clk.Set(testStart)
+	if err := store.UpsertSetting(settingAdaptiveBudget, "false"); err != nil {
+		t.Fatal(err)
+	}
+	if disabled := g.State(); disabled.Adaptation.Enabled || disabled.Budgets[models.ModeActive].Max != 10 {
+		t.Fatalf("disabled adaptation = %+v", disabled.Adaptation)
+	}
+}
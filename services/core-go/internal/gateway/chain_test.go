@@ -0,0 +1,137 @@
+package gateway
+
+import (
+	"slices"
+	"testing"
+
+	"always/core/internal/models"
+)
+
+// testRule is a Go rule that answers every action with verdict.
+type testRule struct {
+	name    string
+	verdict Verdict
+}
+
+func (r testRule) Name() string { return r.name }
+
+func (r testRule) Evaluate(models.Context, models.Action, RuleState) Verdict { return r.verdict }
+
+func TestRuleChainOrder(t *testing.T) {
+	deny := testRule{name: "compliance", verdict: Verdict{Effect: EffectDeny}}
+	cases := []struct {
+		name string
+		opts []Option
+		want []string
+	}{
+		{
+			name: "default",
+			want: []string{RuleValidAction, RuleHighRisk, RuleLowQuality, RuleSilentMode, RuleConfig},
+		},
+		{
+			name: "append",
+			opts: []Option{WithRule(deny)},
+			want: []string{RuleValidAction, RuleHighRisk, RuleLowQuality, RuleSilentMode, RuleConfig, "compliance"},
+		},
+		{
+			name: "before a built-in",
+			opts: []Option{WithRuleBefore(RuleLowQuality, deny)},
+			want: []string{RuleValidAction, RuleHighRisk, "compliance", RuleLowQuality, RuleSilentMode, RuleConfig},
+		},
+		{
+			name: "after a built-in",
+			opts: []Option{WithRuleAfter(RuleSilentMode, deny)},
+			want: []string{RuleValidAction, RuleHighRisk, RuleLowQuality, RuleSilentMode, "compliance", RuleConfig},
+		},
+		{
+			name: "unknown anchor appends",
+			opts: []Option{WithRuleBefore("missing", deny)},
+			want: []string{RuleValidAction, RuleHighRisk, RuleLowQuality, RuleSilentMode, RuleConfig, "compliance"},
+		},
+		{
+			name: "replace in place",
+			opts: []Option{WithRule(testRule{name: RuleHighRisk})},
+			want: []string{RuleValidAction, RuleHighRisk, RuleLowQuality, RuleSilentMode, RuleConfig},
+		},
+		{
+			name: "remove built-ins",
+			opts: []Option{WithoutRule(RuleHighRisk), WithoutRule(RuleSilentMode), WithoutRule("missing")},
+			want: []string{RuleValidAction, RuleLowQuality, RuleConfig},
+		},
+		{
+			name: "reorder",
+			opts: []Option{WithRule(deny), WithRuleOrder("compliance", RuleSilentMode, "missing")},
+			want: []string{"compliance", RuleSilentMode, RuleValidAction, RuleHighRisk, RuleLowQuality, RuleConfig},
+		},
+	}
+	for _, tc := range cases {
+		g, _, _ := newTestGateway(t, nil, tc.opts...)
+		if got := g.Rules().Chain; !slices.Equal(got, tc.want) {
+			t.Errorf("%s: chain = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestRemovedSafetyRulesNoLongerBlock(t *testing.T) {
+	risky := testAction(models.ActionEncourage)
+	risky.RiskLevel = models.RiskHigh
+	unsure := testAction(models.ActionEncourage)
+	unsure.Confidence = 0.2
+	cases := []struct {
+		name   string
+		rule   string
+		mode   models.Mode
+		action models.Action
+		reason string
+	}{
+		{"high risk", RuleHighRisk, models.ModeActive, risky, ReasonHighRiskBlocked},
+		{"low quality", RuleLowQuality, models.ModeActive, unsure, ReasonLowQualityAction},
+		{"silent mode", RuleSilentMode, models.ModeSilent, testAction(models.ActionEncourage), ReasonModeSilentOverride},
+	}
+	for _, tc := range cases {
+		g, _, _ := newTestGateway(t, nil)
+		_, decision := g.Evaluate(testContext(tc.mode, nil), tc.action)
+		if decision.Decision == models.GatewayAllow || decision.Reason != tc.reason {
+			t.Errorf("%s: default chain decision = %s (%s), want blocked by %s", tc.name, decision.Decision, decision.Reason, tc.reason)
+		}
+
+		g, _, _ = newTestGateway(t, nil, WithoutRule(tc.rule))
+		_, decision = g.Evaluate(testContext(tc.mode, nil), tc.action)
+		if decision.Reason == tc.reason {
+			t.Errorf("%s: still blocked by %s after WithoutRule", tc.name, tc.reason)
+		}
+		for _, step := range decision.Trace {
+			if step.Check == tc.rule {
+				t.Errorf("%s: removed rule still in the trace", tc.name)
+			}
+		}
+	}
+}
+
+func TestCustomRuleRunsWhereItIsPlaced(t *testing.T) {
+	deny := testRule{name: "compliance", verdict: Verdict{Effect: EffectDeny, Reason: "compliance_hold"}}
+	unsure := testAction(models.ActionEncourage)
+	unsure.Confidence = 0.2
+
+	// Ahead of low_quality the custom rule decides first.
+	g, _, _ := newTestGateway(t, nil, WithRuleBefore(RuleLowQuality, deny))
+	_, decision := g.Evaluate(testContext(models.ModeActive, nil), unsure)
+	if decision.Reason != "compliance_hold" {
+		t.Fatalf("reason = %q, want compliance_hold", decision.Reason)
+	}
+
+	// Appended, it only sees actions the earlier rules let through.
+	g, _, _ = newTestGateway(t, nil, WithRule(deny))
+	_, decision = g.Evaluate(testContext(models.ModeActive, nil), unsure)
+	if decision.Reason != ReasonLowQualityAction {
+		t.Fatalf("reason = %q, want %s", decision.Reason, ReasonLowQualityAction)
+	}
+	_, decision = g.Evaluate(testContext(models.ModeActive, nil), testAction(models.ActionEncourage))
+	if decision.Decision == models.GatewayAllow || decision.Reason != "compliance_hold" {
+		t.Fatalf("decision = %s (%s), want denied by compliance", decision.Decision, decision.Reason)
+	}
+	trace := traceChecks(decision)
+	if last := trace[len(trace)-1]; last != "compliance=block" {
+		t.Fatalf("trace = %v, want it to end at compliance=block", trace)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	"always/core/internal/clock"
 	"always/core/internal/models"
 )
 
@@ -15,6 +16,21 @@ const (
 	ReasonCooldownActive  = "cooldown_active"
 )
 
+// SignalFocusSessionActive is set on the context while a focus session runs;
+// the default rules make anything but a break cost more then.
+const SignalFocusSessionActive = "focus_session_active"
+
+// SignalFocusPattern names a detected switch pattern such as ping_pong; the
+// default rules make a REFRAME, the intervention aimed at it, cheaper.
+const SignalFocusPattern = "focus_pattern"
+
+// SignalInMeeting and SignalNextMeetingMinutes come from the local calendar;
+// the default rules stay silent during a meeting and shortly before one.
+const (
+	SignalInMeeting          = "in_meeting"
+	SignalNextMeetingMinutes = "next_meeting_in_minutes"
+)
+
 const (
 	settingInterventionBudget = "intervention_budget"
 	settingBudgetSilent       = "budget_silent"
@@ -23,6 +39,7 @@ const (
 	settingDailyBudgetCap     = "daily_budget_cap"
 	settingHourlyBudgetCap    = "hourly_budget_cap"
 	settingCooldownSeconds    = "cooldown_seconds"
+	defaultCooldownSeconds    = 300 // 5 minutes cooldown
 )
 
 type Config struct {
@@ -31,18 +48,25 @@ type Config struct {
 	CooldownSeconds float64
 	HourlyCap       float64
 	DailyCap        float64
+	ActionLimits    map[models.ActionType]ActionLimit
+	Ladder          Ladder
+	// LadderIgnoreThreshold is how many IGNORED in a row step a type down.
+	LadderIgnoreThreshold int
 }
 
 type SettingsStore interface {
 	GetSetting(key string) (string, bool, error)
-	GetBudgetUsage() (models.BudgetUsage, error)
-	SetBudgetUsage(models.BudgetUsage) error
+	ListFeedbackSince(sinceMs int64) ([]models.FeedbackSample, error)
+	GetProfile(key string) (string, float64, bool, error)
+	LoadGatewayState() (models.GatewayState, bool, error)
+	SaveGatewayState(models.GatewayState) error
 }
 
 type Gateway struct {
 	mu               sync.Mutex
 	logger           *slog.Logger
 	store            SettingsStore
+	clock            clock.Clock
 	config           Config
 	currentBudget    map[models.Mode]float64
 	lastIntervention time.Time
@@ -51,29 +75,58 @@ type Gateway struct {
 	hourlyUsed       float64
 	dayBucket        string
 	hourBucket       string
-	usageLoaded      bool
+	stateLoaded      bool
+	actionUsage      map[models.ActionType]models.ActionUsage
+	adaptation       Adaptation
+	schedule         string
+	rules            ruleState
+	chain            []chainEntry
 }
 
-func New(logger *slog.Logger, store SettingsStore) *Gateway {
+func New(logger *slog.Logger, store SettingsStore, opts ...Option) *Gateway {
 	cfg := Config{
 		ModeBudgets:     defaultModeBudgets(),
-		RecoveryRate:    0.5, // Recover 1 point every 2 mins
-		CooldownSeconds: 300, // 5 minutes cooldown
-	}
-	now := time.Now()
-	current := map[models.Mode]float64{}
-	lastUpdate := map[models.Mode]time.Time{}
-	for mode, max := range cfg.ModeBudgets {
-		current[mode] = max
-		lastUpdate[mode] = now
+		RecoveryRate:    defaultRecoveryRate,
+		CooldownSeconds: defaultCooldownSeconds,
+		ActionLimits:    DefaultActionLimits(),
 	}
-	return &Gateway{
+	g := &Gateway{
 		logger:        logger,
 		store:         store,
+		clock:         clock.System,
 		config:        cfg,
-		currentBudget: current,
-		lastUpdate:    lastUpdate,
+		currentBudget: map[models.Mode]float64{},
+		lastUpdate:    map[models.Mode]time.Time{},
+		actionUsage:   map[models.ActionType]models.ActionUsage{},
+		rules:         newRuleState(),
+		chain:         defaultChain(),
 	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	now := g.clock.Now()
+	for mode, max := range cfg.ModeBudgets {
+		g.currentBudget[mode] = max
+		g.lastUpdate[mode] = now
+	}
+	g.mu.Lock()
+	g.refreshConfigLocked()
+	g.loadStateLocked(now)
+	g.mu.Unlock()
+	return g
+}
+
+// SetClock replaces the time source, e.g. with a virtual clock for replays.
+// Budgets restart full as of the new clock's time.
+func (g *Gateway) SetClock(c clock.Clock) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.clock = c
+	now := c.Now()
+	for mode := range g.lastUpdate {
+		g.lastUpdate[mode] = now
+	}
+	g.lastIntervention = time.Time{}
 }
 
 func defaultModeBudgets() map[models.Mode]float64 {
@@ -87,10 +140,8 @@ func defaultModeBudgets() map[models.Mode]float64 {
 func (g *Gateway) refreshConfigLocked() {
 	cfg := Config{
 		ModeBudgets:     defaultModeBudgets(),
-		RecoveryRate:    g.config.RecoveryRate,
-		CooldownSeconds: g.config.CooldownSeconds,
-		HourlyCap:       g.config.HourlyCap,
-		DailyCap:        g.config.DailyCap,
+		RecoveryRate:    defaultRecoveryRate,
+		CooldownSeconds: defaultCooldownSeconds,
 	}
 
 	if g.store != nil {
@@ -129,7 +180,18 @@ func (g *Gateway) refreshConfigLocked() {
 		}
 	}
 
+	g.schedule = g.applyScheduleLocked(&cfg, g.clock.Now())
+	g.loadActionLimitsLocked(&cfg)
+	g.loadLadderLocked(&cfg)
+
+	g.adaptation = g.refreshAdaptationLocked(g.clock.Now())
+	for mode := range cfg.ModeBudgets {
+		cfg.ModeBudgets[mode] *= g.adaptation.BudgetFactor
+	}
+	cfg.RecoveryRate *= g.adaptation.RecoveryFactor
+
 	g.config = cfg
+	g.refreshRulesLocked()
 	for mode, maxBudget := range g.config.ModeBudgets {
 		if _, ok := g.currentBudget[mode]; !ok {
 			g.currentBudget[mode] = maxBudget
@@ -138,7 +200,7 @@ func (g *Gateway) refreshConfigLocked() {
 			g.currentBudget[mode] = maxBudget
 		}
 		if _, ok := g.lastUpdate[mode]; !ok {
-			g.lastUpdate[mode] = time.Now()
+			g.lastUpdate[mode] = g.clock.Now()
 		}
 	}
 }
@@ -164,22 +226,6 @@ func parseFloatSetting(value string) (float64, bool) {
 	return parsed, true
 }
 
-func (g *Gateway) loadUsageLocked(now time.Time) {
-	if g.store != nil && !g.usageLoaded {
-		usage, err := g.store.GetBudgetUsage()
-		if err == nil {
-			g.dailyUsed = usage.DailyUsed
-			g.hourlyUsed = usage.HourlyUsed
-			g.dayBucket = usage.DailyDay
-			g.hourBucket = usage.HourlyHour
-			g.usageLoaded = true
-		} else {
-			g.logger.Warn("load budget usage failed", slog.Any("error", err))
-		}
-	}
-	g.resetUsageBucketsLocked(now)
-}
-
 func (g *Gateway) resetUsageBucketsLocked(now time.Time) {
 	currentDay := now.Format("2006-01-02")
 	currentHour := now.Format("2006-01-02-15")
@@ -196,22 +242,7 @@ func (g *Gateway) resetUsageBucketsLocked(now time.Time) {
 		changed = true
 	}
 	if changed {
-		g.persistUsageLocked()
-	}
-}
-
-func (g *Gateway) persistUsageLocked() {
-	if g.store == nil {
-		return
-	}
-	usage := models.BudgetUsage{
-		DailyUsed:  g.dailyUsed,
-		DailyDay:   g.dayBucket,
-		HourlyUsed: g.hourlyUsed,
-		HourlyHour: g.hourBucket,
-	}
-	if err := g.store.SetBudgetUsage(usage); err != nil {
-		g.logger.Warn("persist budget usage failed", slog.Any("error", err))
+		g.persistStateLocked()
 	}
 }
 
@@ -225,89 +256,304 @@ func (g *Gateway) modeMaxBudget(mode models.Mode) float64 {
 func (g *Gateway) Evaluate(ctx models.Context, action models.Action) (models.Action, models.GatewayDecision) {
 	g.mu.Lock()
 	defer g.mu.Unlock()
+	return g.evaluateLocked(ctx, action, true)
+}
+
+// DryRun evaluates an action like Evaluate but consumes no budget and starts
+// no cooldown.
+func (g *Gateway) DryRun(ctx models.Context, action models.Action) (models.Action, models.GatewayDecision) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.evaluateLocked(ctx, action, false)
+}
 
-	now := time.Now()
+func (g *Gateway) evaluateLocked(ctx models.Context, action models.Action, apply bool) (models.Action, models.GatewayDecision) {
+	now := g.clock.Now()
 	g.refreshConfigLocked()
-	g.loadUsageLocked(now)
+	g.loadStateLocked(now)
 	g.replenishBudgetLocked(ctx.Mode, now)
 
 	original := action
 	decision := models.GatewayDecision{Decision: models.GatewayAllow, Reason: "allow"}
-
-	// 1. Static Rules (Stateless)
-	if reason, invalid := ruleInvalidAction(action); invalid {
-		return overrideAction(original, models.GatewayOverride, reason)
-	}
-	if ruleHighRisk(action) {
-		return overrideAction(original, models.GatewayDeny, ReasonHighRiskBlocked)
-	}
-	if ruleLowQuality(action) {
-		return overrideAction(original, models.GatewayOverride, ReasonLowQualityAction)
-	}
-	if ruleSilentOverride(ctx, action) {
-		return overrideAction(original, models.GatewayOverride, ReasonModeSilentOverride)
+	var trace traceBuilder
+	if g.schedule != "" {
+		trace.add("schedule", TraceMatch, g.schedule, map[string]float64{
+			"budget_max":          g.modeMaxBudget(ctx.Mode),
+			"recovery_per_minute": g.config.RecoveryRate,
+			"cooldown_seconds":    g.config.CooldownSeconds,
+		})
+	}
+
+	// 1. Rule chain
+	multipliers, blockedBy := g.matchRulesLocked(ctx, action, now, &trace, true)
+	if blockedBy != nil {
+		decisionType := models.GatewayOverride
+		if blockedBy.Effect == EffectDeny {
+			decisionType = models.GatewayDeny
+		}
+		return trace.attach(ruleOverride(original, decisionType, *blockedBy))
 	}
 
 	// 2. Dynamic Rules (Stateful) - Only check if action is NOT DoNotDisturb
-	if action.ActionType != models.ActionDoNotDisturb {
-		cost := calculateCost(action)
-
-		// Check Cooldown
-		if g.config.CooldownSeconds > 0 && time.Since(g.lastIntervention).Seconds() < g.config.CooldownSeconds {
-			g.logger.Info("gateway cooldown active",
-				slog.Float64("since_last", time.Since(g.lastIntervention).Seconds()),
-				slog.Float64("cooldown", g.config.CooldownSeconds))
-			return overrideAction(original, models.GatewayOverride, ReasonCooldownActive)
+	if action.ActionType == models.ActionDoNotDisturb {
+		trace.add("budget", TraceSkip, "do_not_disturb", nil)
+		decision.Trace = trace.steps
+		return action, decision
+	}
+
+	// Walk down the ladder from the rung feedback chose until a candidate fits
+	candidates, level := g.ladderCandidates(action)
+	var firstPricing *models.GatewayPricing
+	var stepReason string
+	for i := level; i < len(candidates); i++ {
+		candidate := candidates[i]
+		if i > 0 {
+			reason := stepReason
+			if reason == "" {
+				reason = ReasonLadderFeedback
+			}
+			traceLadderStep(&trace, candidate, reason, i)
+			var ruleBlock *Verdict
+			multipliers, ruleBlock = g.matchRulesLocked(ctx, candidate, now, &trace, false)
+			if ruleBlock != nil {
+				if stepReason == "" {
+					stepReason = ruleBlock.Reason
+				}
+				continue
+			}
 		}
-
-		// Check Budget Caps
-		if g.config.HourlyCap > 0 && g.hourlyUsed+cost > g.config.HourlyCap {
-			g.logger.Info("gateway hourly cap reached",
-				slog.Float64("used", g.hourlyUsed),
-				slog.Float64("cap", g.config.HourlyCap))
-			return overrideAction(original, models.GatewayOverride, ReasonBudgetExhausted)
+		pricing := priceAction(candidate, multipliers)
+		if firstPricing == nil {
+			firstPricing = &pricing
 		}
-		if g.config.DailyCap > 0 && g.dailyUsed+cost > g.config.DailyCap {
-			g.logger.Info("gateway daily cap reached",
-				slog.Float64("used", g.dailyUsed),
-				slog.Float64("cap", g.config.DailyCap))
-			return overrideAction(original, models.GatewayOverride, ReasonBudgetExhausted)
+		reason, cost := g.admitLocked(ctx, candidate, pricing, now, &trace)
+		if reason != "" {
+			if stepReason == "" {
+				stepReason = reason
+			}
+			continue
 		}
 
-		// Check Budget (per mode)
-		if g.currentBudget[ctx.Mode] < cost {
-			g.logger.Info("gateway budget exhausted",
-				slog.Float64("current", g.currentBudget[ctx.Mode]),
-				slog.Float64("cost", cost))
-			return overrideAction(original, models.GatewayOverride, ReasonBudgetExhausted)
+		decision.Pricing = &pricing
+		if i > 0 {
+			decision.Decision = models.GatewayDegrade
+			decision.Reason = ReasonLadderFeedback
+			if stepReason != "" {
+				decision.Reason = stepReason
+			}
+			decision.OverriddenActionType = original.ActionType
+		}
+		decision.Trace = trace.steps
+		if !apply {
+			return candidate, decision
 		}
 
 		// Apply Cost
 		g.currentBudget[ctx.Mode] -= cost
-		g.lastIntervention = now
+		if candidate.ActionType != models.ActionAmbient {
+			g.lastIntervention = now
+		}
 		g.hourlyUsed += cost
 		g.dailyUsed += cost
-		g.persistUsageLocked()
+		g.recordActionLocked(candidate.ActionType, now)
+		g.persistStateLocked()
 		g.logger.Info("gateway intervention allowed",
+			slog.String("action_type", string(candidate.ActionType)),
 			slog.Float64("cost", cost),
 			slog.Float64("remaining", g.currentBudget[ctx.Mode]))
+		return candidate, decision
 	}
 
-	return action, decision
+	final, blocked := overrideAction(original, models.GatewayOverride, stepReason)
+	blocked.Pricing = firstPricing
+	return trace.attach(final, blocked)
 }
 
-func (g *Gateway) CanIntervene(ctx models.Context, cost float64) (bool, string) {
+// matchRulesLocked runs the rule chain for one action. It returns the cost
+// multipliers that matched, or the verdict of the first deny or override.
+// Candidates further down the ladder only trace the rules that match.
+func (g *Gateway) matchRulesLocked(ctx models.Context, action models.Action, now time.Time, trace *traceBuilder, traceAll bool) ([]models.CostMultiplier, *Verdict) {
+	state := g.ruleStateLocked(ctx.Mode, action.ActionType, now)
+	var multipliers []models.CostMultiplier
+	for _, entry := range g.expandChainLocked() {
+		verdict := entry.rule.Evaluate(ctx, action, state)
+		if verdict.Reason == "" {
+			verdict.Reason = entry.rule.Name()
+		}
+		switch {
+		case verdict.Effect == EffectDeny || verdict.Effect == EffectOverride:
+			trace.add(entry.name, TraceBlock, verdict.Reason, nil)
+			return nil, &verdict
+		case verdict.Effect == EffectCostMultiplier && verdict.Multiplier > 0:
+			trace.add(entry.name, TraceMatch, "", map[string]float64{"multiplier": verdict.Multiplier})
+			multipliers = append(multipliers, models.CostMultiplier{Rule: entry.rule.Name(), Factor: verdict.Multiplier})
+		case traceAll:
+			outcome := TracePass
+			if _, declarative := entry.rule.(specRule); declarative {
+				outcome = TraceNoMatch
+			}
+			trace.add(entry.name, outcome, "", nil)
+		}
+	}
+	return multipliers, nil
+}
+
+// expandChainLocked lists the chain with the RuleConfig entry replaced by the
+// active declarative rules, each traced as rule:<name>.
+func (g *Gateway) expandChainLocked() []chainEntry {
+	expanded := make([]chainEntry, 0, len(g.chain)+len(g.rules.set.Rules))
+	for _, entry := range g.chain {
+		if entry.rule != nil {
+			expanded = append(expanded, entry)
+			continue
+		}
+		for _, spec := range g.rules.set.Rules {
+			expanded = append(expanded, chainEntry{name: "rule:" + spec.Name, rule: specRule{spec: spec}})
+		}
+	}
+	return expanded
+}
+
+// admitLocked runs the cooldown, limit, cap and budget checks for one priced
+// action. It returns the reason the action is blocked, or "" and its cost.
+func (g *Gateway) admitLocked(ctx models.Context, action models.Action, pricing models.GatewayPricing, now time.Time, trace *traceBuilder) (string, float64) {
+	cost := pricing.Cost
+	trace.add("pricing", TracePass, "", map[string]float64{
+		"table_cost":        pricing.TableCost,
+		"reported_cost":     pricing.ReportedCost,
+		"confidence_factor": pricing.ConfidenceFactor,
+		"cost":              cost,
+	})
+
+	// Check Cooldown; an action type with its own cooldown waits for both, and
+	// AMBIENT interrupts nothing
+	cooldown := time.Duration(g.config.CooldownSeconds * float64(time.Second))
+	cooldownInputs := map[string]float64{
+		"cooldown_seconds":  g.config.CooldownSeconds,
+		"remaining_seconds": remainingSeconds(g.lastIntervention, cooldown, now),
+	}
+	switch {
+	case action.ActionType == models.ActionAmbient:
+		trace.add("cooldown", TraceSkip, "ambient", cooldownInputs)
+	case g.config.CooldownSeconds > 0 && now.Sub(g.lastIntervention).Seconds() < g.config.CooldownSeconds:
+		g.logger.Info("gateway cooldown active",
+			slog.Float64("since_last", now.Sub(g.lastIntervention).Seconds()),
+			slog.Float64("cooldown", g.config.CooldownSeconds))
+		trace.add("cooldown", TraceBlock, ReasonCooldownActive, cooldownInputs)
+		return ReasonCooldownActive, cost
+	default:
+		trace.add("cooldown", TracePass, "", cooldownInputs)
+	}
+	limitInputs := g.actionLimitInputsLocked(action.ActionType, now)
+	if reason, blocked := g.checkActionLimitLocked(action.ActionType, now); blocked {
+		trace.add("action_limit", TraceBlock, reason, limitInputs)
+		return reason, cost
+	}
+	trace.add("action_limit", TracePass, "", limitInputs)
+
+	// Check Budget Caps
+	hourlyInputs := map[string]float64{"used": g.hourlyUsed, "cap": g.config.HourlyCap, "cost": cost}
+	if g.config.HourlyCap > 0 && g.hourlyUsed+cost > g.config.HourlyCap {
+		g.logger.Info("gateway hourly cap reached",
+			slog.Float64("used", g.hourlyUsed),
+			slog.Float64("cap", g.config.HourlyCap))
+		trace.add("hourly_cap", TraceBlock, ReasonBudgetExhausted, hourlyInputs)
+		return ReasonBudgetExhausted, cost
+	}
+	trace.add("hourly_cap", TracePass, "", hourlyInputs)
+	dailyInputs := map[string]float64{"used": g.dailyUsed, "cap": g.config.DailyCap, "cost": cost}
+	if g.config.DailyCap > 0 && g.dailyUsed+cost > g.config.DailyCap {
+		g.logger.Info("gateway daily cap reached",
+			slog.Float64("used", g.dailyUsed),
+			slog.Float64("cap", g.config.DailyCap))
+		trace.add("daily_cap", TraceBlock, ReasonBudgetExhausted, dailyInputs)
+		return ReasonBudgetExhausted, cost
+	}
+	trace.add("daily_cap", TracePass, "", dailyInputs)
+
+	// Check Budget (per mode)
+	budgetBefore := g.currentBudget[ctx.Mode]
+	budgetInputs := map[string]float64{
+		"budget_before": budgetBefore,
+		"budget_max":    g.modeMaxBudget(ctx.Mode),
+		"cost":          cost,
+	}
+	if budgetBefore < cost {
+		g.logger.Info("gateway budget exhausted",
+			slog.Float64("current", budgetBefore),
+			slog.Float64("cost", cost))
+		budgetInputs["budget_after"] = budgetBefore
+		trace.add("mode_budget", TraceBlock, ReasonBudgetExhausted, budgetInputs)
+		return ReasonBudgetExhausted, cost
+	}
+	budgetInputs["budget_after"] = budgetBefore - cost
+	trace.add("mode_budget", TracePass, "", budgetInputs)
+	return "", cost
+}
+
+// MaxCost bounds what an intervention of one of actionTypes can cost in ctx
+// now, for CanIntervene ahead of the policy call. Each type is priced at the
+// blended cap with no confidence under the cost multipliers of the rules it
+// matches; types the rules block are skipped, and the cheapest bound is
+// returned since one affordable type is enough to intervene.
+func (g *Gateway) MaxCost(ctx models.Context, actionTypes ...models.ActionType) float64 {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
-	now := time.Now()
+	now := g.clock.Now()
 	g.refreshConfigLocked()
-	g.loadUsageLocked(now)
+	g.loadStateLocked(now)
 	g.replenishBudgetLocked(ctx.Mode, now)
 
-	if g.config.CooldownSeconds > 0 && time.Since(g.lastIntervention).Seconds() < g.config.CooldownSeconds {
+	bound := -1.0
+	for _, actionType := range actionTypes {
+		probe := models.Action{ActionType: actionType, Message: string(actionType), Confidence: 1, RiskLevel: models.RiskLow}
+		var trace traceBuilder
+		multipliers, blockedBy := g.matchRulesLocked(ctx, probe, now, &trace, false)
+		if blockedBy != nil {
+			continue
+		}
+		if cost := maxPricedCost(multipliers); bound < 0 || cost < bound {
+			bound = cost
+		}
+	}
+	if bound < 0 {
+		return maxPricedCost(nil)
+	}
+	return bound
+}
+
+// CanIntervene reports whether an intervention costing cost may fire now. With
+// actionTypes it also requires at least one of them to be clear of its own
+// cooldown and daily limit; otherwise the first one's reason is returned.
+func (g *Gateway) CanIntervene(ctx models.Context, cost float64, actionTypes ...models.ActionType) (bool, string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := g.clock.Now()
+	g.refreshConfigLocked()
+	g.loadStateLocked(now)
+	g.replenishBudgetLocked(ctx.Mode, now)
+
+	if g.config.CooldownSeconds > 0 && now.Sub(g.lastIntervention).Seconds() < g.config.CooldownSeconds {
 		return false, ReasonCooldownActive
 	}
+	if len(actionTypes) > 0 {
+		var limitReason string
+		for _, actionType := range actionTypes {
+			reason, blocked := g.checkActionLimitLocked(actionType, now)
+			if !blocked {
+				limitReason = ""
+				break
+			}
+			if limitReason == "" {
+				limitReason = reason
+			}
+		}
+		if limitReason != "" {
+			return false, limitReason
+		}
+	}
 	if g.config.HourlyCap > 0 && g.hourlyUsed+cost > g.config.HourlyCap {
 		return false, ReasonBudgetExhausted
 	}
@@ -320,10 +566,6 @@ func (g *Gateway) CanIntervene(ctx models.Context, cost float64) (bool, string)
 	return true, "allow"
 }
 
-func MaxActionCost() float64 {
-	return 3.0
-}
-
 func (g *Gateway) replenishBudgetLocked(mode models.Mode, now time.Time) {
 	lastUpdate, ok := g.lastUpdate[mode]
 	if !ok {
@@ -345,33 +587,22 @@ func (g *Gateway) replenishBudgetLocked(mode models.Mode, now time.Time) {
 	g.lastUpdate[mode] = now
 }
 
-// ClearCooldown resets the cooldown timer to allow immediate interaction
-func (g *Gateway) ClearCooldown() {
+// ClearCooldown resets the cooldown timer to allow immediate interaction,
+// along with the per-type cooldowns of actionTypes.
+func (g *Gateway) ClearCooldown(actionTypes ...models.ActionType) {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
 	// Set lastIntervention to a time in the past to bypass cooldown
-	g.lastIntervention = time.Now().Add(-time.Duration(g.config.CooldownSeconds+1) * time.Second)
-	g.logger.Info("gateway cooldown cleared, interaction enabled")
-}
-
-func calculateCost(action models.Action) float64 {
-	// Base cost by action type
-	// Can be enhanced to use action.Cost from AI if reliable
-	switch action.ActionType {
-	case models.ActionDoNotDisturb:
-		return 0
-	case models.ActionRestReminder:
-		return 2.0
-	case models.ActionEncourage:
-		return 1.5
-	case models.ActionTaskBreakdown:
-		return 3.0
-	case models.ActionReframe:
-		return 2.5
-	default:
-		return 1.0
+	g.lastIntervention = g.clock.Now().Add(-time.Duration(g.config.CooldownSeconds+1) * time.Second)
+	for _, actionType := range actionTypes {
+		if usage, ok := g.actionUsage[actionType]; ok {
+			usage.LastMs = 0
+			g.actionUsage[actionType] = usage
+		}
 	}
+	g.persistStateLocked()
+	g.logger.Info("gateway cooldown cleared, interaction enabled")
 }
 
 func overrideAction(original models.Action, decisionType models.GatewayDecisionType, reason string) (models.Action, models.GatewayDecision) {
@@ -392,6 +623,14 @@ func overrideAction(original models.Action, decisionType models.GatewayDecisionT
 	return final, decision
 }
 
+func ruleOverride(original models.Action, decisionType models.GatewayDecisionType, verdict Verdict) (models.Action, models.GatewayDecision) {
+	final, decision := overrideAction(original, decisionType, verdict.Reason)
+	if verdict.Message != "" {
+		final.Message = verdict.Message
+	}
+	return final, decision
+}
+
 func defaultOverrideMessage(reason string) string {
 	switch reason {
 	case ReasonModeSilentOverride:
@@ -406,6 +645,12 @@ func defaultOverrideMessage(reason string) string {
 		return "干预预算不足，已降级为勿扰模式。"
 	case ReasonCooldownActive:
 		return "处于冷却期，已降级为勿扰模式。"
+	}
+	switch {
+	case strings.HasPrefix(reason, "cooldown_"):
+		return "同类提示处于冷却期，已降级为勿扰模式。"
+	case strings.HasPrefix(reason, "daily_limit_"):
+		return "同类提示今日次数已用完，已降级为勿扰模式。"
 	default:
 		return "已降级为勿扰模式。"
 	}
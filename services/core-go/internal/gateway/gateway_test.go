@@ -0,0 +1,55 @@
+package gateway
+
+import (
+	"io"
+	"log/slog"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"always/core/internal/clock"
+	"always/core/internal/db"
+	"always/core/internal/models"
+)
+
+var testStart = time.Date(2026, 10, 12, 10, 0, 0, 0, time.Local)
+
+// newTestGateway returns a gateway on a fresh store holding settings, with a
+// virtual clock starting at testStart.
+func newTestGateway(t *testing.T, settings map[string]string, opts ...Option) (*Gateway, *db.Store, *clock.Virtual) {
+	t.Helper()
+	store, err := db.Open(filepath.Join(t.TempDir(), "always.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { store.DB().Close() })
+	for key, value := range settings {
+		if err := store.UpsertSetting(key, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	clk := clock.NewVirtual(testStart)
+	return reopenGateway(store, clk, opts...), store, clk
+}
+
+// reopenGateway starts another gateway on store, as after a restart.
+func reopenGateway(store SettingsStore, clk clock.Clock, opts ...Option) *Gateway {
+	opts = append([]Option{WithClock(clk)}, opts...)
+	return New(slog.New(slog.NewTextHandler(io.Discard, nil)), store, opts...)
+}
+
+func testContext(mode models.Mode, signals map[string]string) models.Context {
+	return models.Context{Mode: mode, Signals: signals}
+}
+
+func testAction(actionType models.ActionType) models.Action {
+	return models.Action{
+		ActionType: actionType,
+		Message:    "keep going",
+		Confidence: 0.9,
+		RiskLevel:  models.RiskLow,
+	}
+}
+
+func approxEqual(a, b float64) bool { return math.Abs(a-b) < 1e-6 }
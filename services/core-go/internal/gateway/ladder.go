@@ -0,0 +1,150 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"always/core/internal/models"
+)
+
+const (
+	// SettingGatewayLadder holds a JSON object that replaces DefaultLadder.
+	SettingGatewayLadder         = "gateway_ladder"
+	settingLadderIgnoreThreshold = "ladder_ignore_threshold"
+	defaultLadderIgnoreThreshold = 3
+	ReasonLadderFeedback         = "ladder_feedback"
+)
+
+// Ladder lists, for each action type, the cheaper action types it may step
+// down to, most intrusive first. DO_NOT_DISTURB is the implicit last rung.
+type Ladder map[models.ActionType][]models.ActionType
+
+func DefaultLadder() Ladder {
+	return Ladder{
+		models.ActionTaskBreakdown: {models.ActionEncourage, models.ActionAmbient},
+		models.ActionReframe:       {models.ActionEncourage, models.ActionAmbient},
+		models.ActionRestReminder:  {models.ActionAmbient},
+		models.ActionEncourage:     {models.ActionAmbient},
+	}
+}
+
+// ParseLadder decodes and validates a JSON ladder such as
+// {"TASK_BREAKDOWN": ["ENCOURAGE", "AMBIENT"]}.
+func ParseLadder(raw string) (Ladder, error) {
+	var ladder Ladder
+	decoder := json.NewDecoder(strings.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&ladder); err != nil {
+		return nil, fmt.Errorf("decode ladder: %w", err)
+	}
+	if ladder == nil {
+		return nil, fmt.Errorf("ladder must be a json object")
+	}
+	for actionType, steps := range ladder {
+		if !isValidActionType(actionType) || actionType == models.ActionDoNotDisturb {
+			return nil, fmt.Errorf("invalid ladder action type %q", actionType)
+		}
+		seen := map[models.ActionType]bool{actionType: true}
+		for _, step := range steps {
+			if !isValidActionType(step) || step == models.ActionDoNotDisturb {
+				return nil, fmt.Errorf("invalid step %q for %s", step, actionType)
+			}
+			if seen[step] {
+				return nil, fmt.Errorf("step %s repeats in the ladder of %s", step, actionType)
+			}
+			seen[step] = true
+		}
+	}
+	return ladder, nil
+}
+
+func (g *Gateway) loadLadderLocked(cfg *Config) {
+	cfg.Ladder = DefaultLadder()
+	cfg.LadderIgnoreThreshold = defaultLadderIgnoreThreshold
+	if g.store == nil {
+		return
+	}
+	if value, ok, err := g.store.GetSetting(SettingGatewayLadder); err == nil && ok && strings.TrimSpace(value) != "" {
+		ladder, err := ParseLadder(value)
+		if err != nil {
+			g.logger.Warn("invalid gateway ladder, using default", slog.Any("error", err))
+		} else {
+			cfg.Ladder = ladder
+		}
+	}
+	if value, ok, err := g.store.GetSetting(settingLadderIgnoreThreshold); err == nil && ok {
+		if parsed, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && parsed > 0 {
+			cfg.LadderIgnoreThreshold = parsed
+		}
+	}
+}
+
+// ladderCandidates returns the proposed action followed by its ladder steps
+// and the rung feedback says to start from.
+func (g *Gateway) ladderCandidates(action models.Action) ([]models.Action, int) {
+	steps := g.config.Ladder[action.ActionType]
+	candidates := make([]models.Action, 0, len(steps)+1)
+	candidates = append(candidates, action)
+	for _, step := range steps {
+		candidates = append(candidates, degradeAction(action, step))
+	}
+	level := min(max(g.actionUsage[action.ActionType].LadderLevel, 0), len(steps))
+	return candidates, level
+}
+
+// degradeAction keeps the message of the proposed action but drops its
+// reported cost, which was estimated for the original type.
+func degradeAction(original models.Action, actionType models.ActionType) models.Action {
+	degraded := original
+	degraded.ActionType = actionType
+	degraded.Cost = 0
+	return degraded
+}
+
+// ApplyFeedback moves an action type along its ladder: a run of IGNORED
+// feedback steps it one rung down, ADOPTED or LIKE steps it one rung back up.
+func (g *Gateway) ApplyFeedback(actionType models.ActionType, feedback models.FeedbackType) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := g.clock.Now()
+	g.refreshConfigLocked()
+	g.loadStateLocked(now)
+
+	steps := len(g.config.Ladder[actionType])
+	usage := g.actionUsage[actionType]
+	if steps == 0 && usage.LadderLevel == 0 {
+		return
+	}
+	usage.ActionType = actionType
+	switch feedback {
+	case models.FeedbackAdopted, models.FeedbackLike:
+		usage.IgnoredStreak = 0
+		usage.LadderLevel = max(min(usage.LadderLevel, steps)-1, 0)
+	case models.FeedbackIgnored:
+		usage.IgnoredStreak++
+		if usage.IgnoredStreak >= g.config.LadderIgnoreThreshold {
+			usage.IgnoredStreak = 0
+			usage.LadderLevel = min(usage.LadderLevel+1, steps)
+		}
+	default:
+		return
+	}
+	g.actionUsage[actionType] = usage
+	g.persistStateLocked()
+	g.logger.Info("gateway ladder feedback",
+		slog.String("action_type", string(actionType)),
+		slog.String("feedback", string(feedback)),
+		slog.Int("ladder_level", usage.LadderLevel),
+		slog.Int("ignored_streak", usage.IgnoredStreak))
+}
+
+// traceLadderStep records why the ladder moves to a candidate before its
+// checks run.
+func traceLadderStep(trace *traceBuilder, candidate models.Action, reason string, level int) {
+	inputs := map[string]float64{"level": float64(level), "table_cost": tableCost(candidate.ActionType)}
+	trace.add("ladder:"+string(candidate.ActionType), TraceMatch, reason, inputs)
+}
@@ -0,0 +1,102 @@
+package gateway
+
+import (
+	"testing"
+	"time"
+
+	"always/core/internal/models"
+)
+
+func TestParseLadder(t *testing.T) {
+	tests := []struct {
+		raw string
+		ok  bool
+	}{
+		{raw: `{"TASK_BREAKDOWN": ["ENCOURAGE", "AMBIENT"]}`, ok: true},
+		{raw: `{"ENCOURAGE": []}`, ok: true},
+		{raw: `null`},
+		{raw: `[]`},
+		{raw: `{"DO_NOT_DISTURB": ["AMBIENT"]}`},
+		{raw: `{"ENCOURAGE": ["DO_NOT_DISTURB"]}`},
+		{raw: `{"ENCOURAGE": ["SHOUT"]}`},
+		{raw: `{"ENCOURAGE": ["ENCOURAGE"]}`},
+		{raw: `{"REFRAME": ["AMBIENT", "AMBIENT"]}`},
+	}
+	for _, tt := range tests {
+		if _, err := ParseLadder(tt.raw); (err == nil) != tt.ok {
+			t.Errorf("ParseLadder(%s) = %v, ok %v", tt.raw, err, tt.ok)
+		}
+	}
+}
+
+func TestLadderDegradesBlockedActions(t *testing.T) {
+	g, _, clk := newTestGateway(t, map[string]string{
+		"daily_limit_task_breakdown": "1",
+		SettingGatewayLadder:         `{"TASK_BREAKDOWN": ["REFRAME", "AMBIENT"]}`,
+	})
+	ctx := testContext(models.ModeActive, nil)
+	g.Evaluate(ctx, testAction(models.ActionTaskBreakdown))
+
+	clk.Advance(10 * time.Minute)
+	final, decision := g.Evaluate(ctx, testAction(models.ActionTaskBreakdown))
+	if final.ActionType != models.ActionReframe || final.Message != "keep going" || final.Cost != 0 {
+		t.Fatalf("degraded action = %+v", final)
+	}
+	if decision.Decision != models.GatewayDegrade || decision.Reason != ActionLimitReason(models.ActionTaskBreakdown) ||
+		decision.OverriddenActionType != models.ActionTaskBreakdown {
+		t.Fatalf("decision = %+v", decision)
+	}
+
+	// AMBIENT neither waits for nor starts the shared cooldown.
+	clk.Advance(time.Minute)
+	final, decision = g.Evaluate(ctx, testAction(models.ActionTaskBreakdown))
+	if final.ActionType != models.ActionAmbient || decision.Reason != ReasonCooldownActive {
+		t.Fatalf("second degrade = %s %+v", final.ActionType, decision)
+	}
+	if remaining := g.State().Cooldown.RemainingSeconds; remaining != 240 {
+		t.Fatalf("ambient restarted the cooldown: %v left", remaining)
+	}
+
+	// With no rung left the action becomes DO_NOT_DISTURB.
+	g2, _, _ := newTestGateway(t, map[string]string{"budget_active": "0"})
+	final, decision = g2.Evaluate(ctx, testAction(models.ActionEncourage))
+	if final.ActionType != models.ActionDoNotDisturb || decision.Decision != models.GatewayOverride || decision.Reason != ReasonBudgetExhausted {
+		t.Fatalf("exhausted = %s %+v", final.ActionType, decision)
+	}
+}
+
+func TestLadderFollowsFeedback(t *testing.T) {
+	g, _, _ := newTestGateway(t, map[string]string{settingLadderIgnoreThreshold: "2"})
+	ctx := testContext(models.ModeActive, nil)
+	level := func() int { return g.State().Actions[models.ActionTaskBreakdown].LadderLevel }
+
+	g.ApplyFeedback(models.ActionTaskBreakdown, models.FeedbackIgnored)
+	if level() != 0 {
+		t.Fatalf("one ignore moved the ladder to %d", level())
+	}
+	g.ApplyFeedback(models.ActionTaskBreakdown, models.FeedbackIgnored)
+	if level() != 1 {
+		t.Fatalf("level = %d after two ignores", level())
+	}
+	final, decision := g.DryRun(ctx, testAction(models.ActionTaskBreakdown))
+	if final.ActionType != models.ActionEncourage || decision.Reason != ReasonLadderFeedback {
+		t.Fatalf("start rung = %s %+v", final.ActionType, decision)
+	}
+
+	for range 6 {
+		g.ApplyFeedback(models.ActionTaskBreakdown, models.FeedbackIgnored)
+	}
+	if level() != 2 {
+		t.Fatalf("level = %d, want the last rung", level())
+	}
+	g.ApplyFeedback(models.ActionTaskBreakdown, models.FeedbackAdopted)
+	g.ApplyFeedback(models.ActionTaskBreakdown, models.FeedbackDislike)
+	if level() != 1 {
+		t.Fatalf("level = %d after adopted", level())
+	}
+	// A type without a ladder has nothing to move.
+	g.ApplyFeedback(models.ActionAmbient, models.FeedbackIgnored)
+	if _, ok := g.State().Actions[models.ActionAmbient]; ok {
+		t.Fatal("ambient gained ladder state")
+	}
+}
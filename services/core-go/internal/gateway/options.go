@@ -0,0 +1,124 @@
+package gateway
+
+import (
+	"log/slog"
+	"slices"
+
+	"always/core/internal/clock"
+)
+
+// Option customizes a Gateway at construction.
+type Option func(*Gateway)
+
+// chainEntry is one step of the rule chain; the RuleConfig entry has no Rule
+// of its own and expands to the active declarative rules.
+type chainEntry struct {
+	name string
+	rule Rule
+}
+
+func defaultChain() []chainEntry {
+	return []chainEntry{
+		{name: RuleValidAction, rule: validActionRule{}},
+		{name: RuleHighRisk, rule: highRiskRule{}},
+		{name: RuleLowQuality, rule: lowQualityRule{}},
+		{name: RuleSilentMode, rule: silentModeRule{}},
+		{name: RuleConfig},
+	}
+}
+
+// WithClock makes the gateway read time from c from the start, so the saved
+// state is restored as of c's time; SetClock only applies afterwards.
+func WithClock(c clock.Clock) Option {
+	return func(g *Gateway) {
+		g.clock = c
+	}
+}
+
+// WithRule appends rule to the end of the chain, after the declarative rules.
+// A rule named like an existing entry replaces it in place.
+func WithRule(rule Rule) Option {
+	return func(g *Gateway) {
+		g.insertRule(rule, len(g.chain))
+	}
+}
+
+// WithRuleBefore inserts rule just before the entry called name, e.g.
+// RuleConfig to run ahead of the declarative rules. An unknown name appends.
+func WithRuleBefore(name string, rule Rule) Option {
+	return func(g *Gateway) {
+		g.insertRule(rule, g.chainIndex(name, len(g.chain)))
+	}
+}
+
+// WithRuleAfter inserts rule just after the entry called name. An unknown
+// name appends.
+func WithRuleAfter(name string, rule Rule) Option {
+	return func(g *Gateway) {
+		index := g.chainIndex(name, len(g.chain)-1)
+		g.insertRule(rule, index+1)
+	}
+}
+
+// WithoutRule removes the entry called name, built-in ones included.
+// Removing RuleConfig disables the declarative rules.
+func WithoutRule(name string) Option {
+	return func(g *Gateway) {
+		index := g.chainIndex(name, -1)
+		if index < 0 {
+			g.logger.Warn("gateway rule not found, nothing removed", slog.String("rule", name))
+			return
+		}
+		g.chain = slices.Delete(g.chain, index, index+1)
+	}
+}
+
+// WithRuleOrder moves the named entries to the front of the chain in the
+// given order; the others follow in their current order.
+func WithRuleOrder(names ...string) Option {
+	return func(g *Gateway) {
+		ordered := make([]chainEntry, 0, len(g.chain))
+		for _, name := range names {
+			index := g.chainIndex(name, -1)
+			if index < 0 {
+				g.logger.Warn("gateway rule not found, not ordered", slog.String("rule", name))
+				continue
+			}
+			ordered = append(ordered, g.chain[index])
+			g.chain = slices.Delete(g.chain, index, index+1)
+		}
+		g.chain = append(ordered, g.chain...)
+	}
+}
+
+func (g *Gateway) ruleChainLocked() []string {
+	names := make([]string, 0, len(g.chain))
+	for _, entry := range g.chain {
+		names = append(names, entry.name)
+	}
+	return names
+}
+
+func (g *Gateway) insertRule(rule Rule, index int) {
+	if rule == nil {
+		return
+	}
+	name := rule.Name()
+	if existing := g.chainIndex(name, -1); existing >= 0 {
+		g.chain[existing] = chainEntry{name: name, rule: rule}
+		return
+	}
+	g.chain = slices.Insert(g.chain, index, chainEntry{name: name, rule: rule})
+}
+
+func (g *Gateway) chainIndex(name string, fallback int) int {
+	for i, entry := range g.chain {
+		if entry.name == name {
+			return i
+		}
+	}
+	if fallback >= 0 {
+		g.logger.Warn("gateway rule not found, appending", slog.String("rule", name))
+	}
+	return fallback
+}
@@ -0,0 +1,86 @@
+package gateway
+
+import (
+	"log/slog"
+	"time"
+
+	"always/core/internal/models"
+)
+
+// loadStateLocked restores the saved state the first time it succeeds, then
+// rolls the hourly and daily buckets over to now.
+func (g *Gateway) loadStateLocked(now time.Time) {
+	if g.store != nil && !g.stateLoaded {
+		state, found, err := g.store.LoadGatewayState()
+		if err != nil {
+			g.logger.Warn("load gateway state failed", slog.Any("error", err))
+		} else {
+			g.restoreStateLocked(state, found, now)
+			g.stateLoaded = true
+		}
+	}
+	g.resetUsageBucketsLocked(now)
+}
+
+// restoreStateLocked applies a saved state. Mode budgets recover for the time
+// the core was down, as if it had kept running.
+func (g *Gateway) restoreStateLocked(state models.GatewayState, found bool, now time.Time) {
+	g.dailyUsed = state.Usage.DailyUsed
+	g.hourlyUsed = state.Usage.HourlyUsed
+	g.dayBucket = state.Usage.DailyDay
+	g.hourBucket = state.Usage.HourlyHour
+	for _, usage := range state.Actions {
+		g.actionUsage[usage.ActionType] = usage
+	}
+	if !found {
+		return
+	}
+	for mode, budget := range state.ModeBudgets {
+		g.currentBudget[mode] = min(budget, g.modeMaxBudget(mode))
+		if ms := state.LastUpdateMs[mode]; ms > 0 && ms <= now.UnixMilli() {
+			g.lastUpdate[mode] = time.UnixMilli(ms)
+		} else {
+			g.lastUpdate[mode] = now
+		}
+		g.replenishBudgetLocked(mode, now)
+	}
+	if state.LastInterventionMs > 0 {
+		g.lastIntervention = time.UnixMilli(state.LastInterventionMs)
+	}
+	g.logger.Info("gateway state restored",
+		slog.Int("version", state.Version),
+		slog.Float64("daily_used", g.dailyUsed),
+		slog.Int64("last_intervention_ms", state.LastInterventionMs))
+}
+
+// persistStateLocked saves the whole state; it never overwrites a saved state
+// that could not be loaded.
+func (g *Gateway) persistStateLocked() {
+	if g.store == nil || !g.stateLoaded {
+		return
+	}
+	state := models.GatewayState{
+		Version:      models.GatewayStateVersion,
+		ModeBudgets:  map[models.Mode]float64{},
+		LastUpdateMs: map[models.Mode]int64{},
+		Usage: models.BudgetUsage{
+			DailyUsed:  g.dailyUsed,
+			DailyDay:   g.dayBucket,
+			HourlyUsed: g.hourlyUsed,
+			HourlyHour: g.hourBucket,
+		},
+	}
+	for mode, budget := range g.currentBudget {
+		state.ModeBudgets[mode] = budget
+		state.LastUpdateMs[mode] = g.lastUpdate[mode].UnixMilli()
+	}
+	if !g.lastIntervention.IsZero() {
+		state.LastInterventionMs = g.lastIntervention.UnixMilli()
+	}
+	for _, usage := range g.actionUsage {
+		state.Actions = append(state.Actions, usage)
+	}
+	if err := g.store.SaveGatewayState(state); err != nil {
+		g.logger.Warn("persist gateway state failed", slog.Any("error", err))
+	}
+}
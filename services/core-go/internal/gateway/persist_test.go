@@ -0,0 +1,60 @@
+package gateway
+
+import (
+	"testing"
+	"time"
+
+	"always/core/internal/clock"
+	"always/core/internal/models"
+)
+
+func TestGatewayStateSurvivesRestart(t *testing.T) {
+	g, store, _ := newTestGateway(t, nil)
+	ctx := testContext(models.ModeActive, nil)
+	_, decision := g.Evaluate(ctx, testAction(models.ActionEncourage))
+	cost := decision.Pricing.Cost
+
+	restarted := reopenGateway(store, clock.NewVirtual(testStart.Add(2*time.Minute))).State()
+	// Two minutes of downtime recover one point.
+	if active := restarted.Budgets[models.ModeActive]; !approxEqual(active.Current, 10-cost+1) {
+		t.Fatalf("active budget after restart = %+v, cost %v", active, cost)
+	}
+	if restarted.Cooldown.RemainingSeconds != 180 || restarted.Cooldown.LastMs != testStart.UnixMilli() {
+		t.Fatalf("cooldown after restart = %+v", restarted.Cooldown)
+	}
+	if !approxEqual(restarted.Daily.Used, cost) || !approxEqual(restarted.Hourly.Used, cost) {
+		t.Fatalf("usage after restart = %+v %+v", restarted.Hourly, restarted.Daily)
+	}
+	if encourage := restarted.Actions[models.ActionEncourage]; encourage.DailyCount != 1 {
+		t.Fatalf("encourage usage after restart = %+v", encourage)
+	}
+
+	later := reopenGateway(store, clock.NewVirtual(testStart.Add(time.Hour))).State()
+	if later.Budgets[models.ModeActive].Current != 10 || later.Cooldown.RemainingSeconds != 0 {
+		t.Fatalf("state an hour later = %+v", later)
+	}
+	if !approxEqual(later.Daily.Used, cost) || later.Hourly.Used != 0 {
+		t.Fatalf("hourly usage should roll over: %+v %+v", later.Hourly, later.Daily)
+	}
+	nextDay := reopenGateway(store, clock.NewVirtual(testStart.Add(24*time.Hour))).State()
+	if nextDay.Daily.Used != 0 || nextDay.Actions[models.ActionEncourage].DailyCount != 0 {
+		t.Fatalf("daily usage should roll over: %+v", nextDay)
+	}
+}
+
+func TestGatewayKeepsNewerSavedState(t *testing.T) {
+	_, store, clk := newTestGateway(t, nil)
+	if _, err := store.DB().Exec(`UPDATE gateway_state SET version = 99 WHERE id = 1`); err != nil {
+		t.Fatal(err)
+	}
+	g := reopenGateway(store, clk)
+	g.Evaluate(testContext(models.ModeActive, nil), testAction(models.ActionEncourage))
+
+	var version int
+	if err := store.DB().QueryRow(`SELECT version FROM gateway_state WHERE id = 1`).Scan(&version); err != nil {
+		t.Fatal(err)
+	}
+	if version != 99 {
+		t.Fatalf("saved state overwritten with version %d", version)
+	}
+}
@@ -0,0 +1,75 @@
+package gateway
+
+import "always/core/internal/models"
+
+const (
+	// reportedCostWeight is how much the policy's own cost estimate counts
+	// against the table cost.
+	reportedCostWeight = 0.5
+	minActionCost      = 0.5
+	maxActionCost      = 3.0
+	// confidencePenalty is the extra cost share for an action with zero
+	// confidence; a fully confident action pays the blended cost.
+	confidencePenalty = 1.0
+)
+
+// MaxActionCost is the most an action's blended cost can be, before the
+// confidence charge and the rule multipliers; see Gateway.MaxCost for the
+// bound of a priced action.
+func MaxActionCost() float64 {
+	return maxActionCost
+}
+
+// priceAction blends the table cost with the policy-reported cost, clamps it,
+// charges extra for low confidence and applies the cost multipliers of the
+// matching rules.
+func priceAction(action models.Action, multipliers []models.CostMultiplier) models.GatewayPricing {
+	pricing := models.GatewayPricing{
+		TableCost:    tableCost(action.ActionType),
+		ReportedCost: action.Cost,
+		Confidence:   action.Confidence,
+		Multipliers:  multipliers,
+	}
+	blended := pricing.TableCost
+	if action.Cost > 0 {
+		blended = (1-reportedCostWeight)*pricing.TableCost + reportedCostWeight*action.Cost
+	}
+	// The floor never lifts an action above its table cost, so AMBIENT stays
+	// nearly free.
+	pricing.BlendedCost = min(max(blended, min(minActionCost, pricing.TableCost)), maxActionCost)
+	pricing.ConfidenceFactor = 1 + (1-min(max(action.Confidence, 0), 1))*confidencePenalty
+	pricing.Cost = pricing.BlendedCost * pricing.ConfidenceFactor
+	for _, multiplier := range multipliers {
+		pricing.Cost *= multiplier.Factor
+	}
+	return pricing
+}
+
+// maxPricedCost is the most priceAction can charge under multipliers: a
+// blended cost at the cap with no confidence.
+func maxPricedCost(multipliers []models.CostMultiplier) float64 {
+	cost := maxActionCost * (1 + confidencePenalty)
+	for _, multiplier := range multipliers {
+		cost *= multiplier.Factor
+	}
+	return cost
+}
+
+func tableCost(actionType models.ActionType) float64 {
+	switch actionType {
+	case models.ActionDoNotDisturb:
+		return 0
+	case models.ActionRestReminder:
+		return 2.0
+	case models.ActionEncourage:
+		return 1.5
+	case models.ActionTaskBreakdown:
+		return 3.0
+	case models.ActionReframe:
+		return 2.5
+	case models.ActionAmbient:
+		return 0.1
+	default:
+		return 1.0
+	}
+}
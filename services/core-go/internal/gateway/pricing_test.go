@@ -0,0 +1,152 @@
+package gateway
+
+import (
+	"testing"
+	"time"
+
+	"always/core/internal/models"
+)
+
+func TestPriceAction(t *testing.T) {
+	tests := []struct {
+		name        string
+		action      models.Action
+		multipliers []float64
+		blended     float64
+		factor      float64
+		cost        float64
+	}{
+		{
+			name:    "table cost",
+			action:  models.Action{ActionType: models.ActionEncourage, Confidence: 1},
+			blended: 1.5, factor: 1, cost: 1.5,
+		},
+		{
+			name:    "reported cost blends in",
+			action:  models.Action{ActionType: models.ActionEncourage, Confidence: 1, Cost: 0.5},
+			blended: 1, factor: 1, cost: 1,
+		},
+		{
+			name:    "low confidence costs more",
+			action:  models.Action{ActionType: models.ActionRestReminder, Confidence: 0.5},
+			blended: 2, factor: 1.5, cost: 3,
+		},
+		{
+			name:        "multipliers compound",
+			action:      models.Action{ActionType: models.ActionReframe, Confidence: 1},
+			multipliers: []float64{0.5, 1.5},
+			blended:     2.5, factor: 1, cost: 1.875,
+		},
+		{
+			name:    "blended cost capped",
+			action:  models.Action{ActionType: models.ActionReframe, Confidence: 1, Cost: 9},
+			blended: maxActionCost, factor: 1, cost: maxActionCost,
+		},
+		{
+			name:    "blended cost floored",
+			action:  models.Action{ActionType: models.ActionEncourage, Confidence: 1, Cost: -2},
+			blended: 1.5, factor: 1, cost: 1.5,
+		},
+		{
+			name:    "blended floor with a cheap report",
+			action:  models.Action{ActionType: models.ActionRestReminder, Confidence: 1, Cost: 0.01},
+			blended: 1.005, factor: 1, cost: 1.005,
+		},
+		{
+			name:    "capped table cost still pays for low confidence",
+			action:  models.Action{ActionType: models.ActionTaskBreakdown, Confidence: 0.5},
+			blended: maxActionCost, factor: 1.5, cost: 4.5,
+		},
+		{
+			name:        "multipliers apply past the cap",
+			action:      models.Action{ActionType: models.ActionEncourage, Confidence: 0.8},
+			multipliers: []float64{3},
+			blended:     1.5, factor: 1.2, cost: 5.4,
+		},
+		{
+			name:    "ambient stays below the floor",
+			action:  models.Action{ActionType: models.ActionAmbient, Confidence: 1},
+			blended: 0.1, factor: 1, cost: 0.1,
+		},
+		{
+			name:    "confidence out of range",
+			action:  models.Action{ActionType: models.ActionEncourage, Confidence: -1},
+			blended: 1.5, factor: 2, cost: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var multipliers []models.CostMultiplier
+			for _, factor := range tt.multipliers {
+				multipliers = append(multipliers, models.CostMultiplier{Rule: "test", Factor: factor})
+			}
+			got := priceAction(tt.action, multipliers)
+			if !approxEqual(got.BlendedCost, tt.blended) || !approxEqual(got.ConfidenceFactor, tt.factor) || !approxEqual(got.Cost, tt.cost) {
+				t.Fatalf("pricing = %+v, want blended %v factor %v cost %v", got, tt.blended, tt.factor, tt.cost)
+			}
+		})
+	}
+}
+
+func TestLowConfidenceCostsMoreAtTheCap(t *testing.T) {
+	sure := testAction(models.ActionTaskBreakdown)
+	sure.Confidence = 1
+	unsure := sure
+	unsure.Confidence = 0.5
+	if tableCost(models.ActionTaskBreakdown) != maxActionCost {
+		t.Fatalf("TASK_BREAKDOWN table cost = %v, want it at the cap", tableCost(models.ActionTaskBreakdown))
+	}
+	if got, want := priceAction(unsure, nil).Cost, priceAction(sure, nil).Cost; !(got > want) {
+		t.Fatalf("cost at confidence 0.5 = %v, want more than %v", got, want)
+	}
+
+	g, _, _ := newTestGateway(t, nil)
+	ctx := testContext(models.ModeActive, nil)
+	ctx.FocusState = "FOCUSED"
+	_, sureDecision := g.DryRun(ctx, sure)
+	_, unsureDecision := g.DryRun(ctx, unsure)
+	if !approxEqual(sureDecision.Pricing.Cost, 4.5) || !approxEqual(unsureDecision.Pricing.Cost, 6.75) {
+		t.Fatalf("FOCUSED costs = %v and %v, want 4.5 and 6.75", sureDecision.Pricing.Cost, unsureDecision.Pricing.Cost)
+	}
+}
+
+func TestMaxCostBoundsPricedActions(t *testing.T) {
+	g, _, clk := newTestGateway(t, nil)
+	plain := testContext(models.ModeActive, nil)
+	if got := g.MaxCost(plain, models.ActionTaskBreakdown); !approxEqual(got, 6) {
+		t.Fatalf("MaxCost without context = %v, want 6", got)
+	}
+
+	// Late at night, in a focus session and FOCUSED: every multiplier applies.
+	clk.Set(testStart.Add(13 * time.Hour))
+	ctx := testContext(models.ModeActive, map[string]string{SignalFocusSessionActive: "true"})
+	ctx.FocusState = "FOCUSED"
+	bound := g.MaxCost(ctx, models.ActionTaskBreakdown)
+	if !approxEqual(bound, 6*3*1.5*1.5) {
+		t.Fatalf("MaxCost = %v, want %v", bound, 6*3*1.5*1.5)
+	}
+	if ok, _ := g.CanIntervene(ctx, bound, models.ActionTaskBreakdown); ok {
+		t.Fatalf("CanIntervene passed a bound of %v with a budget of 10", bound)
+	}
+	action := testAction(models.ActionTaskBreakdown)
+	action.Confidence = 0.5
+	_, decision := g.DryRun(ctx, action)
+	if decision.Pricing.Cost > bound || len(decision.Pricing.Multipliers) != 3 {
+		t.Fatalf("pricing = %+v, want three multipliers and at most %v", decision.Pricing, bound)
+	}
+
+	// REST_REMINDER is exempt from the session and FOCUSED multipliers, so it
+	// stays affordable and is enough to intervene.
+	bound = g.MaxCost(ctx, models.ActionTaskBreakdown, models.ActionRestReminder)
+	if !approxEqual(bound, 6*1.5) {
+		t.Fatalf("MaxCost with REST_REMINDER = %v, want %v", bound, 6*1.5)
+	}
+	if ok, reason := g.CanIntervene(ctx, bound, models.ActionTaskBreakdown, models.ActionRestReminder); !ok {
+		t.Fatalf("CanIntervene = %s", reason)
+	}
+	rest := testAction(models.ActionRestReminder)
+	rest.Confidence = 0.5
+	if _, decision := g.DryRun(ctx, rest); decision.Pricing == nil || decision.Pricing.Cost > bound {
+		t.Fatalf("REST_REMINDER cost = %v, want at most %v", decision.Pricing.Cost, bound)
+	}
+}
@@ -1,6 +1,10 @@
 package gateway
 
-import "always/core/internal/models"
+import (
+	"time"
+
+	"always/core/internal/models"
+)
 
 const (
 	ReasonInvalidActionType  = "invalid_action_type"
@@ -9,8 +13,121 @@ const (
 	ReasonModeSilentOverride = "mode_silent_override"
 	ReasonLowQualityAction   = "low_quality_action"
 	ReasonHighRiskBlocked    = "high_risk_blocked"
+	ReasonMeeting            = "meeting"
+)
+
+// Names of the built-in entries of the rule chain.
+const (
+	// RuleValidAction rejects unknown action types, risk levels and
+	// confidences outside [0, 1].
+	RuleValidAction = "valid_action"
+	// RuleHighRisk denies HIGH risk actions.
+	RuleHighRisk = "high_risk"
+	// RuleLowQuality turns actions without a message or with a confidence
+	// below 0.5 into DO_NOT_DISTURB.
+	RuleLowQuality = "low_quality"
+	// RuleSilentMode turns every action into DO_NOT_DISTURB in SILENT mode.
+	RuleSilentMode = "silent_mode"
+	// RuleConfig stands for DefaultRules merged with the declarative rules
+	// from gateway_rules or the rules file, evaluated in their own order.
+	RuleConfig = "config_rules"
 )
 
+// Rule is a check the gateway runs on every action before pricing it. Rules
+// run in chain order and the first deny or override verdict decides.
+type Rule interface {
+	Name() string
+	Evaluate(ctx models.Context, action models.Action, state RuleState) Verdict
+}
+
+// Verdict is the outcome of a Rule. The zero Verdict lets the action pass.
+type Verdict struct {
+	// Effect is empty to pass, or one of the RuleSpec effects.
+	Effect RuleEffect
+	// Reason is recorded on the decision and defaults to the rule name.
+	Reason string
+	// Message replaces the DO_NOT_DISTURB message of a deny or override.
+	Message string
+	// Multiplier scales the cost for EffectCostMultiplier.
+	Multiplier float64
+}
+
+// RuleState is the gateway state a Rule sees: the budget of the context's
+// mode after recovery, the caps and cooldowns, and the limits and usage of
+// the action's type.
+type RuleState struct {
+	Now      time.Time
+	Budget   ModeBudgetState
+	Hourly   UsageState
+	Daily    UsageState
+	Cooldown CooldownState
+	Action   ActionLimitState
+	Schedule string
+}
+
+type validActionRule struct{}
+
+func (validActionRule) Name() string { return RuleValidAction }
+
+func (validActionRule) Evaluate(_ models.Context, action models.Action, _ RuleState) Verdict {
+	if reason, invalid := ruleInvalidAction(action); invalid {
+		return Verdict{Effect: EffectOverride, Reason: reason}
+	}
+	return Verdict{}
+}
+
+type highRiskRule struct{}
+
+func (highRiskRule) Name() string { return RuleHighRisk }
+
+func (highRiskRule) Evaluate(_ models.Context, action models.Action, _ RuleState) Verdict {
+	if action.RiskLevel == models.RiskHigh {
+		return Verdict{Effect: EffectDeny, Reason: ReasonHighRiskBlocked}
+	}
+	return Verdict{}
+}
+
+type lowQualityRule struct{}
+
+func (lowQualityRule) Name() string { return RuleLowQuality }
+
+func (lowQualityRule) Evaluate(_ models.Context, action models.Action, _ RuleState) Verdict {
+	if action.Message == "" || action.Confidence < 0.5 {
+		return Verdict{Effect: EffectOverride, Reason: ReasonLowQualityAction}
+	}
+	return Verdict{}
+}
+
+type silentModeRule struct{}
+
+func (silentModeRule) Name() string { return RuleSilentMode }
+
+func (silentModeRule) Evaluate(ctx models.Context, action models.Action, _ RuleState) Verdict {
+	if ctx.Mode == models.ModeSilent && action.ActionType != models.ActionDoNotDisturb {
+		return Verdict{Effect: EffectOverride, Reason: ReasonModeSilentOverride}
+	}
+	return Verdict{}
+}
+
+// specRule runs one declarative RuleSpec as a Rule.
+type specRule struct {
+	spec RuleSpec
+}
+
+func (r specRule) Name() string { return r.spec.Name }
+
+func (r specRule) Evaluate(ctx models.Context, action models.Action, state RuleState) Verdict {
+	if !r.spec.When.matches(ctx, action, state.Now) {
+		return Verdict{}
+	}
+	return Verdict{
+		Effect:     r.spec.Effect,
+		Reason:     r.spec.reason(),
+		Message:    r.spec.Message,
+		Multiplier: r.spec.Multiplier,
+	}
+}
+
 func ruleInvalidAction(action models.Action) (string, bool) {
 	if !isValidActionType(action.ActionType) {
 		return ReasonInvalidActionType, true
@@ -24,25 +141,14 @@ func ruleInvalidAction(action models.Action) (string, bool) {
 	return "", false
 }
 
-func ruleHighRisk(action models.Action) bool {
-	return action.RiskLevel == models.RiskHigh
-}
-
-func ruleLowQuality(action models.Action) bool {
-	return action.Message == "" || action.Confidence < 0.5
-}
-
-func ruleSilentOverride(ctx models.Context, action models.Action) bool {
-	return ctx.Mode == models.ModeSilent && action.ActionType != models.ActionDoNotDisturb
-}
-
 func isValidActionType(actionType models.ActionType) bool {
 	switch actionType {
 	case models.ActionDoNotDisturb,
 		models.ActionEncourage,
 		models.ActionTaskBreakdown,
 		models.ActionRestReminder,
-		models.ActionReframe:
+		models.ActionReframe,
+		models.ActionAmbient:
 		return true
 	default:
 		return false
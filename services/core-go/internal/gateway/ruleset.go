@@ -0,0 +1,370 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"always/core/internal/clock"
+	"always/core/internal/models"
+)
+
+type RuleEffect string
+
+const (
+	// EffectDeny and EffectOverride replace the action with DO_NOT_DISTURB
+	// and stop evaluation; they differ only in the recorded decision.
+	EffectDeny     RuleEffect = "deny"
+	EffectOverride RuleEffect = "override"
+	// EffectCostMultiplier scales the budget cost of the action and lets
+	// evaluation continue. Multipliers of several matching rules compound.
+	EffectCostMultiplier RuleEffect = "cost_multiplier"
+)
+
+// RuleSpec is one declarative gateway rule. Rules run in order; the first
+// deny or override that matches decides.
+type RuleSpec struct {
+	Name string `json:"name"`
+	// Disabled removes the default rule of the same name; the other fields
+	// are ignored.
+	Disabled   bool          `json:"disabled,omitempty"`
+	When       RuleCondition `json:"when"`
+	Effect     RuleEffect    `json:"effect"`
+	Reason     string        `json:"reason,omitempty"`
+	Message    string        `json:"message,omitempty"`
+	Multiplier float64       `json:"multiplier,omitempty"`
+}
+
+// RuleCondition matches when every field that is set matches. Lists match any
+// of their values; Any matches when at least one nested condition does.
+type RuleCondition struct {
+	Mode              []models.Mode       `json:"mode,omitempty"`
+	ActionType        []models.ActionType `json:"action_type,omitempty"`
+	ActionTypeNot     []models.ActionType `json:"action_type_not,omitempty"`
+	RiskLevel         []models.RiskLevel  `json:"risk_level,omitempty"`
+	ConfidenceBelow   *float64            `json:"confidence_below,omitempty"`
+	ConfidenceAtLeast *float64            `json:"confidence_at_least,omitempty"`
+	MessageEmpty      *bool               `json:"message_empty,omitempty"`
+	FocusState        []string            `json:"focus_state,omitempty"`
+	// Signals maps a signal name to its required value; "*" requires any
+	// non-empty value and "" requires the signal to be absent.
+	Signals map[string]string `json:"signals,omitempty"`
+	// SignalsAtMost and SignalsAtLeast compare numeric signals; a missing or
+	// non-numeric signal does not match.
+	SignalsAtMost  map[string]float64 `json:"signals_at_most,omitempty"`
+	SignalsAtLeast map[string]float64 `json:"signals_at_least,omitempty"`
+	// Time is a local HH:MM-HH:MM window and may wrap midnight. Weekdays name
+	// the day a window starts on, so a Friday 22:00-07:00 window still covers
+	// Saturday morning.
+	Time     string          `json:"time,omitempty"`
+	Weekdays []string        `json:"weekdays,omitempty"`
+	Any      []RuleCondition `json:"any,omitempty"`
+}
+
+// RuleError locates a validation problem in a rule list.
+type RuleError struct {
+	Index int    `json:"index"`
+	Name  string `json:"name,omitempty"`
+	Error string `json:"error"`
+}
+
+type RuleErrors []RuleError
+
+func (e RuleErrors) Error() string {
+	parts := make([]string, 0, len(e))
+	for _, item := range e {
+		if item.Name != "" {
+			parts = append(parts, fmt.Sprintf("rule %d (%s): %s", item.Index, item.Name, item.Error))
+		} else {
+			parts = append(parts, fmt.Sprintf("rule %d: %s", item.Index, item.Error))
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+// DefaultRules are the built-in declarative rules. Rules from a rules file or
+// the gateway_rules setting are merged over them by name, see MergeRules. The
+// high_risk, low_quality and silent_mode checks are chain rules of their own
+// and cannot be configured away.
+func DefaultRules() []RuleSpec {
+	return []RuleSpec{
+		{
+			Name: "meeting",
+			When: RuleCondition{
+				ActionTypeNot: []models.ActionType{models.ActionDoNotDisturb},
+				Any: []RuleCondition{
+					{Signals: map[string]string{SignalInMeeting: "true"}},
+					{SignalsAtMost: map[string]float64{SignalNextMeetingMinutes: 5}},
+				},
+			},
+			Effect:  EffectOverride,
+			Reason:  ReasonMeeting,
+			Message: "会议中或会议即将开始，已暂停提示。",
+		},
+		{
+			Name: "focus_session",
+			When: RuleCondition{
+				Signals:       map[string]string{SignalFocusSessionActive: "true"},
+				ActionTypeNot: []models.ActionType{models.ActionRestReminder},
+			},
+			Effect:     EffectCostMultiplier,
+			Multiplier: 3,
+		},
+		{
+			Name: "focus_pattern_reframe",
+			When: RuleCondition{
+				Signals:    map[string]string{SignalFocusPattern: "*"},
+				ActionType: []models.ActionType{models.ActionReframe},
+			},
+			Effect:     EffectCostMultiplier,
+			Multiplier: 0.5,
+		},
+		{
+			Name: "focused_state",
+			When: RuleCondition{
+				FocusState:    []string{"FOCUSED"},
+				ActionTypeNot: []models.ActionType{models.ActionRestReminder},
+			},
+			Effect:     EffectCostMultiplier,
+			Multiplier: 1.5,
+		},
+		{
+			Name:       "late_night",
+			When:       RuleCondition{Time: "22:00-07:00"},
+			Effect:     EffectCostMultiplier,
+			Multiplier: 1.5,
+		},
+	}
+}
+
+// MergeRules overlays configured rules on base by name: a rule named like a
+// base rule replaces it in place, a disabled one removes it, and new rules
+// follow the base rules in their configured order.
+func MergeRules(base []RuleSpec, configured []RuleSpec) []RuleSpec {
+	byName := make(map[string]RuleSpec, len(configured))
+	for _, rule := range configured {
+		byName[rule.Name] = rule
+	}
+	merged := make([]RuleSpec, 0, len(base)+len(configured))
+	seen := make(map[string]bool, len(base))
+	for _, rule := range base {
+		seen[rule.Name] = true
+		if override, ok := byName[rule.Name]; ok {
+			rule = override
+		}
+		if !rule.Disabled {
+			merged = append(merged, rule)
+		}
+	}
+	for _, rule := range configured {
+		if !seen[rule.Name] && !rule.Disabled {
+			merged = append(merged, rule)
+		}
+	}
+	return merged
+}
+
+// ParseRules decodes and validates a JSON rule list. Validation problems are
+// returned as RuleErrors.
+func ParseRules(raw []byte) ([]RuleSpec, error) {
+	var rules []RuleSpec
+	decoder := json.NewDecoder(strings.NewReader(string(raw)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&rules); err != nil {
+		return nil, fmt.Errorf("decode gateway rules: %w", err)
+	}
+	if err := ValidateRules(rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func ValidateRules(rules []RuleSpec) error {
+	var errs RuleErrors
+	names := map[string]bool{}
+	for i, rule := range rules {
+		fail := func(format string, args ...any) {
+			errs = append(errs, RuleError{Index: i, Name: rule.Name, Error: fmt.Sprintf(format, args...)})
+		}
+		if strings.TrimSpace(rule.Name) == "" {
+			fail("name required")
+		} else if names[rule.Name] {
+			fail("duplicate name")
+		} else if isChainRuleName(rule.Name) {
+			fail("name is reserved for a built-in rule")
+		}
+		names[rule.Name] = true
+		if rule.Disabled {
+			continue
+		}
+		switch rule.Effect {
+		case EffectDeny, EffectOverride:
+			if rule.Multiplier != 0 {
+				fail("multiplier only applies to %s", EffectCostMultiplier)
+			}
+		case EffectCostMultiplier:
+			if rule.Multiplier <= 0 {
+				fail("multiplier must be positive")
+			}
+		default:
+			fail("invalid effect %q", rule.Effect)
+		}
+		if err := rule.When.validate(); err != nil {
+			fail("%v", err)
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func isChainRuleName(name string) bool {
+	switch name {
+	case RuleValidAction, RuleHighRisk, RuleLowQuality, RuleSilentMode, RuleConfig:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c RuleCondition) validate() error {
+	for _, mode := range c.Mode {
+		switch mode {
+		case models.ModeSilent, models.ModeLight, models.ModeActive:
+		default:
+			return fmt.Errorf("invalid mode %q", mode)
+		}
+	}
+	for _, actionType := range append(append([]models.ActionType{}, c.ActionType...), c.ActionTypeNot...) {
+		if !isValidActionType(actionType) {
+			return fmt.Errorf("invalid action type %q", actionType)
+		}
+	}
+	for _, level := range c.RiskLevel {
+		if !isValidRiskLevel(level) {
+			return fmt.Errorf("invalid risk level %q", level)
+		}
+	}
+	if c.Time != "" {
+		if _, _, err := clock.ParseRange(c.Time); err != nil {
+			return err
+		}
+	}
+	for _, day := range c.Weekdays {
+		if _, ok := clock.ParseWeekday(day); !ok {
+			return fmt.Errorf("invalid weekday %q", day)
+		}
+	}
+	for _, nested := range c.Any {
+		if err := nested.validate(); err != nil {
+			return fmt.Errorf("any: %w", err)
+		}
+	}
+	return nil
+}
+
+func (c RuleCondition) matches(ctx models.Context, action models.Action, now time.Time) bool {
+	if len(c.Mode) > 0 && !contains(c.Mode, ctx.Mode) {
+		return false
+	}
+	if len(c.ActionType) > 0 && !contains(c.ActionType, action.ActionType) {
+		return false
+	}
+	if contains(c.ActionTypeNot, action.ActionType) {
+		return false
+	}
+	if len(c.RiskLevel) > 0 && !contains(c.RiskLevel, action.RiskLevel) {
+		return false
+	}
+	if c.ConfidenceBelow != nil && action.Confidence >= *c.ConfidenceBelow {
+		return false
+	}
+	if c.ConfidenceAtLeast != nil && action.Confidence < *c.ConfidenceAtLeast {
+		return false
+	}
+	if c.MessageEmpty != nil && (action.Message == "") != *c.MessageEmpty {
+		return false
+	}
+	if len(c.FocusState) > 0 {
+		state := ctx.FocusState
+		if state == "" {
+			state = ctx.Signals["focus_state"]
+		}
+		if !contains(c.FocusState, state) {
+			return false
+		}
+	}
+	for key, want := range c.Signals {
+		got := ctx.Signals[key]
+		switch want {
+		case "*":
+			if got == "" {
+				return false
+			}
+		default:
+			if got != want {
+				return false
+			}
+		}
+	}
+	for key, limit := range c.SignalsAtMost {
+		value, err := strconv.ParseFloat(strings.TrimSpace(ctx.Signals[key]), 64)
+		if err != nil || value > limit {
+			return false
+		}
+	}
+	for key, limit := range c.SignalsAtLeast {
+		value, err := strconv.ParseFloat(strings.TrimSpace(ctx.Signals[key]), 64)
+		if err != nil || value < limit {
+			return false
+		}
+	}
+	day := now.Weekday()
+	if c.Time != "" {
+		start, end, err := clock.ParseRange(c.Time)
+		if err != nil || !clock.InRange(now, start, end) {
+			return false
+		}
+		day = clock.StartDay(now, start, end)
+	}
+	if len(c.Weekdays) > 0 {
+		matched := false
+		for _, name := range c.Weekdays {
+			if weekday, ok := clock.ParseWeekday(name); ok && weekday == day {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if len(c.Any) > 0 {
+		for _, nested := range c.Any {
+			if nested.matches(ctx, action, now) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func (r RuleSpec) reason() string {
+	if r.Reason != "" {
+		return r.Reason
+	}
+	return r.Name
+}
+
+func contains[T comparable](values []T, value T) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
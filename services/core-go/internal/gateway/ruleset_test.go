@@ -0,0 +1,161 @@
+package gateway
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"always/core/internal/models"
+)
+
+func ruleNames(rules []RuleSpec) []string {
+	names := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		names = append(names, rule.Name)
+	}
+	return names
+}
+
+func TestMergeRules(t *testing.T) {
+	merged := MergeRules(DefaultRules(), []RuleSpec{
+		{Name: "weekend", When: RuleCondition{Weekdays: []string{"sat", "sun"}}, Effect: EffectCostMultiplier, Multiplier: 2},
+		{Name: "late_night", When: RuleCondition{Time: "23:00-06:00"}, Effect: EffectOverride},
+		{Name: "focus_session", Disabled: true},
+		{Name: "unknown", Disabled: true},
+	})
+	want := []string{"meeting", "focus_pattern_reframe", "focused_state", "late_night", "weekend"}
+	if got := ruleNames(merged); !slices.Equal(got, want) {
+		t.Fatalf("merged = %v, want %v", got, want)
+	}
+	if merged[3].Effect != EffectOverride || merged[3].When.Time != "23:00-06:00" {
+		t.Fatalf("late_night not replaced: %+v", merged[3])
+	}
+}
+
+func TestValidateRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []RuleSpec
+		ok    bool
+	}{
+		{name: "defaults", rules: DefaultRules(), ok: true},
+		{name: "disabled needs no effect", rules: []RuleSpec{{Name: "meeting", Disabled: true}}, ok: true},
+		{name: "missing name", rules: []RuleSpec{{Effect: EffectDeny}}},
+		{name: "duplicate", rules: []RuleSpec{{Name: "a", Effect: EffectDeny}, {Name: "a", Effect: EffectDeny}}},
+		{name: "built-in high_risk", rules: []RuleSpec{{Name: RuleHighRisk, Disabled: true}}},
+		{name: "built-in silent_mode", rules: []RuleSpec{{Name: RuleSilentMode, Effect: EffectCostMultiplier, Multiplier: 1}}},
+		{name: "multiplier on deny", rules: []RuleSpec{{Name: "a", Effect: EffectDeny, Multiplier: 2}}},
+		{name: "zero multiplier", rules: []RuleSpec{{Name: "a", Effect: EffectCostMultiplier}}},
+		{name: "bad effect", rules: []RuleSpec{{Name: "a", Effect: "block"}}},
+		{name: "bad time", rules: []RuleSpec{{Name: "a", Effect: EffectDeny, When: RuleCondition{Time: "22-07"}}}},
+		{name: "bad weekday", rules: []RuleSpec{{Name: "a", Effect: EffectDeny, When: RuleCondition{Weekdays: []string{"someday"}}}}},
+		{name: "bad nested mode", rules: []RuleSpec{{Name: "a", Effect: EffectDeny, When: RuleCondition{Any: []RuleCondition{{Mode: []models.Mode{"LOUD"}}}}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRules(tt.rules)
+			if (err == nil) != tt.ok {
+				t.Fatalf("ValidateRules() = %v, ok %v", err, tt.ok)
+			}
+			var ruleErrs RuleErrors
+			if err != nil && !errors.As(err, &ruleErrs) {
+				t.Fatalf("error %T is not RuleErrors", err)
+			}
+		})
+	}
+}
+
+func TestConfiguredRulesKeepDefaultsAndSafetyRules(t *testing.T) {
+	g, store, _ := newTestGateway(t, map[string]string{
+		SettingGatewayRules: `[{"name": "no_reframe", "when": {"action_type": ["REFRAME"]}, "effect": "deny"}]`,
+	})
+	set := g.Rules()
+	if set.Source != RulesSourceSettings {
+		t.Fatalf("source = %q", set.Source)
+	}
+	want := []string{"meeting", "focus_session", "focus_pattern_reframe", "focused_state", "late_night", "no_reframe"}
+	if got := ruleNames(set.Rules); !slices.Equal(got, want) {
+		t.Fatalf("rules = %v, want %v", got, want)
+	}
+
+	ctx := testContext(models.ModeActive, nil)
+	if _, decision := g.DryRun(ctx, testAction(models.ActionReframe)); decision.Decision != models.GatewayDeny || decision.Reason != "no_reframe" {
+		t.Fatalf("configured rule: %+v", decision)
+	}
+	meeting := testContext(models.ModeActive, map[string]string{SignalInMeeting: "true"})
+	if _, decision := g.DryRun(meeting, testAction(models.ActionEncourage)); decision.Reason != ReasonMeeting {
+		t.Fatalf("default meeting rule lost: %+v", decision)
+	}
+	risky := testAction(models.ActionEncourage)
+	risky.RiskLevel = models.RiskHigh
+	if _, decision := g.DryRun(ctx, risky); decision.Decision != models.GatewayDeny || decision.Reason != ReasonHighRiskBlocked {
+		t.Fatalf("high risk: %+v", decision)
+	}
+	vague := testAction(models.ActionEncourage)
+	vague.Confidence = 0.2
+	if _, decision := g.DryRun(ctx, vague); decision.Reason != ReasonLowQualityAction {
+		t.Fatalf("low quality: %+v", decision)
+	}
+	if _, decision := g.DryRun(testContext(models.ModeSilent, nil), testAction(models.ActionEncourage)); decision.Reason != ReasonModeSilentOverride {
+		t.Fatalf("silent mode: %+v", decision)
+	}
+
+	// Rules that try to configure a built-in check are rejected as a whole.
+	if err := store.UpsertSetting(SettingGatewayRules, `[{"name": "high_risk", "disabled": true}]`); err != nil {
+		t.Fatal(err)
+	}
+	set = g.Rules()
+	if set.LastError == "" || !slices.Contains(ruleNames(set.Rules), "no_reframe") {
+		t.Fatalf("reserved name accepted: %+v", set)
+	}
+	if _, decision := g.DryRun(ctx, risky); decision.Reason != ReasonHighRiskBlocked {
+		t.Fatalf("high risk after rejected rules: %+v", decision)
+	}
+}
+
+func TestRulesFileMergesOverDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	if err := os.WriteFile(path, []byte(`[{"name": "meeting", "disabled": true}, {"name": "late_night", "when": {"time": "21:00-07:00"}, "effect": "cost_multiplier", "multiplier": 4}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	g, _, clk := newTestGateway(t, nil)
+	g.SetRulesFile(path)
+	set := g.Rules()
+	want := []string{"focus_session", "focus_pattern_reframe", "focused_state", "late_night"}
+	if set.Source != RulesSourceFile || !slices.Equal(ruleNames(set.Rules), want) {
+		t.Fatalf("rules = %+v", set)
+	}
+
+	meeting := testContext(models.ModeActive, map[string]string{SignalInMeeting: "true"})
+	if _, decision := g.DryRun(meeting, testAction(models.ActionEncourage)); decision.Decision != models.GatewayAllow {
+		t.Fatalf("disabled meeting rule still applied: %+v", decision)
+	}
+	clk.Set(testStart.Add(11 * time.Hour)) // 21:00
+	_, decision := g.DryRun(testContext(models.ModeActive, nil), testAction(models.ActionEncourage))
+	if decision.Pricing == nil || len(decision.Pricing.Multipliers) != 1 || decision.Pricing.Multipliers[0].Factor != 4 {
+		t.Fatalf("late_night override: %+v", decision.Pricing)
+	}
+}
+
+func TestFocusSessionMakesInterventionsExpensive(t *testing.T) {
+	g, _, _ := newTestGateway(t, map[string]string{
+		SettingBudgetSchedule: `[{"name": "tight", "budgets": {"ACTIVE": 4}}]`,
+	})
+	plain := testContext(models.ModeActive, nil)
+	session := testContext(models.ModeActive, map[string]string{SignalFocusSessionActive: "true"})
+	for _, actionType := range []models.ActionType{models.ActionEncourage, models.ActionTaskBreakdown} {
+		if final, decision := g.DryRun(plain, testAction(actionType)); final.ActionType != actionType || decision.Decision != models.GatewayAllow {
+			t.Fatalf("%s without a session: %s %+v", actionType, final.ActionType, decision)
+		}
+		final, decision := g.DryRun(session, testAction(actionType))
+		if final.ActionType == actionType || !slices.Contains(traceChecks(decision), "mode_budget=block") {
+			t.Fatalf("%s in a session: %s, trace %v", actionType, final.ActionType, traceChecks(decision))
+		}
+	}
+	if final, decision := g.DryRun(session, testAction(models.ActionRestReminder)); final.ActionType != models.ActionRestReminder || decision.Decision != models.GatewayAllow {
+		t.Fatalf("REST_REMINDER in a session: %s %+v", final.ActionType, decision)
+	}
+}
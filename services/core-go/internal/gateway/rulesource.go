@@ -0,0 +1,143 @@
+package gateway
+
+import (
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// SettingGatewayRules holds a JSON rule list that replaces the rules file.
+	SettingGatewayRules = "gateway_rules"
+	envRulesFile        = "GATEWAY_RULES_FILE"
+)
+
+const (
+	RulesSourceDefault  = "default"
+	RulesSourceSettings = "settings"
+	RulesSourceFile     = "file"
+)
+
+// RuleSet describes the rules the gateway currently evaluates: DefaultRules
+// with the configured rules of Source merged over them.
+type RuleSet struct {
+	Source    string     `json:"source"`
+	Path      string     `json:"path,omitempty"`
+	Rules     []RuleSpec `json:"rules"`
+	LoadedAt  int64      `json:"loaded_at_ms,omitempty"`
+	LastError string     `json:"last_error,omitempty"`
+	// Chain lists the entries of the rule chain in evaluation order; these
+	// rules run at its RuleConfig entry.
+	Chain []string `json:"chain"`
+}
+
+type ruleState struct {
+	set         RuleSet
+	settingsRaw string
+	fileModTime time.Time
+	fileSize    int64
+}
+
+func newRuleState() ruleState {
+	return ruleState{
+		set: RuleSet{
+			Source: RulesSourceDefault,
+			Path:   strings.TrimSpace(os.Getenv(envRulesFile)),
+			Rules:  DefaultRules(),
+		},
+	}
+}
+
+// SetRulesFile changes the rules file watched when the gateway_rules setting
+// is unset. An empty path falls back to the built-in rules.
+func (g *Gateway) SetRulesFile(path string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.rules = newRuleState()
+	g.rules.set.Path = strings.TrimSpace(path)
+}
+
+// Rules returns the active rule set after picking up any pending changes.
+func (g *Gateway) Rules() RuleSet {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.refreshRulesLocked()
+	set := g.rules.set
+	set.Rules = append([]RuleSpec(nil), set.Rules...)
+	set.Chain = g.ruleChainLocked()
+	return set
+}
+
+// refreshRulesLocked reloads rules when the setting or the file changed. A
+// broken source keeps the previously loaded rules and records the error.
+func (g *Gateway) refreshRulesLocked() {
+	if g.store != nil {
+		raw, ok, err := g.store.GetSetting(SettingGatewayRules)
+		if err != nil {
+			g.logger.Warn("load gateway rules setting failed", slog.Any("error", err))
+			return
+		}
+		if ok && strings.TrimSpace(raw) != "" {
+			if raw != g.rules.settingsRaw {
+				g.rules.settingsRaw = raw
+				// Force a re-read of the file once the setting is cleared.
+				g.rules.fileModTime = time.Time{}
+				g.applyRulesLocked(RulesSourceSettings, []byte(raw))
+			}
+			return
+		}
+	}
+	if g.rules.settingsRaw != "" || g.rules.set.Source == RulesSourceSettings {
+		g.rules.settingsRaw = ""
+		g.setRulesLocked(RulesSourceDefault, DefaultRules())
+	}
+
+	path := g.rules.set.Path
+	if path == "" {
+		return
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		if g.rules.set.LastError == "" {
+			g.logger.Warn("stat gateway rules file failed", slog.String("path", path), slog.Any("error", err))
+		}
+		g.rules.set.LastError = err.Error()
+		g.rules.fileModTime = time.Time{}
+		return
+	}
+	if info.ModTime().Equal(g.rules.fileModTime) && info.Size() == g.rules.fileSize {
+		return
+	}
+	g.rules.fileModTime = info.ModTime()
+	g.rules.fileSize = info.Size()
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		g.logger.Warn("read gateway rules file failed", slog.String("path", path), slog.Any("error", err))
+		g.rules.set.LastError = err.Error()
+		return
+	}
+	g.applyRulesLocked(RulesSourceFile, raw)
+}
+
+func (g *Gateway) applyRulesLocked(source string, raw []byte) {
+	rules, err := ParseRules(raw)
+	if err != nil {
+		g.logger.Warn("gateway rules rejected, keeping previous rules",
+			slog.String("source", source),
+			slog.Any("error", err))
+		g.rules.set.LastError = err.Error()
+		return
+	}
+	g.setRulesLocked(source, MergeRules(DefaultRules(), rules))
+	g.logger.Info("gateway rules loaded",
+		slog.String("source", source),
+		slog.Int("rules", len(rules)))
+}
+
+func (g *Gateway) setRulesLocked(source string, rules []RuleSpec) {
+	g.rules.set.Source = source
+	g.rules.set.Rules = rules
+	g.rules.set.LoadedAt = g.clock.Now().UnixMilli()
+	g.rules.set.LastError = ""
+}
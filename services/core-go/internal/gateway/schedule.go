@@ -0,0 +1,135 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"always/core/internal/models"
+)
+
+// SettingBudgetSchedule holds a JSON list of ScheduleProfile.
+const SettingBudgetSchedule = "budget_schedule"
+
+// ScheduleProfile overrides the budget configuration during a time window on
+// some weekdays. Unset fields keep the configured value; the first profile
+// that matches wins.
+type ScheduleProfile struct {
+	Name     string   `json:"name"`
+	Time     string   `json:"time,omitempty"`
+	Weekdays []string `json:"weekdays,omitempty"`
+	// Budgets replaces the maximum budget of the listed modes; BudgetFactor
+	// then scales every mode.
+	Budgets         map[models.Mode]float64 `json:"budgets,omitempty"`
+	BudgetFactor    *float64                `json:"budget_factor,omitempty"`
+	RecoveryPerMin  *float64                `json:"recovery_per_minute,omitempty"`
+	HourlyCap       *float64                `json:"hourly_cap,omitempty"`
+	DailyCap        *float64                `json:"daily_cap,omitempty"`
+	CooldownSeconds *float64                `json:"cooldown_seconds,omitempty"`
+}
+
+// ParseSchedule decodes and validates a JSON schedule.
+func ParseSchedule(raw string) ([]ScheduleProfile, error) {
+	var profiles []ScheduleProfile
+	decoder := json.NewDecoder(strings.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&profiles); err != nil {
+		return nil, fmt.Errorf("decode budget schedule: %w", err)
+	}
+	names := map[string]bool{}
+	for i, profile := range profiles {
+		if err := profile.validate(); err != nil {
+			return nil, fmt.Errorf("profile %d (%s): %w", i, profile.Name, err)
+		}
+		if names[profile.Name] {
+			return nil, fmt.Errorf("profile %d (%s): duplicate name", i, profile.Name)
+		}
+		names[profile.Name] = true
+	}
+	return profiles, nil
+}
+
+func (p ScheduleProfile) validate() error {
+	if strings.TrimSpace(p.Name) == "" {
+		return fmt.Errorf("name required")
+	}
+	if err := p.window().validate(); err != nil {
+		return err
+	}
+	for mode, budget := range p.Budgets {
+		switch mode {
+		case models.ModeSilent, models.ModeLight, models.ModeActive:
+		default:
+			return fmt.Errorf("invalid mode %q", mode)
+		}
+		if budget < 0 {
+			return fmt.Errorf("budget for %s must not be negative", mode)
+		}
+	}
+	for field, value := range map[string]*float64{
+		"budget_factor":       p.BudgetFactor,
+		"recovery_per_minute": p.RecoveryPerMin,
+		"hourly_cap":          p.HourlyCap,
+		"daily_cap":           p.DailyCap,
+		"cooldown_seconds":    p.CooldownSeconds,
+	} {
+		if value != nil && *value < 0 {
+			return fmt.Errorf("%s must not be negative", field)
+		}
+	}
+	return nil
+}
+
+func (p ScheduleProfile) window() RuleCondition {
+	return RuleCondition{Time: p.Time, Weekdays: p.Weekdays}
+}
+
+func (p ScheduleProfile) matches(now time.Time) bool {
+	return p.window().matches(models.Context{}, models.Action{}, now)
+}
+
+// applyScheduleLocked applies the profile active at now on top of the
+// configured budgets and returns its name, or "" when none matches.
+func (g *Gateway) applyScheduleLocked(cfg *Config, now time.Time) string {
+	if g.store == nil {
+		return ""
+	}
+	value, ok, err := g.store.GetSetting(SettingBudgetSchedule)
+	if err != nil || !ok || strings.TrimSpace(value) == "" {
+		return ""
+	}
+	profiles, err := ParseSchedule(value)
+	if err != nil {
+		g.logger.Warn("invalid budget schedule, ignoring", slog.Any("error", err))
+		return ""
+	}
+	for _, profile := range profiles {
+		if !profile.matches(now) {
+			continue
+		}
+		for mode, budget := range profile.Budgets {
+			cfg.ModeBudgets[mode] = budget
+		}
+		if profile.BudgetFactor != nil {
+			for mode := range cfg.ModeBudgets {
+				cfg.ModeBudgets[mode] *= *profile.BudgetFactor
+			}
+		}
+		if profile.RecoveryPerMin != nil {
+			cfg.RecoveryRate = *profile.RecoveryPerMin
+		}
+		if profile.HourlyCap != nil {
+			cfg.HourlyCap = *profile.HourlyCap
+		}
+		if profile.DailyCap != nil {
+			cfg.DailyCap = *profile.DailyCap
+		}
+		if profile.CooldownSeconds != nil {
+			cfg.CooldownSeconds = *profile.CooldownSeconds
+		}
+		return profile.Name
+	}
+	return ""
+}
@@ -0,0 +1,123 @@
+package gateway
+
+import (
+	"testing"
+	"time"
+
+	"always/core/internal/models"
+)
+
+const testSchedule = `[
+	{"name": "deep_work", "time": "09:00-12:00", "weekdays": ["mon", "tue", "wed", "thu", "fri"], "budget_factor": 0.5},
+	{"name": "afternoon", "time": "13:00-18:00", "budgets": {"ACTIVE": 12}, "recovery_per_minute": 1, "hourly_cap": 4},
+	{"name": "night", "time": "22:00-07:00", "budget_factor": 0.1, "cooldown_seconds": 1800}
+]`
+
+func TestParseSchedule(t *testing.T) {
+	tests := []struct {
+		raw string
+		ok  bool
+	}{
+		{raw: testSchedule, ok: true},
+		{raw: `[]`, ok: true},
+		{raw: `[{"time": "09:00-12:00"}]`},
+		{raw: `[{"name": "a"}, {"name": "a"}]`},
+		{raw: `[{"name": "a", "time": "9-12"}]`},
+		{raw: `[{"name": "a", "weekdays": ["someday"]}]`},
+		{raw: `[{"name": "a", "budgets": {"LOUD": 1}}]`},
+		{raw: `[{"name": "a", "budgets": {"ACTIVE": -1}}]`},
+		{raw: `[{"name": "a", "cooldown_seconds": -1}]`},
+		{raw: `[{"name": "a", "budget": 1}]`},
+	}
+	for _, tt := range tests {
+		if _, err := ParseSchedule(tt.raw); (err == nil) != tt.ok {
+			t.Errorf("ParseSchedule(%s) = %v, ok %v", tt.raw, err, tt.ok)
+		}
+	}
+}
+
+func TestScheduleResolvesOnEveryRefresh(t *testing.T) {
+	// testStart is Monday 10:00.
+	g, _, clk := newTestGateway(t, map[string]string{SettingBudgetSchedule: testSchedule})
+	tests := []struct {
+		at       time.Time
+		schedule string
+		active   float64
+		recovery float64
+		cooldown float64
+		hourly   float64
+	}{
+		{at: testStart, schedule: "deep_work", active: 5, recovery: 0.5, cooldown: 300},
+		{at: testStart.Add(2*time.Hour + 30*time.Minute), schedule: "", active: 10, recovery: 0.5, cooldown: 300},
+		{at: testStart.Add(4 * time.Hour), schedule: "afternoon", active: 12, recovery: 1, cooldown: 300, hourly: 4},
+		{at: testStart.Add(13 * time.Hour), schedule: "night", active: 1, recovery: 0.5, cooldown: 1800},
+		{at: testStart.Add(20 * time.Hour), schedule: "night", active: 1, recovery: 0.5, cooldown: 1800},
+		// Saturday morning has no deep work.
+		{at: testStart.Add(5 * 24 * time.Hour), schedule: "", active: 10, recovery: 0.5, cooldown: 300},
+	}
+	for _, tt := range tests {
+		clk.Set(tt.at)
+		state := g.State()
+		if state.Schedule != tt.schedule || !approxEqual(state.Budgets[models.ModeActive].Max, tt.active) ||
+			state.RecoveryPerMin != tt.recovery || state.Cooldown.Seconds != tt.cooldown || state.Hourly.Cap != tt.hourly {
+			t.Errorf("%s: schedule %q, active %+v, recovery %v, cooldown %v, hourly cap %v",
+				tt.at.Format("Mon 15:04"), state.Schedule, state.Budgets[models.ModeActive], state.RecoveryPerMin, state.Cooldown.Seconds, state.Hourly.Cap)
+		}
+	}
+}
+
+func TestScheduleCapsRemainingBudget(t *testing.T) {
+	g, store, clk := newTestGateway(t, nil)
+	ctx := testContext(models.ModeActive, nil)
+	clk.Set(testStart.Add(-2 * time.Hour)) // 08:00, before deep work
+	if budget := g.State().Budgets[models.ModeActive].Current; budget != 10 {
+		t.Fatalf("budget before the schedule = %v", budget)
+	}
+	if err := store.UpsertSetting(SettingBudgetSchedule, testSchedule); err != nil {
+		t.Fatal(err)
+	}
+
+	clk.Set(testStart)
+	_, decision := g.DryRun(ctx, testAction(models.ActionEncourage))
+	if step := decision.Trace[0]; step.Check != "schedule" || step.Reason != "deep_work" || step.Inputs["budget_max"] != 5 {
+		t.Fatalf("first trace step = %+v", step)
+	}
+	if budget := g.State().Budgets[models.ModeActive].Current; budget != 5 {
+		t.Fatalf("budget in deep work = %v", budget)
+	}
+
+	// Leaving the window raises the cap; the budget recovers at the usual pace.
+	clk.Set(testStart.Add(2*time.Hour - time.Minute))
+	if budget := g.State().Budgets[models.ModeActive].Current; budget != 5 {
+		t.Fatalf("budget at the end of deep work = %v", budget)
+	}
+	clk.Set(testStart.Add(2*time.Hour + 4*time.Minute))
+	if budget := g.State().Budgets[models.ModeActive]; budget.Max != 10 || budget.Current != 7.5 {
+		t.Fatalf("budget after deep work = %+v", budget)
+	}
+}
+
+func TestOvernightProfileBelongsToItsStartDay(t *testing.T) {
+	g, _, clk := newTestGateway(t, map[string]string{
+		SettingBudgetSchedule: `[{"name": "friday_night", "time": "22:00-07:00", "weekdays": ["fri"], "budget_factor": 0.1}]`,
+	})
+	friday := time.Date(2026, 10, 16, 0, 0, 0, 0, time.Local)
+	tests := []struct {
+		at       time.Time
+		schedule string
+	}{
+		{at: friday.Add(6 * time.Hour), schedule: ""},                             // Fri 06:00 is Thursday's night
+		{at: friday.Add(23 * time.Hour), schedule: "friday_night"},                // Fri 23:00
+		{at: friday.Add(24 * time.Hour), schedule: "friday_night"},                // Sat 00:00
+		{at: friday.Add(30*time.Hour + 59*time.Minute), schedule: "friday_night"}, // Sat 06:59
+		{at: friday.Add(31 * time.Hour), schedule: ""},                            // Sat 07:00
+		{at: friday.Add(47 * time.Hour), schedule: ""},                            // Sat 23:00
+		{at: friday.Add(48*time.Hour + time.Hour), schedule: ""},                  // Sun 01:00
+	}
+	for _, tt := range tests {
+		clk.Set(tt.at)
+		if got := g.State().Schedule; got != tt.schedule {
+			t.Errorf("%s: schedule %q, want %q", tt.at.Format("Mon 15:04"), got, tt.schedule)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package gateway
+
+import (
+	"time"
+
+	"always/core/internal/models"
+)
+
+type ModeBudgetState struct {
+	Current float64 `json:"current"`
+	Max     float64 `json:"max"`
+}
+
+type UsageState struct {
+	Bucket string  `json:"bucket"`
+	Used   float64 `json:"used"`
+	// Cap is zero when unlimited.
+	Cap float64 `json:"cap"`
+}
+
+type CooldownState struct {
+	Seconds          float64 `json:"seconds"`
+	RemainingSeconds float64 `json:"remaining_seconds"`
+	LastMs           int64   `json:"last_ms,omitempty"`
+}
+
+type ActionLimitState struct {
+	CooldownSeconds          float64 `json:"cooldown_seconds"`
+	CooldownRemainingSeconds float64 `json:"cooldown_remaining_seconds"`
+	DailyLimit               int     `json:"daily_limit"`
+	DailyCount               int     `json:"daily_count"`
+	LastMs                   int64   `json:"last_ms,omitempty"`
+	// LadderLevel is the rung of Ladder this type currently starts from.
+	LadderLevel   int `json:"ladder_level"`
+	IgnoredStreak int `json:"ignored_streak"`
+}
+
+// State is a snapshot of the gateway's budgets, usage and effective config.
+type State struct {
+	NowMs          int64                                  `json:"now_ms"`
+	Budgets        map[models.Mode]ModeBudgetState        `json:"budgets"`
+	RecoveryPerMin float64                                `json:"recovery_per_minute"`
+	Hourly         UsageState                             `json:"hourly"`
+	Daily          UsageState                             `json:"daily"`
+	Cooldown       CooldownState                          `json:"cooldown"`
+	Actions        map[models.ActionType]ActionLimitState `json:"actions"`
+	Ladder         Ladder                                 `json:"ladder"`
+	Schedule       string                                 `json:"schedule,omitempty"`
+	Rules          string                                 `json:"rules_source"`
+	Adaptation     Adaptation                             `json:"adaptation"`
+}
+
+// State returns the current budgets after recovery up to now. Reading it does
+// not consume anything.
+func (g *Gateway) State() State {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := g.clock.Now()
+	g.refreshConfigLocked()
+	g.loadStateLocked(now)
+
+	state := State{
+		NowMs:          now.UnixMilli(),
+		Budgets:        map[models.Mode]ModeBudgetState{},
+		RecoveryPerMin: g.config.RecoveryRate,
+		Hourly:         UsageState{Bucket: g.hourBucket, Used: g.hourlyUsed, Cap: g.config.HourlyCap},
+		Daily:          UsageState{Bucket: g.dayBucket, Used: g.dailyUsed, Cap: g.config.DailyCap},
+		Cooldown:       g.cooldownStateLocked(now),
+		Actions:        map[models.ActionType]ActionLimitState{},
+		Ladder:         g.config.Ladder,
+		Schedule:       g.schedule,
+		Rules:          g.rules.set.Source,
+		Adaptation:     g.adaptation,
+	}
+	for mode := range g.config.ModeBudgets {
+		g.replenishBudgetLocked(mode, now)
+		state.Budgets[mode] = ModeBudgetState{Current: g.currentBudget[mode], Max: g.modeMaxBudget(mode)}
+	}
+	for actionType := range actionLimitKeys {
+		state.Actions[actionType] = g.actionStateLocked(actionType, now)
+	}
+	return state
+}
+
+func (g *Gateway) actionStateLocked(actionType models.ActionType, now time.Time) ActionLimitState {
+	limit := g.config.ActionLimits[actionType]
+	usage := g.actionUsage[actionType]
+	item := ActionLimitState{
+		CooldownSeconds: limit.Cooldown.Seconds(),
+		DailyLimit:      limit.DailyLimit,
+		LastMs:          usage.LastMs,
+		LadderLevel:     min(usage.LadderLevel, len(g.config.Ladder[actionType])),
+		IgnoredStreak:   usage.IgnoredStreak,
+	}
+	if usage.LastMs > 0 {
+		item.CooldownRemainingSeconds = remainingSeconds(time.UnixMilli(usage.LastMs), limit.Cooldown, now)
+	}
+	if usage.DailyDay == now.Format("2006-01-02") {
+		item.DailyCount = usage.DailyCount
+	}
+	return item
+}
+
+func (g *Gateway) cooldownStateLocked(now time.Time) CooldownState {
+	cooldown := CooldownState{
+		Seconds:          g.config.CooldownSeconds,
+		RemainingSeconds: remainingSeconds(g.lastIntervention, time.Duration(g.config.CooldownSeconds*float64(time.Second)), now),
+	}
+	if !g.lastIntervention.IsZero() {
+		cooldown.LastMs = g.lastIntervention.UnixMilli()
+	}
+	return cooldown
+}
+
+// ruleStateLocked is the RuleState of an action evaluated in mode at now;
+// the mode's budget is expected to be replenished already.
+func (g *Gateway) ruleStateLocked(mode models.Mode, actionType models.ActionType, now time.Time) RuleState {
+	return RuleState{
+		Now:      now,
+		Budget:   ModeBudgetState{Current: g.currentBudget[mode], Max: g.modeMaxBudget(mode)},
+		Hourly:   UsageState{Bucket: g.hourBucket, Used: g.hourlyUsed, Cap: g.config.HourlyCap},
+		Daily:    UsageState{Bucket: g.dayBucket, Used: g.dailyUsed, Cap: g.config.DailyCap},
+		Cooldown: g.cooldownStateLocked(now),
+		Action:   g.actionStateLocked(actionType, now),
+		Schedule: g.schedule,
+	}
+}
+
+func remainingSeconds(last time.Time, cooldown time.Duration, now time.Time) float64 {
+	if last.IsZero() || cooldown <= 0 {
+		return 0
+	}
+	remaining := cooldown - now.Sub(last)
+	if remaining <= 0 || remaining > cooldown {
+		return 0
+	}
+	return remaining.Seconds()
+}
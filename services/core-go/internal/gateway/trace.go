@@ -0,0 +1,31 @@
+package gateway
+
+import "always/core/internal/models"
+
+// Outcomes of a single gateway check.
+const (
+	TracePass    = "pass"
+	TraceBlock   = "block"
+	TraceNoMatch = "no_match"
+	TraceMatch   = "match"
+	TraceSkip    = "skip"
+)
+
+// traceBuilder collects the checks of one evaluation in order.
+type traceBuilder struct {
+	steps []models.GatewayTraceStep
+}
+
+func (t *traceBuilder) add(check string, outcome string, reason string, inputs map[string]float64) {
+	t.steps = append(t.steps, models.GatewayTraceStep{
+		Check:   check,
+		Outcome: outcome,
+		Reason:  reason,
+		Inputs:  inputs,
+	})
+}
+
+func (t *traceBuilder) attach(action models.Action, decision models.GatewayDecision) (models.Action, models.GatewayDecision) {
+	decision.Trace = t.steps
+	return action, decision
+}
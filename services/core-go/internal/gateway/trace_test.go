@@ -0,0 +1,80 @@
+package gateway
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"always/core/internal/models"
+)
+
+func traceChecks(decision models.GatewayDecision) []string {
+	checks := make([]string, 0, len(decision.Trace))
+	for _, step := range decision.Trace {
+		checks = append(checks, step.Check+"="+step.Outcome)
+	}
+	return checks
+}
+
+var ruleChecks = []string{
+	"valid_action=pass", "high_risk=pass", "low_quality=pass", "silent_mode=pass",
+	"rule:meeting=no_match", "rule:focus_session=no_match", "rule:focus_pattern_reframe=no_match",
+	"rule:focused_state=no_match", "rule:late_night=no_match",
+}
+
+var admitChecks = []string{"pricing=pass", "cooldown=pass", "action_limit=pass", "hourly_cap=pass", "daily_cap=pass", "mode_budget=pass"}
+
+func TestTraceAllowed(t *testing.T) {
+	g, _, _ := newTestGateway(t, nil)
+	_, decision := g.Evaluate(testContext(models.ModeActive, nil), testAction(models.ActionEncourage))
+	want := slices.Concat(ruleChecks, admitChecks)
+	if got := traceChecks(decision); !slices.Equal(got, want) {
+		t.Fatalf("trace = %v\nwant    %v", got, want)
+	}
+	budget := decision.Trace[len(decision.Trace)-1].Inputs
+	if budget["budget_before"] != 10 || !approxEqual(budget["budget_after"], 10-decision.Pricing.Cost) || budget["budget_max"] != 10 {
+		t.Fatalf("mode_budget inputs = %v", budget)
+	}
+}
+
+func TestTraceStopsAtBlockingRule(t *testing.T) {
+	g, _, _ := newTestGateway(t, nil)
+	ctx := testContext(models.ModeActive, map[string]string{SignalInMeeting: "true"})
+	_, decision := g.Evaluate(ctx, testAction(models.ActionEncourage))
+	want := slices.Concat(ruleChecks[:4], []string{"rule:meeting=block"})
+	if got := traceChecks(decision); !slices.Equal(got, want) {
+		t.Fatalf("trace = %v\nwant    %v", got, want)
+	}
+	if last := decision.Trace[len(decision.Trace)-1]; last.Reason != ReasonMeeting {
+		t.Fatalf("block reason = %q", last.Reason)
+	}
+
+	risky := testAction(models.ActionEncourage)
+	risky.RiskLevel = models.RiskHigh
+	_, decision = g.Evaluate(testContext(models.ModeActive, nil), risky)
+	if got := traceChecks(decision); !slices.Equal(got, []string{"valid_action=pass", "high_risk=block"}) {
+		t.Fatalf("high risk trace = %v", got)
+	}
+}
+
+func TestTraceRecordsLadderAndCooldown(t *testing.T) {
+	g, _, clk := newTestGateway(t, nil)
+	ctx := testContext(models.ModeActive, nil)
+	g.Evaluate(ctx, testAction(models.ActionEncourage))
+	clk.Advance(time.Minute)
+	_, decision := g.Evaluate(ctx, testAction(models.ActionTaskBreakdown))
+
+	want := slices.Concat(ruleChecks,
+		[]string{"pricing=pass", "cooldown=block", "ladder:ENCOURAGE=match", "pricing=pass", "cooldown=block", "ladder:AMBIENT=match", "pricing=pass", "cooldown=skip"},
+		admitChecks[2:])
+	if got := traceChecks(decision); !slices.Equal(got, want) {
+		t.Fatalf("trace = %v\nwant    %v", got, want)
+	}
+	cooldown := decision.Trace[len(ruleChecks)+1]
+	if cooldown.Reason != ReasonCooldownActive || cooldown.Inputs["remaining_seconds"] != 240 || cooldown.Inputs["cooldown_seconds"] != 300 {
+		t.Fatalf("cooldown step = %+v", cooldown)
+	}
+	if decision.Decision != models.GatewayDegrade || decision.OverriddenActionType != models.ActionTaskBreakdown {
+		t.Fatalf("decision = %+v", decision)
+	}
+}
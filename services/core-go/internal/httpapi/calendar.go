@@ -0,0 +1,66 @@
+package httpapi
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"always/core/internal/calendar"
+	"always/core/internal/db"
+	"always/core/internal/gateway"
+	"always/core/internal/models"
+)
+
+// settingCalendarPath points at a local .ics file or directory and replaces
+// CALENDAR_ICS_PATH.
+const settingCalendarPath = "calendar_ics_path"
+
+func meetingStatus(store *db.Store, meetings *calendar.Source, now time.Time) (calendar.Status, error) {
+	path, _, err := store.GetSetting(settingCalendarPath)
+	if err != nil {
+		return calendar.Status{}, err
+	}
+	return meetings.Status(path, now), nil
+}
+
+// addMeetingSignals sets in_meeting and next_meeting_in_minutes when a
+// calendar is configured.
+func addMeetingSignals(store *db.Store, meetings *calendar.Source, payload *models.Context, now time.Time) error {
+	status, err := meetingStatus(store, meetings, now)
+	if err != nil {
+		return err
+	}
+	if status.Path == "" {
+		return nil
+	}
+	payload.Signals[gateway.SignalInMeeting] = strconv.FormatBool(status.InMeeting)
+	if status.NextInMinutes != nil {
+		payload.Signals[gateway.SignalNextMeetingMinutes] = fmt.Sprintf("%.1f", *status.NextInMinutes)
+	} else {
+		delete(payload.Signals, gateway.SignalNextMeetingMinutes)
+	}
+	return nil
+}
+
+func validateCalendarPath(value string) error {
+	if value == "" {
+		return nil
+	}
+	if _, err := os.Stat(value); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (h *Handler) handleCalendarGet(w http.ResponseWriter, _ *http.Request) {
+	status, err := meetingStatus(h.store, h.calendar, h.clock.Now())
+	if err != nil {
+		h.logger.Error("settings read failed", slog.Any("error", err))
+		respondError(w, http.StatusInternalServerError, "settings error")
+		return
+	}
+	respondJSON(w, http.StatusOK, status)
+}
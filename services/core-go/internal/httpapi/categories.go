@@ -0,0 +1,161 @@
+package httpapi
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"always/core/internal/db"
+	"always/core/internal/focus"
+	"always/core/internal/models"
+)
+
+const categoryWindow = 10 * time.Minute
+
+func (h *Handler) handleFocusCategoriesGet(w http.ResponseWriter, _ *http.Request) {
+	rules, err := h.store.ListFocusCategoryRules()
+	if err != nil {
+		h.logger.Error("list focus categories failed", slog.Any("error", err))
+		respondError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	if rules == nil {
+		rules = []models.FocusCategoryRule{}
+	}
+	respondJSON(w, http.StatusOK, rules)
+}
+
+func (h *Handler) handleFocusCategoriesPost(w http.ResponseWriter, r *http.Request) {
+	var rule models.FocusCategoryRule
+	if err := decodeJSON(r, &rule); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	if err := focus.ValidateCategoryRule(rule); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	rule.ID = 0
+	rule.CreatedAtMs = time.Now().UnixMilli()
+	id, err := h.store.InsertFocusCategoryRule(rule)
+	if err != nil {
+		h.logger.Error("insert focus category failed", slog.Any("error", err))
+		respondError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	rule.ID = id
+	respondJSON(w, http.StatusOK, rule)
+}
+
+func (h *Handler) handleFocusCategoryPut(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	var rule models.FocusCategoryRule
+	if err := decodeJSON(r, &rule); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	if err := focus.ValidateCategoryRule(rule); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	rule.ID = id
+	found, err := h.store.UpdateFocusCategoryRule(rule)
+	if err != nil {
+		h.logger.Error("update focus category failed", slog.Any("error", err))
+		respondError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	if !found {
+		respondError(w, http.StatusNotFound, "category rule not found")
+		return
+	}
+	updated, _, err := h.store.GetFocusCategoryRule(id)
+	if err != nil {
+		h.logger.Error("get focus category failed", slog.Any("error", err))
+		respondError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	respondJSON(w, http.StatusOK, updated)
+}
+
+func (h *Handler) handleFocusCategoryDelete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	found, err := h.store.DeleteFocusCategoryRule(id)
+	if err != nil {
+		h.logger.Error("delete focus category failed", slog.Any("error", err))
+		respondError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	if !found {
+		respondError(w, http.StatusNotFound, "category rule not found")
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (h *Handler) handleFocusCategorySummary(w http.ResponseWriter, r *http.Request) {
+	nowMs := time.Now().UnixMilli()
+	untilMs := nowMs
+	if s := r.URL.Query().Get("until_ms"); s != "" {
+		if parsed, err := parseInt64(s); err == nil && parsed > 0 {
+			untilMs = parsed
+		}
+	}
+	sinceMs := untilMs - categoryWindow.Milliseconds()
+	if s := r.URL.Query().Get("since_ms"); s != "" {
+		if parsed, err := parseInt64(s); err == nil {
+			sinceMs = parsed
+		}
+	}
+	if sinceMs >= untilMs {
+		respondError(w, http.StatusBadRequest, "since_ms must be before until_ms")
+		return
+	}
+	totals, err := categoryDurations(h.store, sinceMs, untilMs, nowMs)
+	if err != nil {
+		h.logger.Error("focus category summary failed", slog.Any("error", err))
+		respondError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	minutes := make(map[string]float64, len(totals))
+	for category, ms := range totals {
+		minutes[category] = float64(ms) / 60000
+	}
+	respondJSON(w, http.StatusOK, map[string]any{
+		"since_ms": sinceMs,
+		"until_ms": untilMs,
+		"minutes":  minutes,
+	})
+}
+
+func loadCategorizer(store *db.Store) (*focus.Categorizer, error) {
+	rules, err := store.ListFocusCategoryRules()
+	if err != nil {
+		return nil, err
+	}
+	return focus.NewCategorizer(rules), nil
+}
+
+func categoryDurations(store *db.Store, sinceMs int64, untilMs int64, nowMs int64) (map[string]int64, error) {
+	categorizer, err := loadCategorizer(store)
+	if err != nil {
+		return nil, err
+	}
+	untilMs = min(untilMs, nowMs)
+	events, err := store.ListFocusEventsRange(sinceMs, untilMs)
+	if err != nil {
+		return nil, err
+	}
+	return focus.CategoryDurations(events, categorizer, sinceMs, untilMs), nil
+}
@@ -0,0 +1,50 @@
+package httpapi
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"always/core/internal/models"
+)
+
+type focusCompactRequest struct {
+	SinceMs int64 `json:"since_ms,omitempty"`
+}
+
+// handleFocusCompact runs the compaction the monitor otherwise runs in the
+// background, over events that started before the switch window.
+func (h *Handler) handleFocusCompact(w http.ResponseWriter, r *http.Request) {
+	var req focusCompactRequest
+	if err := decodeJSON(r, &req); err != nil && !errors.Is(err, io.EOF) {
+		respondError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	var result models.FocusCompaction
+	var err error
+	if h.focus != nil {
+		// The monitor knows which events it still holds.
+		result, err = h.focus.Compact(req.SinceMs)
+	} else {
+		result, err = h.compactStoredEvents(req.SinceMs)
+	}
+	if err != nil {
+		h.logger.Error("compact focus events failed", slog.Any("error", err))
+		respondError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	respondJSON(w, http.StatusOK, result)
+}
+
+func (h *Handler) compactStoredEvents(sinceMs int64) (models.FocusCompaction, error) {
+	thresholds, err := loadFocusThresholds(h.store)
+	if err != nil {
+		return models.FocusCompaction{}, err
+	}
+	window := time.Duration(thresholds.SwitchWindowMinutes) * time.Minute
+	untilMs := h.clock.Now().Add(-window).UnixMilli()
+	peekMs := (time.Duration(thresholds.MicroSwitchSeconds) * time.Second).Milliseconds()
+	return h.store.CompactFocusEvents(sinceMs, untilMs, peekMs)
+}
@@ -0,0 +1,82 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"always/core/internal/gateway"
+)
+
+type gatewayRulesRequest struct {
+	Rules []gateway.RuleSpec `json:"rules"`
+}
+
+func (h *Handler) handleGatewayRulesGet(w http.ResponseWriter, _ *http.Request) {
+	respondJSON(w, http.StatusOK, h.gateway.Rules())
+}
+
+// handleGatewayRulesPut saves rules to the gateway_rules setting, which takes
+// precedence over the rules file until it is deleted.
+func (h *Handler) handleGatewayRulesPut(w http.ResponseWriter, r *http.Request) {
+	rules, ok := decodeGatewayRules(w, r)
+	if !ok {
+		return
+	}
+	encoded, err := json.Marshal(rules)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := h.store.UpsertSetting(gateway.SettingGatewayRules, string(encoded)); err != nil {
+		h.logger.Error("update gateway rules failed", slog.Any("error", err))
+		respondError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	respondJSON(w, http.StatusOK, h.gateway.Rules())
+}
+
+// handleGatewayRulesDelete drops the saved rules so the gateway falls back to
+// the rules file or the built-in rules.
+func (h *Handler) handleGatewayRulesDelete(w http.ResponseWriter, _ *http.Request) {
+	if _, err := h.store.DeleteSetting(gateway.SettingGatewayRules); err != nil {
+		h.logger.Error("delete gateway rules failed", slog.Any("error", err))
+		respondError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	respondJSON(w, http.StatusOK, h.gateway.Rules())
+}
+
+func (h *Handler) handleGatewayRulesValidate(w http.ResponseWriter, r *http.Request) {
+	rules, ok := decodeGatewayRules(w, r)
+	if !ok {
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]any{"valid": true, "rules": len(rules)})
+}
+
+func decodeGatewayRules(w http.ResponseWriter, r *http.Request) ([]gateway.RuleSpec, bool) {
+	var req gatewayRulesRequest
+	if err := decodeJSON(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid json: "+err.Error())
+		return nil, false
+	}
+	if req.Rules == nil {
+		respondError(w, http.StatusBadRequest, "rules required")
+		return nil, false
+	}
+	if err := gateway.ValidateRules(req.Rules); err != nil {
+		var ruleErrs gateway.RuleErrors
+		if errors.As(err, &ruleErrs) {
+			respondJSON(w, http.StatusBadRequest, map[string]any{
+				"error":  "invalid gateway rules",
+				"errors": ruleErrs,
+			})
+			return nil, false
+		}
+		respondError(w, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+	return req.Rules, true
+}
@@ -0,0 +1,61 @@
+package httpapi
+
+import (
+	"log/slog"
+	"net/http"
+
+	"always/core/internal/models"
+)
+
+type gatewayEvaluateRequest struct {
+	Context models.Context `json:"context"`
+	Action  models.Action  `json:"action"`
+	// Enrich adds the live focus and session signals /v1/decision would add.
+	Enrich bool `json:"enrich,omitempty"`
+}
+
+type gatewayEvaluateResponse struct {
+	Action          models.Action          `json:"action"`
+	GatewayDecision models.GatewayDecision `json:"gateway_decision"`
+	Context         models.Context         `json:"context"`
+}
+
+func (h *Handler) handleGatewayState(w http.ResponseWriter, _ *http.Request) {
+	respondJSON(w, http.StatusOK, h.gateway.State())
+}
+
+// handleGatewayEvaluate runs the gateway on a supplied context and action
+// without consuming budget or starting a cooldown.
+func (h *Handler) handleGatewayEvaluate(w http.ResponseWriter, r *http.Request) {
+	var req gatewayEvaluateRequest
+	if err := decodeJSON(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	now := h.clock.Now()
+	if req.Context.Timestamp == 0 {
+		req.Context.Timestamp = now.UnixMilli()
+	}
+	if req.Context.Signals == nil {
+		req.Context.Signals = map[string]string{}
+	}
+	if err := validateContext(req.Context); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.Enrich {
+		// A dry run records nothing, so the derived focus state is not
+		// stored as a snapshot.
+		if _, err := collectSignals(h.store, h.focus, h.calendar, &req.Context, now); err != nil {
+			h.logger.Error("settings read failed", slog.Any("error", err))
+			respondError(w, http.StatusInternalServerError, "settings error")
+			return
+		}
+	}
+	action, decision := h.gateway.DryRun(req.Context, req.Action)
+	respondJSON(w, http.StatusOK, gatewayEvaluateResponse{
+		Action:          action,
+		GatewayDecision: decision,
+		Context:         req.Context,
+	})
+}
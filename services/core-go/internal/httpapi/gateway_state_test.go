@@ -0,0 +1,153 @@
+package httpapi
+
+import (
+	"math"
+	"net/http"
+	"testing"
+	"time"
+
+	"always/core/internal/focus"
+	"always/core/internal/gateway"
+	"always/core/internal/models"
+)
+
+func approx(a, b float64) bool { return math.Abs(a-b) < 1e-6 }
+
+func TestGatewayState(t *testing.T) {
+	env := newTestEnv(t)
+	var state gateway.State
+	if status := env.call(t, http.MethodGet, "/v1/gateway/state", nil, &state); status != http.StatusOK {
+		t.Fatalf("state = %d", status)
+	}
+	if active := state.Budgets[models.ModeActive]; active.Current != 10 || active.Max != 10 {
+		t.Fatalf("fresh active budget = %+v", active)
+	}
+	if state.Cooldown.Seconds != 300 || state.Cooldown.RemainingSeconds != 0 || state.Rules != gateway.RulesSourceDefault {
+		t.Fatalf("fresh state = %+v", state)
+	}
+
+	resp := env.decide(t, models.ModeActive, nil)
+	if resp.Action.ActionType != models.ActionEncourage || resp.GatewayDecision.Pricing == nil {
+		t.Fatalf("decision = %+v", resp.GatewayDecision)
+	}
+	cost := resp.GatewayDecision.Pricing.Cost
+
+	env.clock.Advance(time.Minute)
+	env.call(t, http.MethodGet, "/v1/gateway/state", nil, &state)
+	// One minute recovers 0.5 points.
+	if active := state.Budgets[models.ModeActive]; !approx(active.Current, 10-cost+0.5) {
+		t.Fatalf("active budget = %+v, cost %v", active, cost)
+	}
+	if state.Cooldown.RemainingSeconds != 240 || state.Cooldown.LastMs != testStart.UnixMilli() {
+		t.Fatalf("cooldown = %+v", state.Cooldown)
+	}
+	if !approx(state.Daily.Used, cost) || !approx(state.Hourly.Used, cost) || state.Daily.Bucket != "2026-10-12" {
+		t.Fatalf("usage = %+v %+v", state.Hourly, state.Daily)
+	}
+	if encourage := state.Actions[models.ActionEncourage]; encourage.DailyCount != 1 || encourage.LastMs != testStart.UnixMilli() {
+		t.Fatalf("encourage usage = %+v", encourage)
+	}
+	if rest := state.Actions[models.ActionRestReminder]; rest.CooldownSeconds != 2700 {
+		t.Fatalf("rest reminder limits = %+v", rest)
+	}
+}
+
+func TestGatewayEvaluateConsumesNothing(t *testing.T) {
+	env := newTestEnv(t)
+	req := map[string]any{
+		"context": models.Context{Mode: models.ModeActive},
+		"action":  env.action,
+	}
+	for range 3 {
+		var resp gatewayEvaluateResponse
+		if status := env.call(t, http.MethodPost, "/v1/gateway/evaluate", req, &resp); status != http.StatusOK {
+			t.Fatalf("evaluate = %d", status)
+		}
+		if resp.Action.ActionType != models.ActionEncourage || resp.GatewayDecision.Decision != models.GatewayAllow {
+			t.Fatalf("evaluate = %+v", resp)
+		}
+		if resp.Context.Timestamp != testStart.UnixMilli() {
+			t.Fatalf("timestamp not defaulted: %d", resp.Context.Timestamp)
+		}
+	}
+	var state gateway.State
+	env.call(t, http.MethodGet, "/v1/gateway/state", nil, &state)
+	if state.Budgets[models.ModeActive].Current != 10 || state.Cooldown.LastMs != 0 || state.Daily.Used != 0 {
+		t.Fatalf("dry run consumed budget: %+v", state)
+	}
+
+	// During a real cooldown the dry run reports what would happen.
+	env.decide(t, models.ModeActive, nil)
+	var resp gatewayEvaluateResponse
+	env.call(t, http.MethodPost, "/v1/gateway/evaluate", req, &resp)
+	if resp.Action.ActionType != models.ActionAmbient || resp.GatewayDecision.Reason != gateway.ReasonCooldownActive {
+		t.Fatalf("evaluate during cooldown = %+v", resp)
+	}
+
+	bad := map[string]any{"context": models.Context{Mode: "LOUD"}, "action": env.action}
+	if status := env.call(t, http.MethodPost, "/v1/gateway/evaluate", bad, nil); status != http.StatusBadRequest {
+		t.Fatalf("invalid mode = %d", status)
+	}
+}
+
+func TestGatewayEvaluateEnrich(t *testing.T) {
+	env := newTestEnv(t)
+	if status := env.call(t, http.MethodPost, "/v1/sessions", models.FocusSessionStartRequest{Name: "deep work", TargetMinutes: 50}, nil); status != http.StatusOK {
+		t.Fatalf("start session = %d", status)
+	}
+	req := map[string]any{
+		"context": models.Context{Mode: models.ModeActive},
+		"action":  env.action,
+	}
+	var plain, enriched gatewayEvaluateResponse
+	env.call(t, http.MethodPost, "/v1/gateway/evaluate", req, &plain)
+	req["enrich"] = true
+	env.call(t, http.MethodPost, "/v1/gateway/evaluate", req, &enriched)
+
+	if plain.Context.Signals[gateway.SignalFocusSessionActive] != "" || len(plain.GatewayDecision.Pricing.Multipliers) != 0 {
+		t.Fatalf("plain evaluate was enriched: %+v", plain)
+	}
+	if enriched.Context.Signals[gateway.SignalFocusSessionActive] != "true" {
+		t.Fatalf("enriched signals = %v", enriched.Context.Signals)
+	}
+	pricing := enriched.GatewayDecision.Pricing
+	if pricing == nil || len(pricing.Multipliers) != 1 || pricing.Multipliers[0].Rule != "focus_session" {
+		t.Fatalf("enriched pricing = %+v", pricing)
+	}
+}
+
+func TestGatewayEvaluateEnrichWritesNothing(t *testing.T) {
+	env := newTestEnv(t)
+	env.observe(0, focus.FocusSnapshot{AppName: "Code", PID: 1})
+	env.clock.Set(testStart.Add(time.Minute))
+	snapshots := func() int {
+		var count int
+		if err := env.store.DB().QueryRow(`SELECT COUNT(*) FROM focus_state_snapshots`).Scan(&count); err != nil {
+			t.Fatal(err)
+		}
+		return count
+	}
+
+	before := snapshots()
+	req := map[string]any{
+		"context": models.Context{Mode: models.ModeActive},
+		"action":  env.action,
+		"enrich":  true,
+	}
+	var enriched gatewayEvaluateResponse
+	if status := env.call(t, http.MethodPost, "/v1/gateway/evaluate", req, &enriched); status != http.StatusOK {
+		t.Fatalf("evaluate status = %d", status)
+	}
+	if enriched.Context.Signals["focus_state"] == "" {
+		t.Fatalf("enriched signals = %v, want a focus_state", enriched.Context.Signals)
+	}
+	if after := snapshots(); after != before {
+		t.Fatalf("focus_state_snapshots = %d after a dry run, want %d", after, before)
+	}
+
+	// A real decision still records the snapshot.
+	env.decide(t, models.ModeActive, nil)
+	if after := snapshots(); after != before+1 {
+		t.Fatalf("focus_state_snapshots = %d after a decision, want %d", after, before+1)
+	}
+}
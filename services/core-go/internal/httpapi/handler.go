@@ -18,6 +18,8 @@ import (
 	"github.com/google/uuid"
 
 	"always/core/internal/ai"
+	"always/core/internal/calendar"
+	"always/core/internal/clock"
 	"always/core/internal/db"
 	"always/core/internal/focus"
 	"always/core/internal/gateway"
@@ -29,58 +31,124 @@ const (
 	settingQuietHours         = "quiet_hours"
 	settingInterventionBudget = "intervention_budget"
 	settingFocusMonitor       = "focus_monitor_enabled"
+	settingFocusProviderCmd   = "focus_provider_cmd"
+	settingFocusIdleThreshold = "focus_idle_threshold_seconds"
+	settingTitleRedaction     = "title_redaction_rules"
 	settingOllamaModel        = "ollama_model"
 	settingAgentEnabled       = "agent_enabled"
 	settingRuleOnlyMode       = "rule_only_mode"
+	settingAdaptiveBudget     = "adaptive_budget_enabled"
 	settingBudgetSilent       = "budget_silent"
 	settingBudgetLight        = "budget_light"
 	settingBudgetActive       = "budget_active"
 	settingDailyBudgetCap     = "daily_budget_cap"
 	settingHourlyBudgetCap    = "hourly_budget_cap"
 	settingCooldownSeconds    = "cooldown_seconds"
+	settingLadderThreshold    = "ladder_ignore_threshold"
 	settingLastAutoSuggestMs  = "last_auto_suggestion_ms"
+
+	settingCooldownEncourage       = "cooldown_encourage_seconds"
+	settingCooldownTaskBreakdown   = "cooldown_task_breakdown_seconds"
+	settingCooldownRestReminder    = "cooldown_rest_reminder_seconds"
+	settingCooldownReframe         = "cooldown_reframe_seconds"
+	settingDailyLimitEncourage     = "daily_limit_encourage"
+	settingDailyLimitTaskBreakdown = "daily_limit_task_breakdown"
+	settingDailyLimitRestReminder  = "daily_limit_rest_reminder"
+	settingDailyLimitReframe       = "daily_limit_reframe"
 )
 
 var allowedSettings = map[string]bool{
 	settingQuietHours:         true,
 	settingInterventionBudget: true,
 	settingFocusMonitor:       true,
+	settingFocusProviderCmd:   true,
+	settingFocusIdleThreshold: true,
+	settingTitleRedaction:     true,
 	settingOllamaModel:        true,
 	settingAgentEnabled:       true,
 	settingRuleOnlyMode:       true,
+	settingAdaptiveBudget:     true,
 	settingBudgetSilent:       true,
 	settingBudgetLight:        true,
 	settingBudgetActive:       true,
 	settingDailyBudgetCap:     true,
 	settingHourlyBudgetCap:    true,
 	settingCooldownSeconds:    true,
+	settingLadderThreshold:    true,
+
+	settingCooldownEncourage:       true,
+	settingCooldownTaskBreakdown:   true,
+	settingCooldownRestReminder:    true,
+	settingCooldownReframe:         true,
+	settingDailyLimitEncourage:     true,
+	settingDailyLimitTaskBreakdown: true,
+	settingDailyLimitRestReminder:  true,
+	settingDailyLimitReframe:       true,
+
+	settingFocusSwitchWindow:       true,
+	settingFocusNoProgressHold:     true,
+	settingStateNoProgressMinutes:  true,
+	settingStateSwitchCount:        true,
+	settingStateFocusedMinutes:     true,
+	settingStateDistractingMinutes: true,
+	settingPatternPingPongSwitches: true,
+	settingPatternPeekSeconds:      true,
+	settingPatternPeekCount:        true,
+	settingPatternReturnMinutes:    true,
+	settingMicroSwitchSeconds:      true,
+	gateway.SettingGatewayRules:    true,
+	gateway.SettingGatewayLadder:   true,
+	gateway.SettingBudgetSchedule:  true,
+	settingQuietCalendar:           true,
+	settingCalendarPath:            true,
 }
 
 const autoSuggestionWindow = 10 * time.Minute
 
+// autoSuggestionTypes are the interventions an automatic suggestion may turn
+// into; it is skipped when the per-type limits rule out all of them.
+var autoSuggestionTypes = []models.ActionType{
+	models.ActionEncourage,
+	models.ActionTaskBreakdown,
+	models.ActionRestReminder,
+	models.ActionReframe,
+}
+
 type Handler struct {
-	store   *db.Store
-	ai      *ai.Client
-	focus   *focus.Monitor
-	memory  *memory.Service
-	gateway *gateway.Gateway
-	started time.Time
-	logger  *slog.Logger
+	store    *db.Store
+	ai       *ai.Client
+	focus    *focus.Monitor
+	memory   *memory.Service
+	gateway  *gateway.Gateway
+	calendar *calendar.Source
+	clock    clock.Clock
+	started  time.Time
+	logger   *slog.Logger
 }
 
-func NewHandler(store *db.Store, aiClient *ai.Client, focusMonitor *focus.Monitor, memoryService *memory.Service, started time.Time, logger *slog.Logger) *Handler {
-	gw := gateway.New(logger, store)
+// NewHandler wires the API to its services; gatewayOptions customize the
+// gateway, e.g. to add Go rules when embedding the core.
+func NewHandler(store *db.Store, aiClient *ai.Client, focusMonitor *focus.Monitor, memoryService *memory.Service, started time.Time, logger *slog.Logger, gatewayOptions ...gateway.Option) *Handler {
+	gw := gateway.New(logger, store, gatewayOptions...)
 	return &Handler{
-		store:   store,
-		ai:      aiClient,
-		focus:   focusMonitor,
-		memory:  memoryService,
-		gateway: gw,
-		started: started,
-		logger:  logger,
+		store:    store,
+		ai:       aiClient,
+		focus:    focusMonitor,
+		memory:   memoryService,
+		gateway:  gw,
+		calendar: calendar.NewSource(logger),
+		clock:    clock.System,
+		started:  started,
+		logger:   logger,
 	}
 }
 
+// SetClock makes the decision pipeline and its gateway read time from c.
+func (h *Handler) SetClock(c clock.Clock) {
+	h.clock = c
+	h.gateway.SetClock(c)
+}
+
 func (h *Handler) Router() chi.Router {
 	r := chi.NewRouter()
 	r.Use(corsMiddleware)
@@ -92,9 +160,35 @@ func (h *Handler) Router() chi.Router {
 	r.Get("/v1/logs", h.handleLogs)
 	r.Get("/v1/focus/current", h.handleFocusCurrent)
 	r.Get("/v1/focus/recent", h.handleFocusRecent)
+	r.Get("/v1/focus/timeline", h.handleFocusTimeline)
+	r.Get("/v1/focus/heatmap", h.handleFocusHeatmap)
+	r.Post("/v1/focus/compact", h.handleFocusCompact)
+	r.Post("/v1/focus/events", h.handleFocusEventsIngest)
+	r.Post("/v1/focus/redaction/preview", h.handleRedactionPreview)
+	r.Get("/v1/focus/categories", h.handleFocusCategoriesGet)
+	r.Post("/v1/focus/categories", h.handleFocusCategoriesPost)
+	r.Get("/v1/focus/categories/summary", h.handleFocusCategorySummary)
+	r.Put("/v1/focus/categories/{id}", h.handleFocusCategoryPut)
+	r.Delete("/v1/focus/categories/{id}", h.handleFocusCategoryDelete)
+	r.Post("/v1/sessions", h.handleSessionStart)
+	r.Get("/v1/sessions", h.handleSessionList)
+	r.Get("/v1/sessions/current", h.handleSessionCurrent)
+	r.Get("/v1/sessions/{id}", h.handleSessionGet)
+	r.Post("/v1/sessions/{id}/pause", h.handleSessionPause)
+	r.Post("/v1/sessions/{id}/resume", h.handleSessionResume)
+	r.Post("/v1/sessions/{id}/end", h.handleSessionEnd)
+	r.Get("/v1/quiet", h.handleQuietGet)
+	r.Get("/v1/calendar", h.handleCalendarGet)
+	r.Get("/v1/gateway/state", h.handleGatewayState)
+	r.Post("/v1/gateway/evaluate", h.handleGatewayEvaluate)
+	r.Get("/v1/gateway/rules", h.handleGatewayRulesGet)
+	r.Put("/v1/gateway/rules", h.handleGatewayRulesPut)
+	r.Delete("/v1/gateway/rules", h.handleGatewayRulesDelete)
+	r.Post("/v1/gateway/rules/validate", h.handleGatewayRulesValidate)
 	r.Get("/v1/export", h.handleExport)
 	r.Get("/v1/ollama/models", h.handleOllamaModels)
 	r.Get("/v1/settings", h.handleSettingsGet)
+	r.Get("/v1/settings/schema", h.handleSettingsSchema)
 	r.Post("/v1/settings", h.handleSettingsPost)
 	r.Get("/v1/profile", h.handleProfile)
 	r.Get("/v1/learning/explanations", h.handleLearningExplanations)
@@ -120,8 +214,9 @@ func (h *Handler) handleDecision(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	}
+	now := h.clock.Now()
 	if req.Context.Timestamp == 0 {
-		req.Context.Timestamp = time.Now().UnixMilli()
+		req.Context.Timestamp = now.UnixMilli()
 	}
 	if req.Context.Signals == nil {
 		req.Context.Signals = map[string]string{}
@@ -142,7 +237,7 @@ func (h *Handler) handleDecision(w http.ResponseWriter, r *http.Request) {
 		h.logger.Info("user text detected, cooldown cleared for conversation")
 	}
 
-	if err := enrichSignals(h.store, h.focus, &req.Context); err != nil {
+	if err := enrichSignals(h.store, h.focus, h.calendar, &req.Context, now); err != nil {
 		h.logger.Error("settings read failed", slog.String("request_id", requestID), slog.Any("error", err))
 		respondError(w, http.StatusInternalServerError, "settings error")
 		return
@@ -169,16 +264,10 @@ func (h *Handler) handleDecision(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	quietHours := req.Context.Signals["quiet_hours"]
-	if quietHours == "" {
-		if value, ok, err := h.store.GetSetting(settingQuietHours); err == nil && ok {
-			quietHours = value
-		}
-	}
-	if quietHours != "" && withinQuietHours(time.Now(), quietHours) {
+	if req.Context.Signals[signalQuietNow] == "true" {
 		action := models.Action{
 			ActionType: models.ActionDoNotDisturb,
-			Message:    "安静时段内，已暂停提示。",
+			Message:    quietMessage(req.Context.Signals),
 			Confidence: 1,
 			Cost:       0,
 			RiskLevel:  models.RiskLow,
@@ -188,7 +277,7 @@ func (h *Handler) handleDecision(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if req.Context.UserText == "" {
-		allowed, reason, err := h.shouldAllowAutoSuggestion(req.Context)
+		allowed, reason, err := h.shouldAllowAutoSuggestion(req.Context, now)
 		if err != nil {
 			h.logger.Error("auto suggestion check failed", slog.String("request_id", requestID), slog.Any("error", err))
 			respondError(w, http.StatusInternalServerError, "auto suggestion error")
@@ -217,7 +306,7 @@ func (h *Handler) handleDecision(w http.ResponseWriter, r *http.Request) {
 	}
 
 	finalAction, gatewayDecision := h.gateway.Evaluate(req.Context, rawAction)
-	createdAt := time.Now()
+	createdAt := h.clock.Now()
 
 	resp := models.DecisionResponse{
 		RequestID:       requestID,
@@ -313,6 +402,14 @@ func (h *Handler) handleFeedback(w http.ResponseWriter, r *http.Request) {
 		h.logger.Error("process feedback failed", slog.String("request_id", req.RequestID), slog.Any("error", err))
 	}
 
+	// Move the proposed action type along its ladder; feedback on a blocked
+	// suggestion says nothing about it
+	if raw, final, found, err := h.store.GetDecisionActions(req.RequestID); err != nil {
+		h.logger.Error("load decision actions failed", slog.String("request_id", req.RequestID), slog.Any("error", err))
+	} else if found && final.ActionType != models.ActionDoNotDisturb {
+		h.gateway.ApplyFeedback(raw.ActionType, req.Feedback)
+	}
+
 	// Clear gateway cooldown to allow continued interaction after user feedback
 	h.gateway.ClearCooldown()
 
@@ -327,14 +424,14 @@ func (h *Handler) handleFeedback(w http.ResponseWriter, r *http.Request) {
 		// Use feedback text as user input for new decision
 		req.Context.UserText = req.FeedbackText
 		if req.Context.Timestamp == 0 {
-			req.Context.Timestamp = time.Now().UnixMilli()
+			req.Context.Timestamp = h.clock.Now().UnixMilli()
 		}
 		if req.Context.Signals == nil {
 			req.Context.Signals = map[string]string{}
 		}
 
 		// Enrich context
-		if err := enrichSignals(h.store, h.focus, &req.Context); err != nil {
+		if err := enrichSignals(h.store, h.focus, h.calendar, &req.Context, h.clock.Now()); err != nil {
 			h.logger.Warn("failed to enrich signals for reply", slog.Any("error", err))
 		}
 		req.Context.ProfileSummary = h.memory.GetProfileSummary()
@@ -353,7 +450,7 @@ func (h *Handler) handleFeedback(w http.ResponseWriter, r *http.Request) {
 		}
 
 		finalAction, gatewayDecision := h.gateway.Evaluate(req.Context, rawAction)
-		createdAt := time.Now()
+		createdAt := h.clock.Now()
 
 		resp := models.DecisionResponse{
 			RequestID:       newRequestID,
@@ -436,10 +533,15 @@ func (h *Handler) handleLogs(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) handleFocusCurrent(w http.ResponseWriter, r *http.Request) {
-	if h.focus == nil || !h.focus.Enabled() {
+	if h.focus == nil {
 		respondJSON(w, http.StatusOK, models.FocusCurrent{})
 		return
 	}
+	health := h.focus.ProviderHealth()
+	if !h.focus.Enabled() {
+		respondJSON(w, http.StatusOK, models.FocusCurrent{Provider: health})
+		return
+	}
 	current, ok, err := h.focus.Current()
 	if err != nil {
 		h.logger.Error("focus current failed", slog.Any("error", err))
@@ -447,9 +549,15 @@ func (h *Handler) handleFocusCurrent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if !ok {
-		respondJSON(w, http.StatusOK, models.FocusCurrent{})
+		respondJSON(w, http.StatusOK, models.FocusCurrent{Provider: health})
 		return
 	}
+	current.Provider = health
+	if thresholds, err := loadFocusThresholds(h.store); err == nil {
+		if patterns, err := detectFocusPatterns(h.store, h.focus, h.clock.Now().UnixMilli(), thresholds); err == nil {
+			current.Patterns = &patterns
+		}
+	}
 	respondJSON(w, http.StatusOK, current)
 }
 
@@ -466,6 +574,22 @@ func (h *Handler) handleFocusRecent(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusInternalServerError, "db error")
 		return
 	}
+	categorizer, err := loadCategorizer(h.store)
+	if err != nil {
+		h.logger.Error("load focus categories failed", slog.Any("error", err))
+		respondError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	categorizer.Annotate(events)
+	if category := r.URL.Query().Get("category"); category != "" {
+		filtered := make([]models.FocusEvent, 0, len(events))
+		for _, event := range events {
+			if event.Category == category {
+				filtered = append(filtered, event)
+			}
+		}
+		events = filtered
+	}
 	respondJSON(w, http.StatusOK, events)
 }
 
@@ -549,6 +673,34 @@ func (h *Handler) handleSettingsPost(w http.ResponseWriter, r *http.Request) {
 			h.logger.Error("focus toggle failed", slog.Any("error", err))
 		}
 	}
+	if req.Key == settingFocusIdleThreshold && h.focus != nil {
+		seconds, _ := strconv.Atoi(req.Value)
+		h.focus.SetIdleThreshold(time.Duration(seconds) * time.Second)
+	}
+	if req.Key == settingFocusSwitchWindow && h.focus != nil {
+		minutes, _ := strconv.Atoi(req.Value)
+		h.focus.SetSwitchWindow(time.Duration(minutes) * time.Minute)
+	}
+	if req.Key == settingFocusNoProgressHold && h.focus != nil {
+		minutes, _ := strconv.Atoi(req.Value)
+		h.focus.SetNoProgressHold(time.Duration(minutes) * time.Minute)
+	}
+	if req.Key == settingMicroSwitchSeconds && h.focus != nil {
+		seconds, _ := strconv.Atoi(req.Value)
+		h.focus.SetMicroSwitchThreshold(time.Duration(seconds) * time.Second)
+	}
+	if req.Key == settingTitleRedaction && h.focus != nil {
+		if rules, err := focus.ParseRedactionRules(req.Value); err == nil {
+			if err := h.focus.SetRedactionRules(rules); err != nil {
+				h.logger.Error("apply redaction rules failed", slog.Any("error", err))
+			}
+		}
+	}
+	if req.Key == settingFocusProviderCmd && h.focus != nil {
+		if err := h.focus.SetProviderCommand(req.Value); err != nil && !errors.Is(err, focus.ErrUnsupported) {
+			h.logger.Error("focus provider switch failed", slog.Any("error", err))
+		}
+	}
 	respondJSON(w, http.StatusOK, map[string]string{"status": "ok"})
 }
 
@@ -691,8 +843,26 @@ func respondError(w http.ResponseWriter, status int, message string) {
 }
 
 func (h *Handler) respondWithAction(w http.ResponseWriter, requestID string, ctx models.Context, rawAction models.Action, policyVersion string, modelVersion string, latency int64) {
+	resp, err := h.recordDecision(requestID, ctx, rawAction, policyVersion, modelVersion, latency)
+	if err != nil {
+		h.logger.Error("insert decision failed", slog.String("request_id", requestID), slog.Any("error", err))
+		respondError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// recordDecision runs a core-generated action through the gateway and logs it
+// like any AI decision.
+func (h *Handler) recordDecision(requestID string, ctx models.Context, rawAction models.Action, policyVersion string, modelVersion string, latency int64) (models.DecisionResponse, error) {
 	finalAction, gatewayDecision := h.gateway.Evaluate(ctx, rawAction)
-	createdAt := time.Now()
+	switch policyVersion {
+	case "quiet_hours", "auto_guard":
+		// The core stayed silent before asking the policy; say so in the trace.
+		step := models.GatewayTraceStep{Check: policyVersion, Outcome: gateway.TraceBlock, Reason: rawAction.Message}
+		gatewayDecision.Trace = append([]models.GatewayTraceStep{step}, gatewayDecision.Trace...)
+	}
+	createdAt := h.clock.Now()
 	resp := models.DecisionResponse{
 		RequestID:       requestID,
 		Context:         ctx,
@@ -717,11 +887,9 @@ func (h *Handler) respondWithAction(w http.ResponseWriter, requestID string, ctx
 		CreatedAtMs:     createdAt.UnixMilli(),
 	}
 	if err := h.store.InsertDecision(logEntry); err != nil {
-		h.logger.Error("insert decision failed", slog.String("request_id", requestID), slog.Any("error", err))
-		respondError(w, http.StatusInternalServerError, "db error")
-		return
+		return models.DecisionResponse{}, err
 	}
-	respondJSON(w, http.StatusOK, resp)
+	return resp, nil
 }
 
 func parseInt(val string) (int, error) {
@@ -804,7 +972,7 @@ func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, X-Request-ID")
-		w.Header().Set("Access-Control-Allow-Methods", "GET,POST,OPTIONS")
+		w.Header().Set("Access-Control-Allow-Methods", "GET,POST,PUT,DELETE,OPTIONS")
 		if r.Method == http.MethodOptions {
 			w.WriteHeader(http.StatusNoContent)
 			return
@@ -881,23 +1049,65 @@ func validateFeedback(req models.FeedbackRequest) error {
 	return nil
 }
 
-func enrichSignals(store *db.Store, focusMonitor *focus.Monitor, payload *models.Context) error {
-	payload.Signals["hour_of_day"] = strconv.Itoa(time.Now().Hour())
+// enrichSignals adds the derived signals to payload and records the focus
+// state it derived as a snapshot.
+func enrichSignals(store *db.Store, focusMonitor *focus.Monitor, meetings *calendar.Source, payload *models.Context, now time.Time) error {
+	snapshot, err := collectSignals(store, focusMonitor, meetings, payload, now)
+	if err != nil {
+		return err
+	}
+	if snapshot != nil {
+		_ = store.InsertFocusStateSnapshot(*snapshot)
+	}
+	return nil
+}
+
+// collectSignals adds the derived signals to payload without writing anything.
+// It returns the focus state snapshot to record when the monitor supplied the
+// current focus.
+func collectSignals(store *db.Store, focusMonitor *focus.Monitor, meetings *calendar.Source, payload *models.Context, now time.Time) (*models.FocusStateSnapshot, error) {
+	var snapshot *models.FocusStateSnapshot
+	payload.Signals["hour_of_day"] = strconv.Itoa(now.Hour())
+	session, inSession, err := store.CurrentFocusSession()
+	if err != nil {
+		return nil, err
+	}
+	if inSession && session.Status == models.SessionActive {
+		payload.Signals[gateway.SignalFocusSessionActive] = "true"
+		payload.Signals["focus_session_name"] = session.Name
+		if session.Task != "" {
+			payload.Signals["focus_session_task"] = session.Task
+		}
+		if _, ok := payload.Signals["session_minutes"]; !ok {
+			activeMs := sessionActiveMs(session, now.UnixMilli())
+			payload.Signals["session_minutes"] = fmt.Sprintf("%.1f", float64(activeMs)/60000)
+		}
+	}
 	if _, ok := payload.Signals["session_minutes"]; !ok {
 		payload.Signals["session_minutes"] = "0"
 	}
 
-	quietHours, ok, err := store.GetSetting(settingQuietHours)
+	quietDays, quietHours, err := loadQuietCalendar(store)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if ok && quietHours != "" {
+	if quietHours != "" {
 		payload.Signals["quiet_hours"] = quietHours
 	}
+	quiet := quietDays.status(now, payload.Signals["quiet_hours"])
+	payload.Signals[signalQuietNow] = strconv.FormatBool(quiet.Quiet)
+	if quiet.Quiet {
+		payload.Signals[signalQuietUntilMs] = strconv.FormatInt(quiet.UntilMs, 10)
+	} else {
+		delete(payload.Signals, signalQuietUntilMs)
+	}
+	if err := addMeetingSignals(store, meetings, payload, now); err != nil {
+		return nil, err
+	}
 
 	budgetSetting, ok, err := store.GetSetting(settingInterventionBudget)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if ok {
 		budgetValue := normalizeBudget(budgetSetting)
@@ -908,12 +1118,29 @@ func enrichSignals(store *db.Store, focusMonitor *focus.Monitor, payload *models
 
 	modelSetting, ok, err := store.GetSetting(settingOllamaModel)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if ok && modelSetting != "" {
 		payload.Signals["ollama_model"] = modelSetting
 	}
 
+	thresholds, err := loadFocusThresholds(store)
+	if err != nil {
+		return nil, err
+	}
+	nowMs := now.UnixMilli()
+	var distractingMinutes float64
+	if totals, err := categoryDurations(store, nowMs-categoryWindow.Milliseconds(), nowMs, nowMs); err == nil {
+		distractingMinutes = float64(totals[models.CategoryDistracting]) / 60000
+		payload.Signals["distracting_minutes_10m"] = fmt.Sprintf("%.1f", distractingMinutes)
+		payload.Signals["productive_minutes_10m"] = fmt.Sprintf("%.1f", float64(totals[models.CategoryProductive])/60000)
+	}
+	var pattern string
+	if patterns, err := detectFocusPatterns(store, focusMonitor, nowMs, thresholds); err == nil {
+		pattern = patterns.Pattern
+		setPatternSignals(payload.Signals, patterns)
+	}
+
 	if focusMonitor != nil && focusMonitor.Enabled() {
 		switchCount := focusMonitor.SwitchCount()
 		payload.SwitchCount = switchCount
@@ -922,10 +1149,12 @@ func enrichSignals(store *db.Store, focusMonitor *focus.Monitor, payload *models
 		if noProgress {
 			payload.Signals["no_progress_minutes"] = fmt.Sprintf("%.1f", noProgressDuration.Minutes())
 		}
+		idle, idleDuration := focusMonitor.Idle()
+		payload.Signals["idle_minutes"] = fmt.Sprintf("%.1f", idleDuration.Minutes())
 
 		current, ok, err := focusMonitor.Current()
 		if err != nil {
-			return nil
+			return nil, nil
 		}
 		if ok {
 			if _, exists := payload.Signals["focus_app"]; !exists {
@@ -940,30 +1169,50 @@ func enrichSignals(store *db.Store, focusMonitor *focus.Monitor, payload *models
 			if _, exists := payload.Signals["focus_minutes"]; !exists {
 				payload.Signals["focus_minutes"] = fmt.Sprintf("%.1f", current.FocusMinutes)
 			}
-			focusState := deriveFocusState(current.FocusMinutes, switchCount, noProgress, noProgressDuration)
+			if categorizer, err := loadCategorizer(store); err == nil {
+				payload.Signals["focus_category"] = categorizer.Categorize(models.FocusEvent{
+					AppName:     current.AppName,
+					BundleID:    current.BundleID,
+					WindowTitle: current.WindowTitle,
+				})
+			}
+			focusState := deriveFocusState(focusStateInput{
+				FocusMinutes:       current.FocusMinutes,
+				SwitchCount:        switchCount,
+				NoProgress:         noProgress,
+				NoProgressDuration: noProgressDuration,
+				Idle:               idle,
+				DistractingMinutes: distractingMinutes,
+				Pattern:            pattern,
+			}, thresholds)
 			payload.FocusState = focusState
 			payload.Signals["focus_state"] = focusState
-			_ = store.InsertFocusStateSnapshot(models.FocusStateSnapshot{
-				TsMs:         time.Now().UnixMilli(),
+			snapshot = &models.FocusStateSnapshot{
+				TsMs:         nowMs,
 				FocusState:   focusState,
 				SwitchCount:  switchCount,
 				NoProgressMs: noProgressDuration.Milliseconds(),
 				FocusMinutes: current.FocusMinutes,
 				AppName:      current.AppName,
 				WindowTitle:  current.WindowTitle,
-			})
+			}
 		}
 	} else {
-		if metrics, err := store.FocusMetrics(int64((10 * time.Minute).Milliseconds())); err == nil {
+		if metrics, err := store.FocusMetrics(nowMs, (time.Duration(thresholds.SwitchWindowMinutes) * time.Minute).Milliseconds()); err == nil {
 			payload.SwitchCount = metrics.SwitchCount
 			payload.Signals["switch_count"] = strconv.Itoa(metrics.SwitchCount)
 			payload.Signals["focus_minutes_window"] = fmt.Sprintf("%.1f", metrics.FocusMinutes)
-			focusState := deriveFocusState(metrics.FocusMinutes, metrics.SwitchCount, false, 0)
+			focusState := deriveFocusState(focusStateInput{
+				FocusMinutes:       metrics.FocusMinutes,
+				SwitchCount:        metrics.SwitchCount,
+				DistractingMinutes: distractingMinutes,
+				Pattern:            pattern,
+			}, thresholds)
 			payload.FocusState = focusState
 			payload.Signals["focus_state"] = focusState
 		}
 	}
-	return nil
+	return snapshot, nil
 }
 
 func normalizeBudget(value string) string {
@@ -993,7 +1242,22 @@ func normalizeSettingValue(key, value string) (string, error) {
 			return trimmed, nil
 		}
 		return "", fmt.Errorf("invalid quiet_hours")
-	case settingAgentEnabled, settingRuleOnlyMode:
+	case settingQuietCalendar:
+		calendar, err := parseQuietCalendar(trimmed)
+		if err != nil {
+			return "", fmt.Errorf("invalid quiet_calendar: %v", err)
+		}
+		encoded, err := json.Marshal(calendar)
+		if err != nil {
+			return "", fmt.Errorf("invalid quiet_calendar: %v", err)
+		}
+		return string(encoded), nil
+	case settingCalendarPath:
+		if err := validateCalendarPath(trimmed); err != nil {
+			return "", fmt.Errorf("invalid calendar_ics_path: %v", err)
+		}
+		return trimmed, nil
+	case settingAgentEnabled, settingRuleOnlyMode, settingAdaptiveBudget:
 		switch strings.ToLower(trimmed) {
 		case "true", "false":
 			return strings.ToLower(trimmed), nil
@@ -1007,6 +1271,57 @@ func normalizeSettingValue(key, value string) (string, error) {
 		default:
 			return "", fmt.Errorf("invalid focus_monitor_enabled")
 		}
+	case settingTitleRedaction:
+		rules, err := focus.ParseRedactionRules(trimmed)
+		if err != nil {
+			return "", fmt.Errorf("invalid title_redaction_rules: %v", err)
+		}
+		encoded, err := json.Marshal(rules)
+		if err != nil {
+			return "", fmt.Errorf("invalid title_redaction_rules: %v", err)
+		}
+		return string(encoded), nil
+	case gateway.SettingGatewayRules:
+		rules, err := gateway.ParseRules([]byte(trimmed))
+		if err != nil {
+			return "", fmt.Errorf("invalid gateway_rules: %v", err)
+		}
+		encoded, err := json.Marshal(rules)
+		if err != nil {
+			return "", fmt.Errorf("invalid gateway_rules: %v", err)
+		}
+		return string(encoded), nil
+	case gateway.SettingGatewayLadder:
+		ladder, err := gateway.ParseLadder(trimmed)
+		if err != nil {
+			return "", fmt.Errorf("invalid gateway_ladder: %v", err)
+		}
+		encoded, err := json.Marshal(ladder)
+		if err != nil {
+			return "", fmt.Errorf("invalid gateway_ladder: %v", err)
+		}
+		return string(encoded), nil
+	case gateway.SettingBudgetSchedule:
+		profiles, err := gateway.ParseSchedule(trimmed)
+		if err != nil {
+			return "", fmt.Errorf("invalid budget_schedule: %v", err)
+		}
+		encoded, err := json.Marshal(profiles)
+		if err != nil {
+			return "", fmt.Errorf("invalid budget_schedule: %v", err)
+		}
+		return string(encoded), nil
+	case settingLadderThreshold:
+		parsed, err := strconv.Atoi(trimmed)
+		if err != nil || parsed < 1 {
+			return "", fmt.Errorf("invalid %s", key)
+		}
+		return strconv.Itoa(parsed), nil
+	case settingFocusProviderCmd:
+		if err := focus.ValidateProviderCommand(trimmed); err != nil {
+			return "", fmt.Errorf("invalid focus_provider_cmd: %v", err)
+		}
+		return trimmed, nil
 	case settingOllamaModel:
 		if trimmed == "" {
 			return "", fmt.Errorf("invalid ollama_model")
@@ -1018,10 +1333,22 @@ func normalizeSettingValue(key, value string) (string, error) {
 			return "", fmt.Errorf("invalid %s", key)
 		}
 		return trimmed, nil
-	case settingCooldownSeconds:
+	case settingCooldownSeconds, settingFocusIdleThreshold,
+		settingCooldownEncourage, settingCooldownTaskBreakdown, settingCooldownRestReminder, settingCooldownReframe,
+		settingDailyLimitEncourage, settingDailyLimitTaskBreakdown, settingDailyLimitRestReminder, settingDailyLimitReframe:
 		parsed, err := strconv.Atoi(trimmed)
 		if err != nil || parsed < 0 {
-			return "", fmt.Errorf("invalid cooldown_seconds")
+			return "", fmt.Errorf("invalid %s", key)
+		}
+		return strconv.Itoa(parsed), nil
+	case settingFocusSwitchWindow, settingFocusNoProgressHold, settingStateNoProgressMinutes,
+		settingStateSwitchCount, settingStateFocusedMinutes, settingStateDistractingMinutes,
+		settingPatternPingPongSwitches, settingPatternPeekSeconds, settingPatternPeekCount, settingPatternReturnMinutes,
+		settingMicroSwitchSeconds:
+		bounds := focusThresholdRanges[key]
+		parsed, err := strconv.Atoi(trimmed)
+		if err != nil || parsed < bounds.Min || parsed > bounds.Max {
+			return "", fmt.Errorf("invalid %s: must be between %d and %d", key, bounds.Min, bounds.Max)
 		}
 		return strconv.Itoa(parsed), nil
 	default:
@@ -1030,45 +1357,11 @@ func normalizeSettingValue(key, value string) (string, error) {
 }
 
 func isValidQuietHours(value string) bool {
-	parts := strings.Split(value, "-")
-	if len(parts) != 2 {
-		return false
-	}
-	for _, part := range parts {
-		if _, err := time.Parse("15:04", strings.TrimSpace(part)); err != nil {
-			return false
-		}
-	}
-	return true
-}
-
-func withinQuietHours(now time.Time, quietHours string) bool {
-	parts := strings.Split(quietHours, "-")
-	if len(parts) != 2 {
-		return false
-	}
-	start, err := time.Parse("15:04", strings.TrimSpace(parts[0]))
-	if err != nil {
-		return false
-	}
-	end, err := time.Parse("15:04", strings.TrimSpace(parts[1]))
-	if err != nil {
-		return false
-	}
-	nowMinutes := now.Hour()*60 + now.Minute()
-	startMinutes := start.Hour()*60 + start.Minute()
-	endMinutes := end.Hour()*60 + end.Minute()
-	if startMinutes == endMinutes {
-		return false
-	}
-	if startMinutes < endMinutes {
-		return nowMinutes >= startMinutes && nowMinutes < endMinutes
-	}
-	return nowMinutes >= startMinutes || nowMinutes < endMinutes
+	_, _, err := clock.ParseRange(value)
+	return err == nil
 }
 
-func (h *Handler) shouldAllowAutoSuggestion(ctx models.Context) (bool, string, error) {
-	now := time.Now()
+func (h *Handler) shouldAllowAutoSuggestion(ctx models.Context, now time.Time) (bool, string, error) {
 	lastRaw, ok, err := h.store.GetSetting(settingLastAutoSuggestMs)
 	if err != nil {
 		return false, "", err
@@ -1080,7 +1373,7 @@ func (h *Handler) shouldAllowAutoSuggestion(ctx models.Context) (bool, string, e
 			}
 		}
 	}
-	allowed, reason := h.gateway.CanIntervene(ctx, gateway.MaxActionCost())
+	allowed, reason := h.gateway.CanIntervene(ctx, h.gateway.MaxCost(ctx, autoSuggestionTypes...), autoSuggestionTypes...)
 	if !allowed {
 		return false, reason, nil
 	}
@@ -1112,14 +1405,35 @@ func isImplicitFeedback(feedback models.FeedbackType) bool {
 	}
 }
 
-func deriveFocusState(focusMinutes float64, switchCount int, noProgress bool, noProgressDuration time.Duration) string {
-	if noProgress && noProgressDuration >= 20*time.Minute {
+type focusStateInput struct {
+	FocusMinutes       float64
+	SwitchCount        int
+	NoProgress         bool
+	NoProgressDuration time.Duration
+	Idle               bool
+	DistractingMinutes float64
+	Pattern            string
+}
+
+func deriveFocusState(in focusStateInput, t focusThresholds) string {
+	if in.Idle {
+		return "IDLE"
+	}
+	if in.NoProgress && in.NoProgressDuration >= time.Duration(t.NoProgressMinutes)*time.Minute {
 		return "NO_PROGRESS"
 	}
-	if switchCount >= 8 {
+	switch in.Pattern {
+	case models.FocusPatternPingPong:
+		return "PING_PONG"
+	case models.FocusPatternPeek:
+		return "PEEKING"
+	case models.FocusPatternSlowReturn:
+		return "SLOW_RETURN"
+	}
+	if in.SwitchCount >= t.SwitchCount || in.DistractingMinutes >= float64(t.DistractingMinutes) {
 		return "DISTRACTED"
 	}
-	if focusMinutes >= 25 {
+	if in.FocusMinutes >= float64(t.FocusedMinutes) {
 		return "FOCUSED"
 	}
 	return "LIGHT"
@@ -1159,6 +1473,8 @@ func describeActionType(raw string) string {
 		return "任务拆解"
 	case "REFRAME":
 		return "换个角度"
+	case "AMBIENT":
+		return "氛围提示"
 	case "DO_NOT_DISTURB":
 		return "勿扰"
 	default:
@@ -0,0 +1,129 @@
+package httpapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"always/core/internal/ai"
+	"always/core/internal/clock"
+	"always/core/internal/db"
+	"always/core/internal/focus"
+	"always/core/internal/memory"
+	"always/core/internal/models"
+)
+
+var testStart = time.Date(2026, 10, 12, 10, 0, 0, 0, time.Local)
+
+type testEnv struct {
+	handler *Handler
+	router  http.Handler
+	store   *db.Store
+	monitor *focus.Monitor
+	clock   *clock.Virtual
+	// action is what the stub AI service returns for every decision.
+	action models.Action
+}
+
+// newTestEnv wires a handler to a fresh store, a replay monitor and a stub AI
+// service, all on a virtual clock starting at testStart.
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	store, err := db.Open(filepath.Join(t.TempDir(), "always.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { store.DB().Close() })
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	env := &testEnv{
+		store: store,
+		clock: clock.NewVirtual(testStart),
+		action: models.Action{
+			ActionType: models.ActionEncourage,
+			Message:    "keep going",
+			Confidence: 0.9,
+			RiskLevel:  models.RiskLow,
+		},
+	}
+	aiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"action":         env.action,
+			"policy_version": "test",
+			"model_version":  "test",
+		})
+	}))
+	t.Cleanup(aiServer.Close)
+	env.monitor = focus.NewReplayMonitor(store, logger, env.clock)
+	env.handler = NewHandler(store, ai.NewClient(aiServer.URL), env.monitor, memory.NewService(store.DB(), logger), testStart, logger)
+	env.handler.SetClock(env.clock)
+	env.router = env.handler.Router()
+	return env
+}
+
+// call sends a JSON request and decodes the JSON response into out, if given.
+func (e *testEnv) call(t *testing.T, method, path string, body any, out any) int {
+	t.Helper()
+	var reader io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = bytes.NewReader(raw)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	e.router.ServeHTTP(rec, req)
+	if out != nil && rec.Body.Len() > 0 {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			t.Fatalf("%s %s: decode %q: %v", method, path, rec.Body.String(), err)
+		}
+	}
+	return rec.Code
+}
+
+func (e *testEnv) setSetting(t *testing.T, key, value string) {
+	t.Helper()
+	var resp map[string]any
+	if status := e.call(t, http.MethodPost, "/v1/settings", models.SettingRequest{Key: key, Value: value}, &resp); status != http.StatusOK {
+		t.Fatalf("set %s=%s: %d %v", key, value, status, resp)
+	}
+}
+
+func (e *testEnv) observe(offset time.Duration, snapshot focus.FocusSnapshot) {
+	now := testStart.Add(offset)
+	e.clock.Set(now)
+	snapshot.TsMs = now.UnixMilli()
+	e.monitor.Observe(snapshot)
+}
+
+func TestHealth(t *testing.T) {
+	env := newTestEnv(t)
+	var resp map[string]any
+	if status := env.call(t, http.MethodGet, "/v1/health", nil, &resp); status != http.StatusOK {
+		t.Fatalf("health = %d %v", status, resp)
+	}
+}
+
+// decide asks for a decision at the current virtual time; the stub AI answers
+// with e.action.
+func (e *testEnv) decide(t *testing.T, mode models.Mode, signals map[string]string) models.DecisionResponse {
+	t.Helper()
+	req := models.DecisionRequest{Context: models.Context{
+		Timestamp: e.clock.Now().UnixMilli(),
+		Mode:      mode,
+		Signals:   signals,
+	}}
+	var resp models.DecisionResponse
+	if status := e.call(t, http.MethodPost, "/v1/decision", req, &resp); status != http.StatusOK {
+		t.Fatalf("decision status = %d", status)
+	}
+	return resp
+}
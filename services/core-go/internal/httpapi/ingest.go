@@ -0,0 +1,228 @@
+package httpapi
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"always/core/internal/focus"
+	"always/core/internal/models"
+)
+
+const (
+	maxIngestEvents    = 500
+	maxIngestSpan      = 24 * time.Hour
+	maxIngestClockSkew = time.Minute
+	maxIngestAge       = 30 * 24 * time.Hour
+)
+
+var ingestSourcePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,63}$`)
+
+type focusIngestResult struct {
+	Index        int    `json:"index"`
+	Status       string `json:"status"`
+	ID           int64  `json:"id,omitempty"`
+	LocalEventID int64  `json:"local_event_id,omitempty"`
+}
+
+type focusIngestError struct {
+	Index int    `json:"index"`
+	Error string `json:"error"`
+}
+
+// handleFocusEventsIngest accepts activity pushed by browser extensions,
+// editor plugins or other machines. The batch is validated as a whole before
+// anything is written, so clients can simply retry on error.
+func (h *Handler) handleFocusEventsIngest(w http.ResponseWriter, r *http.Request) {
+	var req models.FocusIngestRequest
+	if err := decodeJSON(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	if len(req.Events) == 0 {
+		respondError(w, http.StatusBadRequest, "events required")
+		return
+	}
+	if len(req.Events) > maxIngestEvents {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("at most %d events per request", maxIngestEvents))
+		return
+	}
+
+	nowMs := h.clock.Now().UnixMilli()
+	var invalid []focusIngestError
+	for i := range req.Events {
+		if err := normalizeIngestEvent(&req.Events[i], nowMs); err != nil {
+			invalid = append(invalid, focusIngestError{Index: i, Error: err.Error()})
+		}
+	}
+	if len(invalid) > 0 {
+		respondJSON(w, http.StatusBadRequest, map[string]any{
+			"error":  "invalid events",
+			"events": invalid,
+		})
+		return
+	}
+
+	redact, err := h.ingestRedactor()
+	if err != nil {
+		h.logger.Error("load redaction rules failed", slog.Any("error", err))
+		respondError(w, http.StatusInternalServerError, "settings error")
+		return
+	}
+
+	results := make([]focusIngestResult, 0, len(req.Events))
+	counts := map[string]int{"inserted": 0, "merged": 0, "duplicate": 0}
+	for i, in := range req.Events {
+		result, err := h.ingestFocusEvent(in, redact, nowMs)
+		if err != nil {
+			h.logger.Error("ingest focus event failed", slog.Any("error", err), slog.String("source", in.Source))
+			respondError(w, http.StatusInternalServerError, "db error")
+			return
+		}
+		result.Index = i
+		counts[result.Status]++
+		results = append(results, result)
+	}
+	respondJSON(w, http.StatusOK, map[string]any{
+		"inserted":   counts["inserted"],
+		"merged":     counts["merged"],
+		"duplicates": counts["duplicate"],
+		"results":    results,
+	})
+}
+
+// ingestFocusEvent skips exact retries and folds URL/domain into an
+// overlapping locally polled event for the same app, so the browser extension
+// and the monitor seeing the same Chrome window are not counted twice. Events
+// without an app name match whichever local app was in front. Anything that
+// cannot be folded is inserted; if it overlaps local activity it records the
+// local event so aggregates skip it.
+func (h *Handler) ingestFocusEvent(in models.FocusIngestEvent, redact func(appName, bundleID, title string) string, nowMs int64) (focusIngestResult, error) {
+	appName := in.AppName
+	if appName == "" {
+		appName = in.Domain
+	}
+	exists, err := h.store.FocusEventExists(in.Source, in.StartMs, appName, in.URL)
+	if err != nil {
+		return focusIngestResult{}, err
+	}
+	if exists {
+		return focusIngestResult{Status: "duplicate"}, nil
+	}
+
+	local, found, err := h.store.FindOverlappingLocalEvent(in.AppName, in.StartMs, in.EndMs, nowMs)
+	if err != nil {
+		return focusIngestResult{}, err
+	}
+	if found {
+		if in.URL == "" && in.Domain == "" {
+			return focusIngestResult{Status: "merged", ID: local.ID}, nil
+		}
+		linked, err := h.store.SetFocusEventLink(local.ID, in.URL, in.Domain)
+		if err != nil {
+			return focusIngestResult{}, err
+		}
+		if linked {
+			return focusIngestResult{Status: "merged", ID: local.ID}, nil
+		}
+	} else if in.AppName != "" {
+		local, found, err = h.store.FindOverlappingLocalEvent("", in.StartMs, in.EndMs, nowMs)
+		if err != nil {
+			return focusIngestResult{}, err
+		}
+	}
+	var localID int64
+	if found {
+		localID = local.ID
+	}
+
+	id, err := h.store.InsertFocusEvent(models.FocusEvent{
+		TsMs:         in.StartMs,
+		AppName:      appName,
+		BundleID:     in.BundleID,
+		WindowTitle:  redact(appName, in.BundleID, in.WindowTitle),
+		DurationMs:   in.EndMs - in.StartMs,
+		Source:       in.Source,
+		URL:          in.URL,
+		Domain:       in.Domain,
+		LocalEventID: localID,
+	})
+	if err != nil {
+		return focusIngestResult{}, err
+	}
+	return focusIngestResult{Status: "inserted", ID: id, LocalEventID: localID}, nil
+}
+
+// ingestRedactor applies the monitor's live redaction rules, falling back to
+// the saved setting when no monitor is running.
+func (h *Handler) ingestRedactor() (func(appName, bundleID, title string) string, error) {
+	if h.focus != nil {
+		return h.focus.RedactTitle, nil
+	}
+	var rules []models.TitleRedactionRule
+	value, ok, err := h.store.GetSetting(settingTitleRedaction)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		if rules, err = focus.ParseRedactionRules(value); err != nil {
+			return nil, err
+		}
+	}
+	redactor, err := focus.NewRedactor(rules)
+	if err != nil {
+		return nil, err
+	}
+	return redactor.Redact, nil
+}
+
+func normalizeIngestEvent(event *models.FocusIngestEvent, nowMs int64) error {
+	event.Source = strings.ToLower(strings.TrimSpace(event.Source))
+	event.AppName = strings.TrimSpace(event.AppName)
+	event.BundleID = strings.TrimSpace(event.BundleID)
+	event.URL = strings.TrimSpace(event.URL)
+	event.Domain = strings.ToLower(strings.TrimSpace(event.Domain))
+	event.WindowTitle = strings.TrimSpace(event.WindowTitle)
+
+	if !ingestSourcePattern.MatchString(event.Source) {
+		return fmt.Errorf("invalid source")
+	}
+	if event.Source == models.FocusSourceLocal {
+		return fmt.Errorf("source %q is reserved", models.FocusSourceLocal)
+	}
+	if event.URL != "" {
+		parsed, err := url.Parse(event.URL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+			return fmt.Errorf("invalid url")
+		}
+		if event.Domain == "" {
+			event.Domain = strings.ToLower(parsed.Hostname())
+		}
+	}
+	if event.Domain != "" && strings.ContainsAny(event.Domain, " /:") {
+		return fmt.Errorf("invalid domain")
+	}
+	if event.AppName == "" && event.Domain == "" {
+		return fmt.Errorf("app_name or url/domain required")
+	}
+	if len(event.AppName) > 256 || len(event.URL) > 2048 || len(event.WindowTitle) > 1024 {
+		return fmt.Errorf("field too long")
+	}
+	if event.StartMs <= 0 || event.EndMs <= event.StartMs {
+		return fmt.Errorf("end_ms must be after start_ms")
+	}
+	if event.EndMs-event.StartMs > maxIngestSpan.Milliseconds() {
+		return fmt.Errorf("event longer than %s", maxIngestSpan)
+	}
+	if event.EndMs > nowMs+maxIngestClockSkew.Milliseconds() {
+		return fmt.Errorf("end_ms is in the future")
+	}
+	if event.StartMs < nowMs-maxIngestAge.Milliseconds() {
+		return fmt.Errorf("start_ms is too old")
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package httpapi
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"always/core/internal/focus"
+	"always/core/internal/models"
+)
+
+type ingestResponse struct {
+	Inserted   int                 `json:"inserted"`
+	Merged     int                 `json:"merged"`
+	Duplicates int                 `json:"duplicates"`
+	Results    []focusIngestResult `json:"results"`
+}
+
+func ingestSpan(source, app, url string, from, to time.Duration) models.FocusIngestEvent {
+	return models.FocusIngestEvent{
+		Source:  source,
+		AppName: app,
+		URL:     url,
+		StartMs: testStart.Add(from).UnixMilli(),
+		EndMs:   testStart.Add(to).UnixMilli(),
+	}
+}
+
+func TestFocusEventsIngest(t *testing.T) {
+	env := newTestEnv(t)
+	env.observe(0, focus.FocusSnapshot{AppName: "Google Chrome", PID: 1})
+	env.observe(10*time.Minute, focus.FocusSnapshot{AppName: "Code", PID: 2})
+	env.clock.Set(testStart.Add(20 * time.Minute))
+	local := focusEventsByApp(t, env)
+
+	req := models.FocusIngestRequest{Events: []models.FocusIngestEvent{
+		// No app name: folds into whichever local app was in front.
+		ingestSpan("chrome-ext", "", "https://docs.example.com/a", time.Minute, 3*time.Minute),
+		// A second URL for the same window cannot be folded and is kept aside.
+		ingestSpan("chrome-ext", "Google Chrome", "https://news.example.com/b", 4*time.Minute, 6*time.Minute),
+		// Another machine while this one was active.
+		ingestSpan("laptop", "Figma", "", 12*time.Minute, 14*time.Minute),
+		// Before any local activity.
+		ingestSpan("laptop", "Figma", "", -30*time.Minute, -20*time.Minute),
+	}}
+	var resp ingestResponse
+	if status := env.call(t, http.MethodPost, "/v1/focus/events", req, &resp); status != http.StatusOK {
+		t.Fatalf("ingest status = %d", status)
+	}
+	want := []focusIngestResult{
+		{Index: 0, Status: "merged", ID: local["Google Chrome"].ID},
+		{Index: 1, Status: "inserted", LocalEventID: local["Google Chrome"].ID},
+		{Index: 2, Status: "inserted", LocalEventID: local["Code"].ID},
+		{Index: 3, Status: "inserted"},
+	}
+	if resp.Merged != 1 || resp.Inserted != 3 || len(resp.Results) != len(want) {
+		t.Fatalf("response = %+v", resp)
+	}
+	for i, result := range resp.Results {
+		if result.Status == "inserted" {
+			result.ID = 0
+		}
+		if result != want[i] {
+			t.Fatalf("result %d = %+v, want %+v", i, resp.Results[i], want[i])
+		}
+	}
+	if chrome := focusEventsByApp(t, env)["Google Chrome"]; chrome.URL != "https://docs.example.com/a" || chrome.Domain != "docs.example.com" {
+		t.Fatalf("local chrome event = %+v", chrome)
+	}
+
+	// Retries fold again into the same link and skip everything inserted.
+	var retry ingestResponse
+	if status := env.call(t, http.MethodPost, "/v1/focus/events", req, &retry); status != http.StatusOK || retry.Merged != 1 || retry.Duplicates != 3 {
+		t.Fatalf("retry = %d %+v", status, retry)
+	}
+
+	// Shadowed rows stay out of the aggregates: 10m remote + 10m Chrome + 10m Code.
+	metrics, err := env.store.FocusMetrics(env.clock.Now().UnixMilli(), time.Hour.Milliseconds())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metrics.SwitchCount != 2 || metrics.FocusMinutes != 30 {
+		t.Fatalf("metrics = %+v", metrics)
+	}
+	events, err := env.store.ListFocusEventsRange(testStart.Add(-time.Hour).UnixMilli(), env.clock.Now().UnixMilli())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("range events = %+v", events)
+	}
+}
+
+func TestFocusEventsIngestValidation(t *testing.T) {
+	env := newTestEnv(t)
+	tests := []struct {
+		name  string
+		event models.FocusIngestEvent
+	}{
+		{"no app or url", ingestSpan("laptop", "", "", -time.Minute, 0)},
+		{"reserved source", ingestSpan(models.FocusSourceLocal, "Code", "", -time.Minute, 0)},
+		{"bad url", ingestSpan("laptop", "", "ftp://example.com", -time.Minute, 0)},
+		{"reversed span", ingestSpan("laptop", "Code", "", 0, -time.Minute)},
+		{"future", ingestSpan("laptop", "Code", "", 0, time.Hour)},
+		{"too old", ingestSpan("laptop", "Code", "", -40*24*time.Hour, -40*24*time.Hour+time.Minute)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := models.FocusIngestRequest{Events: []models.FocusIngestEvent{tt.event}}
+			if status := env.call(t, http.MethodPost, "/v1/focus/events", req, nil); status != http.StatusBadRequest {
+				t.Fatalf("status = %d", status)
+			}
+		})
+	}
+}
+
+func focusEventsByApp(t *testing.T, env *testEnv) map[string]models.FocusEvent {
+	t.Helper()
+	events, err := env.store.ListFocusEvents(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byApp := map[string]models.FocusEvent{}
+	for _, event := range events {
+		if event.Source == models.FocusSourceLocal {
+			byApp[event.AppName] = event
+		}
+	}
+	return byApp
+}
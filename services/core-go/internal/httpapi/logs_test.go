@@ -0,0 +1,48 @@
+package httpapi
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"always/core/internal/gateway"
+	"always/core/internal/models"
+)
+
+func TestLogsReturnGatewayTrace(t *testing.T) {
+	env := newTestEnv(t)
+	first := env.decide(t, models.ModeActive, nil)
+	if len(first.GatewayDecision.Trace) == 0 {
+		t.Fatal("decision has no trace")
+	}
+	// Within the auto-suggestion window the core stays silent before asking
+	// the policy and records why as the first step.
+	env.clock.Advance(time.Minute)
+	second := env.decide(t, models.ModeActive, nil)
+	guard := second.GatewayDecision.Trace
+	if second.Action.ActionType != models.ActionDoNotDisturb || len(guard) == 0 ||
+		guard[0].Check != "auto_guard" || guard[0].Outcome != gateway.TraceBlock {
+		t.Fatalf("auto guard decision = %+v", second.GatewayDecision)
+	}
+
+	var logs []models.EventLog
+	if status := env.call(t, http.MethodGet, "/v1/logs?limit=10", nil, &logs); status != http.StatusOK {
+		t.Fatalf("logs = %d", status)
+	}
+	byID := map[string]models.EventLog{}
+	for _, log := range logs {
+		byID[log.RequestID] = log
+	}
+	for _, resp := range []models.DecisionResponse{first, second} {
+		log, ok := byID[resp.RequestID]
+		if !ok {
+			t.Fatalf("decision %s not logged", resp.RequestID)
+		}
+		if !slices.EqualFunc(log.GatewayDecision.Trace, resp.GatewayDecision.Trace, func(a, b models.GatewayTraceStep) bool {
+			return a.Check == b.Check && a.Outcome == b.Outcome && a.Reason == b.Reason && len(a.Inputs) == len(b.Inputs)
+		}) {
+			t.Fatalf("logged trace = %+v\nwant %+v", log.GatewayDecision.Trace, resp.GatewayDecision.Trace)
+		}
+	}
+}
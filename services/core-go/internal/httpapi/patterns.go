@@ -0,0 +1,50 @@
+package httpapi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"always/core/internal/db"
+	"always/core/internal/focus"
+	"always/core/internal/gateway"
+	"always/core/internal/models"
+)
+
+// detectFocusPatterns runs the switch pattern detectors over the monitor's
+// recent visits, or over stored events when the monitor is off.
+func detectFocusPatterns(store *db.Store, focusMonitor *focus.Monitor, nowMs int64, t focusThresholds) (models.FocusPatterns, error) {
+	var visits []focus.Visit
+	if focusMonitor != nil && focusMonitor.Enabled() {
+		visits = focusMonitor.RecentVisits()
+	} else {
+		windowMs := (time.Duration(t.SwitchWindowMinutes) * time.Minute).Milliseconds()
+		events, err := store.ListFocusEventsRange(nowMs-windowMs, nowMs+1)
+		if err != nil {
+			return models.FocusPatterns{}, err
+		}
+		visits = focus.VisitsFromEvents(events, nowMs)
+	}
+	categorizer, err := loadCategorizer(store)
+	if err != nil {
+		return models.FocusPatterns{}, err
+	}
+	return focus.DetectPatterns(visits, categorizer, nowMs, t.patterns()), nil
+}
+
+func setPatternSignals(signals map[string]string, patterns models.FocusPatterns) {
+	if patterns.Pattern != "" {
+		signals[gateway.SignalFocusPattern] = patterns.Pattern
+	}
+	signals["ping_pong_switches"] = strconv.Itoa(patterns.PingPongSwitches)
+	if len(patterns.PingPongApps) > 0 {
+		signals["ping_pong_apps"] = strings.Join(patterns.PingPongApps, ",")
+	}
+	signals["peek_count"] = strconv.Itoa(patterns.PeekCount)
+	if len(patterns.PeekApps) > 0 {
+		signals["peek_apps"] = strings.Join(patterns.PeekApps, ",")
+	}
+	signals["return_latency_seconds"] = strconv.FormatInt(patterns.ReturnLatencyMs/1000, 10)
+	signals["away_from_task_minutes"] = fmt.Sprintf("%.1f", float64(patterns.AwayMs)/60000)
+}
@@ -0,0 +1,45 @@
+package httpapi
+
+import (
+	"testing"
+	"time"
+
+	"always/core/internal/focus"
+	"always/core/internal/gateway"
+	"always/core/internal/models"
+)
+
+func TestFocusPatternSignals(t *testing.T) {
+	env := newTestEnv(t)
+	for i := range 8 {
+		snapshot := focus.FocusSnapshot{AppName: "Code", PID: 1}
+		if i%2 == 1 {
+			snapshot = focus.FocusSnapshot{AppName: "Slack", PID: 2}
+		}
+		env.observe(time.Duration(i)*40*time.Second, snapshot)
+	}
+	env.clock.Advance(10 * time.Second)
+	env.action = models.Action{ActionType: models.ActionReframe, Message: "back to it", Confidence: 0.9, RiskLevel: models.RiskLow}
+
+	resp := env.decide(t, models.ModeActive, nil)
+	signals := resp.Context.Signals
+	if resp.Context.FocusState != "PING_PONG" || signals[gateway.SignalFocusPattern] != models.FocusPatternPingPong {
+		t.Fatalf("state = %s, signals = %v", resp.Context.FocusState, signals)
+	}
+	if signals["ping_pong_switches"] != "7" || signals["ping_pong_apps"] != "Code,Slack" {
+		t.Fatalf("signals = %v", signals)
+	}
+
+	// A REFRAME aimed at the pattern is priced down by focus_pattern_reframe.
+	pricing := resp.GatewayDecision.Pricing
+	if resp.Action.ActionType != models.ActionReframe || pricing == nil || len(pricing.Multipliers) != 1 || pricing.Multipliers[0].Rule != "focus_pattern_reframe" {
+		t.Fatalf("action = %s, pricing = %+v", resp.Action.ActionType, pricing)
+	}
+
+	// Settling on Slack ends the ping-pong but leaves the user away from Code.
+	env.clock.Advance(3 * time.Minute)
+	resp = env.decide(t, models.ModeActive, nil)
+	if resp.Context.FocusState != "SLOW_RETURN" || resp.Context.Signals["ping_pong_switches"] != "0" {
+		t.Fatalf("after settling: state = %s, signals = %v", resp.Context.FocusState, resp.Context.Signals)
+	}
+}
@@ -0,0 +1,255 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"always/core/internal/clock"
+	"always/core/internal/db"
+)
+
+const (
+	settingQuietCalendar = "quiet_calendar"
+
+	signalQuietNow     = "quiet_now"
+	signalQuietUntilMs = "quiet_until_ms"
+)
+
+// quietCalendar describes when the core stays silent. The legacy quiet_hours
+// window applies on top of it every day.
+type quietCalendar struct {
+	Windows []quietWindow `json:"windows,omitempty"`
+	Dates   []quietDates  `json:"dates,omitempty"`
+	// Override forces quiet on or off until a moment, ahead of everything else.
+	Override *quietOverride `json:"override,omitempty"`
+}
+
+// quietWindow is a daily HH:MM-HH:MM window; Weekdays name the day a window
+// starts on, so a Friday 22:00-07:00 window still covers Saturday morning.
+type quietWindow struct {
+	Time     string   `json:"time"`
+	Weekdays []string `json:"weekdays,omitempty"`
+	Label    string   `json:"label,omitempty"`
+}
+
+// quietDates covers whole days from From to To inclusive, e.g. a holiday or a
+// vacation. To defaults to From.
+type quietDates struct {
+	From  string `json:"from"`
+	To    string `json:"to,omitempty"`
+	Label string `json:"label,omitempty"`
+}
+
+type quietOverride struct {
+	Quiet bool   `json:"quiet"`
+	Until string `json:"until"`
+	Label string `json:"label,omitempty"`
+}
+
+type quietStatus struct {
+	Quiet  bool   `json:"quiet"`
+	Source string `json:"source,omitempty"`
+	Label  string `json:"label,omitempty"`
+	// UntilMs is when the current status ends: the end of the quiet period,
+	// or of an override that keeps the core active.
+	UntilMs int64  `json:"until_ms,omitempty"`
+	Until   string `json:"until,omitempty"`
+}
+
+type quietResponse struct {
+	quietStatus
+	Calendar   quietCalendar `json:"calendar"`
+	QuietHours string        `json:"quiet_hours,omitempty"`
+}
+
+// quietSpan is one calendar entry in effect at some moment.
+type quietSpan struct {
+	Source string
+	Label  string
+	Quiet  bool
+	End    time.Time
+}
+
+func parseQuietCalendar(raw string) (quietCalendar, error) {
+	var calendar quietCalendar
+	decoder := json.NewDecoder(strings.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&calendar); err != nil {
+		return calendar, fmt.Errorf("decode quiet calendar: %w", err)
+	}
+	for i, window := range calendar.Windows {
+		if _, _, err := clock.ParseRange(window.Time); err != nil {
+			return calendar, fmt.Errorf("window %d: %w", i, err)
+		}
+		for _, day := range window.Weekdays {
+			if _, ok := clock.ParseWeekday(day); !ok {
+				return calendar, fmt.Errorf("window %d: invalid weekday %q", i, day)
+			}
+		}
+	}
+	for i, dates := range calendar.Dates {
+		from, err := time.Parse("2006-01-02", dates.From)
+		if err != nil {
+			return calendar, fmt.Errorf("dates %d: invalid from %q: want YYYY-MM-DD", i, dates.From)
+		}
+		if dates.To != "" {
+			to, err := time.Parse("2006-01-02", dates.To)
+			if err != nil {
+				return calendar, fmt.Errorf("dates %d: invalid to %q: want YYYY-MM-DD", i, dates.To)
+			}
+			if to.Before(from) {
+				return calendar, fmt.Errorf("dates %d: to is before from", i)
+			}
+		}
+	}
+	if calendar.Override != nil {
+		if _, err := time.Parse(time.RFC3339, calendar.Override.Until); err != nil {
+			return calendar, fmt.Errorf("override: invalid until %q: want RFC 3339", calendar.Override.Until)
+		}
+	}
+	return calendar, nil
+}
+
+// loadQuietCalendar reads the calendar and the legacy quiet_hours window. The
+// calendar is validated when saved, so one that no longer parses is ignored
+// rather than failing every decision.
+func loadQuietCalendar(store *db.Store) (quietCalendar, string, error) {
+	quietHours, _, err := store.GetSetting(settingQuietHours)
+	if err != nil {
+		return quietCalendar{}, "", err
+	}
+	raw, ok, err := store.GetSetting(settingQuietCalendar)
+	if err != nil {
+		return quietCalendar{}, "", err
+	}
+	if !ok || strings.TrimSpace(raw) == "" {
+		return quietCalendar{}, quietHours, nil
+	}
+	calendar, err := parseQuietCalendar(raw)
+	if err != nil {
+		return quietCalendar{}, quietHours, nil
+	}
+	return calendar, quietHours, nil
+}
+
+// spansAt lists what keeps t quiet. An active override is returned alone.
+func (c quietCalendar) spansAt(t time.Time, quietHours string) []quietSpan {
+	if o := c.Override; o != nil {
+		if until, err := time.Parse(time.RFC3339, o.Until); err == nil && until.After(t) {
+			return []quietSpan{{Source: "override", Label: o.Label, Quiet: o.Quiet, End: until}}
+		}
+	}
+	var spans []quietSpan
+	if quietHours != "" {
+		if end, ok := windowEnd(t, quietHours, nil); ok {
+			spans = append(spans, quietSpan{Source: "quiet_hours", Quiet: true, End: end})
+		}
+	}
+	for _, window := range c.Windows {
+		if end, ok := windowEnd(t, window.Time, window.Weekdays); ok {
+			spans = append(spans, quietSpan{Source: "window", Label: window.Label, Quiet: true, End: end})
+		}
+	}
+	day := t.Format("2006-01-02")
+	for _, dates := range c.Dates {
+		to := dates.To
+		if to == "" {
+			to = dates.From
+		}
+		if day < dates.From || day > to {
+			continue
+		}
+		last, err := time.ParseInLocation("2006-01-02", to, t.Location())
+		if err != nil {
+			continue
+		}
+		spans = append(spans, quietSpan{Source: "dates", Label: dates.Label, Quiet: true, End: last.AddDate(0, 0, 1)})
+	}
+	return spans
+}
+
+// status reports whether now is quiet and until when, following overlapping
+// and back-to-back entries to the end of the whole quiet period.
+func (c quietCalendar) status(now time.Time, quietHours string) quietStatus {
+	spans := c.spansAt(now, quietHours)
+	if len(spans) == 0 {
+		return quietStatus{}
+	}
+	status := quietStatus{Quiet: spans[0].Quiet, Source: spans[0].Source, Label: spans[0].Label}
+	end := now
+	for range 64 {
+		next := end
+		for _, span := range c.spansAt(end, quietHours) {
+			if span.Quiet == status.Quiet && span.End.After(next) {
+				next = span.End
+			}
+		}
+		if !next.After(end) {
+			break
+		}
+		end = next
+		if !status.Quiet {
+			break
+		}
+	}
+	status.UntilMs = end.UnixMilli()
+	status.Until = end.Format(time.RFC3339)
+	return status
+}
+
+// windowEnd returns the end of the occurrence of an HH:MM-HH:MM window that
+// contains t.
+func windowEnd(t time.Time, value string, weekdays []string) (time.Time, bool) {
+	start, end, err := clock.ParseRange(value)
+	if err != nil || start == end {
+		return time.Time{}, false
+	}
+	if !clock.InRange(t, start, end) {
+		return time.Time{}, false
+	}
+	if len(weekdays) > 0 {
+		startDay := clock.StartDay(t, start, end)
+		matched := false
+		for _, day := range weekdays {
+			if weekday, ok := clock.ParseWeekday(day); ok && weekday == startDay {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return time.Time{}, false
+		}
+	}
+	closing := time.Date(t.Year(), t.Month(), t.Day(), end/60, end%60, 0, 0, t.Location())
+	if !closing.After(t) {
+		closing = closing.AddDate(0, 0, 1)
+	}
+	return closing, true
+}
+
+func (h *Handler) handleQuietGet(w http.ResponseWriter, _ *http.Request) {
+	calendar, quietHours, err := loadQuietCalendar(h.store)
+	if err != nil {
+		h.logger.Error("settings read failed", slog.Any("error", err))
+		respondError(w, http.StatusInternalServerError, "settings error")
+		return
+	}
+	respondJSON(w, http.StatusOK, quietResponse{
+		quietStatus: calendar.status(h.clock.Now(), quietHours),
+		Calendar:    calendar,
+		QuietHours:  quietHours,
+	})
+}
+
+func quietMessage(signals map[string]string) string {
+	untilMs, err := strconv.ParseInt(signals[signalQuietUntilMs], 10, 64)
+	if err != nil || untilMs <= 0 {
+		return "安静时段内，已暂停提示。"
+	}
+	return fmt.Sprintf("安静时段内（至 %s），已暂停提示。", time.UnixMilli(untilMs).Format("01-02 15:04"))
+}
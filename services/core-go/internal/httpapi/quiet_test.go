@@ -0,0 +1,170 @@
+package httpapi
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"always/core/internal/models"
+)
+
+func TestParseQuietCalendar(t *testing.T) {
+	cases := []struct {
+		name string
+		raw  string
+		ok   bool
+	}{
+		{"empty", `{}`, true},
+		{"window", `{"windows":[{"time":"22:00-07:00","weekdays":["fri","Saturday"]}]}`, true},
+		{"dates", `{"dates":[{"from":"2026-10-01","to":"2026-10-07"}]}`, true},
+		{"override", `{"override":{"quiet":true,"until":"2026-10-12T12:00:00+08:00"}}`, true},
+		{"bad window", `{"windows":[{"time":"22-07"}]}`, false},
+		{"bad weekday", `{"windows":[{"time":"22:00-07:00","weekdays":["weekend"]}]}`, false},
+		{"bad from", `{"dates":[{"from":"10/01/2026"}]}`, false},
+		{"to before from", `{"dates":[{"from":"2026-10-07","to":"2026-10-01"}]}`, false},
+		{"bad until", `{"override":{"quiet":true,"until":"tomorrow"}}`, false},
+		{"unknown field", `{"holidays":[]}`, false},
+	}
+	for _, tc := range cases {
+		if _, err := parseQuietCalendar(tc.raw); (err == nil) != tc.ok {
+			t.Errorf("%s: parseQuietCalendar error = %v, want ok %v", tc.name, err, tc.ok)
+		}
+	}
+}
+
+func TestQuietCalendarStatus(t *testing.T) {
+	// testStart is Monday 2026-10-12 10:00.
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 10, day, hour, minute, 0, 0, time.Local)
+	}
+	rfc := func(t time.Time) string { return t.Format(time.RFC3339) }
+	cases := []struct {
+		name       string
+		calendar   quietCalendar
+		quietHours string
+		now        time.Time
+		quiet      bool
+		source     string
+		until      time.Time
+	}{
+		{
+			name: "nothing configured",
+			now:  at(12, 10, 0),
+		},
+		{
+			name:       "quiet hours across midnight",
+			quietHours: "22:00-07:00",
+			now:        at(12, 23, 0),
+			quiet:      true,
+			source:     "quiet_hours",
+			until:      at(13, 7, 0),
+		},
+		{
+			name:     "window belongs to the day it starts",
+			calendar: quietCalendar{Windows: []quietWindow{{Time: "22:00-08:00", Weekdays: []string{"fri"}}}},
+			now:      at(17, 7, 0),
+			quiet:    true,
+			source:   "window",
+			until:    at(17, 8, 0),
+		},
+		{
+			name:     "window skips other days",
+			calendar: quietCalendar{Windows: []quietWindow{{Time: "22:00-08:00", Weekdays: []string{"fri"}}}},
+			now:      at(18, 7, 0),
+		},
+		{
+			name:       "back-to-back windows merge",
+			calendar:   quietCalendar{Windows: []quietWindow{{Time: "07:00-09:00", Label: "morning"}}},
+			quietHours: "22:00-07:00",
+			now:        at(12, 23, 0),
+			quiet:      true,
+			source:     "quiet_hours",
+			until:      at(13, 9, 0),
+		},
+		{
+			name:       "dates run into quiet hours",
+			calendar:   quietCalendar{Dates: []quietDates{{From: "2026-10-12", To: "2026-10-13", Label: "trip"}}},
+			quietHours: "22:00-07:00",
+			now:        at(12, 10, 0),
+			quiet:      true,
+			source:     "dates",
+			until:      at(14, 7, 0),
+		},
+		{
+			name: "override keeps the core active",
+			calendar: quietCalendar{
+				Dates:    []quietDates{{From: "2026-10-12"}},
+				Override: &quietOverride{Quiet: false, Until: rfc(at(12, 12, 0))},
+			},
+			now:    at(12, 10, 0),
+			source: "override",
+			until:  at(12, 12, 0),
+		},
+		{
+			name: "expired override is ignored",
+			calendar: quietCalendar{
+				Dates:    []quietDates{{From: "2026-10-12"}},
+				Override: &quietOverride{Quiet: false, Until: rfc(at(12, 9, 0))},
+			},
+			now:    at(12, 10, 0),
+			quiet:  true,
+			source: "dates",
+			until:  at(13, 0, 0),
+		},
+	}
+	for _, tc := range cases {
+		status := tc.calendar.status(tc.now, tc.quietHours)
+		if status.Quiet != tc.quiet || status.Source != tc.source {
+			t.Errorf("%s: status = %v from %q, want %v from %q", tc.name, status.Quiet, status.Source, tc.quiet, tc.source)
+			continue
+		}
+		var wantUntil int64
+		if !tc.until.IsZero() {
+			wantUntil = tc.until.UnixMilli()
+		}
+		if status.UntilMs != wantUntil {
+			t.Errorf("%s: until = %s, want %s", tc.name, time.UnixMilli(status.UntilMs), tc.until)
+		}
+	}
+}
+
+func TestQuietEndpointAndDecision(t *testing.T) {
+	env := newTestEnv(t)
+	var invalid map[string]any
+	if status := env.call(t, http.MethodPost, "/v1/settings", models.SettingRequest{
+		Key:   settingQuietCalendar,
+		Value: `{"windows":[{"time":"late"}]}`,
+	}, &invalid); status != http.StatusBadRequest {
+		t.Fatalf("invalid calendar status = %d, want 400", status)
+	}
+
+	env.setSetting(t, settingQuietCalendar, `{"dates":[{"from":"2026-10-12","label":"day off"}]}`)
+	var quiet quietResponse
+	if status := env.call(t, http.MethodGet, "/v1/quiet", nil, &quiet); status != http.StatusOK {
+		t.Fatalf("quiet status = %d", status)
+	}
+	until := time.Date(2026, 10, 13, 0, 0, 0, 0, time.Local)
+	if !quiet.Quiet || quiet.Source != "dates" || quiet.Label != "day off" || quiet.UntilMs != until.UnixMilli() {
+		t.Fatalf("quiet = %+v, want quiet from dates until %s", quiet.quietStatus, until)
+	}
+	if len(quiet.Calendar.Dates) != 1 {
+		t.Fatalf("calendar = %+v, want the saved dates", quiet.Calendar)
+	}
+
+	resp := env.decide(t, models.ModeActive, nil)
+	if resp.Action.ActionType != models.ActionDoNotDisturb || resp.PolicyVersion != "quiet_hours" {
+		t.Fatalf("decision = %s from %q, want DO_NOT_DISTURB from quiet_hours", resp.Action.ActionType, resp.PolicyVersion)
+	}
+	if resp.Context.Signals[signalQuietNow] != "true" || resp.Context.Signals[signalQuietUntilMs] == "" {
+		t.Fatalf("signals = %v, want quiet_now and quiet_until_ms", resp.Context.Signals)
+	}
+
+	env.clock.Set(until.Add(10 * time.Hour))
+	resp = env.decide(t, models.ModeActive, nil)
+	if resp.Action.ActionType == models.ActionDoNotDisturb && resp.PolicyVersion == "quiet_hours" {
+		t.Fatalf("decision after the quiet day is still silenced")
+	}
+	if resp.Context.Signals[signalQuietNow] != "false" {
+		t.Fatalf("quiet_now = %q, want false", resp.Context.Signals[signalQuietNow])
+	}
+}
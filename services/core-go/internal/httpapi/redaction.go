@@ -0,0 +1,72 @@
+package httpapi
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"always/core/internal/focus"
+	"always/core/internal/models"
+)
+
+type redactionPreviewRequest struct {
+	// Rules to try; the saved title_redaction_rules are used when omitted.
+	Rules []models.TitleRedactionRule `json:"rules,omitempty"`
+	// Titles to test; recent titles seen by the focus monitor when omitted.
+	Titles []models.FocusEvent `json:"titles,omitempty"`
+}
+
+type redactionPreviewItem struct {
+	AppName  string `json:"app_name"`
+	BundleID string `json:"bundle_id,omitempty"`
+	Original string `json:"original"`
+	Redacted string `json:"redacted"`
+}
+
+func (h *Handler) handleRedactionPreview(w http.ResponseWriter, r *http.Request) {
+	var req redactionPreviewRequest
+	if err := decodeJSON(r, &req); err != nil && !errors.Is(err, io.EOF) {
+		respondError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	rules := req.Rules
+	if rules == nil {
+		value, ok, err := h.store.GetSetting(settingTitleRedaction)
+		if err != nil {
+			h.logger.Error("load redaction rules failed", slog.Any("error", err))
+			respondError(w, http.StatusInternalServerError, "settings error")
+			return
+		}
+		if ok {
+			if rules, err = focus.ParseRedactionRules(value); err != nil {
+				respondError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+		}
+	}
+	redactor, err := focus.NewRedactor(rules)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	titles := req.Titles
+	if titles == nil && h.focus != nil {
+		titles = h.focus.RecentRawTitles()
+	}
+	items := make([]redactionPreviewItem, 0, len(titles))
+	for _, title := range titles {
+		items = append(items, redactionPreviewItem{
+			AppName:  title.AppName,
+			BundleID: title.BundleID,
+			Original: title.WindowTitle,
+			Redacted: redactor.Redact(title.AppName, title.BundleID, title.WindowTitle),
+		})
+	}
+	respondJSON(w, http.StatusOK, map[string]any{
+		"rules": rules,
+		"items": items,
+	})
+}
@@ -0,0 +1,48 @@
+package httpapi
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"always/core/internal/focus"
+	"always/core/internal/models"
+)
+
+func TestRedactionPreview(t *testing.T) {
+	env := newTestEnv(t)
+	env.setSetting(t, settingTitleRedaction, `[{"app":"Slack","action":"hash"}]`)
+	env.observe(0, focus.FocusSnapshot{AppName: "Slack", PID: 1, WindowTitle: "Payroll"})
+	env.observe(time.Minute, focus.FocusSnapshot{AppName: "Code", PID: 2, WindowTitle: "main.go"})
+
+	var resp struct {
+		Items []redactionPreviewItem `json:"items"`
+	}
+	if status := env.call(t, http.MethodPost, "/v1/focus/redaction/preview", nil, &resp); status != http.StatusOK {
+		t.Fatalf("preview status = %d", status)
+	}
+	if len(resp.Items) != 2 || resp.Items[1].Original != "Payroll" || resp.Items[1].Redacted == "Payroll" || resp.Items[0].Redacted != "main.go" {
+		t.Fatalf("items = %+v", resp.Items)
+	}
+
+	// Unsaved rules and explicit titles are previewed without touching settings.
+	req := redactionPreviewRequest{
+		Rules:  []models.TitleRedactionRule{{Action: focus.RedactDrop}},
+		Titles: []models.FocusEvent{{AppName: "Code", WindowTitle: "main.go"}},
+	}
+	if status := env.call(t, http.MethodPost, "/v1/focus/redaction/preview", req, &resp); status != http.StatusOK {
+		t.Fatalf("preview status = %d", status)
+	}
+	if len(resp.Items) != 1 || resp.Items[0].Redacted != "" {
+		t.Fatalf("items = %+v", resp.Items)
+	}
+
+	bad := redactionPreviewRequest{Rules: []models.TitleRedactionRule{{Action: "shred"}}}
+	if status := env.call(t, http.MethodPost, "/v1/focus/redaction/preview", bad, nil); status != http.StatusBadRequest {
+		t.Fatalf("invalid rules status = %d", status)
+	}
+	var errResp map[string]any
+	if status := env.call(t, http.MethodPost, "/v1/settings", models.SettingRequest{Key: settingTitleRedaction, Value: `[{"action":"shred"}]`}, &errResp); status != http.StatusBadRequest {
+		t.Fatalf("saving invalid rules = %d %v", status, errResp)
+	}
+}
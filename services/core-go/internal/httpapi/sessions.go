@@ -0,0 +1,363 @@
+package httpapi
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"always/core/internal/focus"
+	"always/core/internal/models"
+)
+
+const (
+	defaultSessionMinutes = 25
+	maxSessionMinutes     = 8 * 60
+	sessionTopApps        = 5
+)
+
+type sessionEndRequest struct {
+	// Mode the end-of-session reminder is evaluated under; LIGHT by default.
+	Mode models.Mode `json:"mode,omitempty"`
+}
+
+type sessionResponse struct {
+	Session  models.FocusSession       `json:"session"`
+	Stats    *models.FocusSessionStats `json:"stats,omitempty"`
+	Decision *models.DecisionResponse  `json:"decision,omitempty"`
+}
+
+func (h *Handler) handleSessionStart(w http.ResponseWriter, r *http.Request) {
+	var req models.FocusSessionStartRequest
+	if err := decodeJSON(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	req.Task = strings.TrimSpace(req.Task)
+	if req.Name == "" {
+		req.Name = "focus"
+	}
+	if req.TargetMinutes == 0 {
+		req.TargetMinutes = defaultSessionMinutes
+	}
+	if req.TargetMinutes < 1 || req.TargetMinutes > maxSessionMinutes {
+		respondError(w, http.StatusBadRequest, "invalid target_minutes")
+		return
+	}
+
+	session := models.FocusSession{
+		Name:          req.Name,
+		Task:          req.Task,
+		TargetMinutes: req.TargetMinutes,
+		Status:        models.SessionActive,
+		StartedAtMs:   h.clock.Now().UnixMilli(),
+	}
+	id, inserted, err := h.store.InsertFocusSession(session)
+	if err != nil {
+		h.logger.Error("insert focus session failed", slog.Any("error", err))
+		respondError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	if !inserted {
+		current, _, err := h.store.CurrentFocusSession()
+		if err != nil {
+			h.logger.Error("current focus session failed", slog.Any("error", err))
+			respondError(w, http.StatusInternalServerError, "db error")
+			return
+		}
+		respondJSON(w, http.StatusConflict, map[string]any{
+			"error":   "session already running",
+			"session": current,
+		})
+		return
+	}
+	session.ID = id
+	h.logger.Info("focus session started", slog.Int64("session_id", id), slog.String("name", session.Name))
+	respondJSON(w, http.StatusOK, sessionResponse{Session: session})
+}
+
+func (h *Handler) handleSessionList(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if l := r.URL.Query().Get("limit"); l != "" {
+		if parsed, err := parseInt(l); err == nil {
+			limit = parsed
+		}
+	}
+	sessions, err := h.store.ListFocusSessions(limit)
+	if err != nil {
+		h.logger.Error("list focus sessions failed", slog.Any("error", err))
+		respondError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	if sessions == nil {
+		sessions = []models.FocusSession{}
+	}
+	respondJSON(w, http.StatusOK, sessions)
+}
+
+func (h *Handler) handleSessionCurrent(w http.ResponseWriter, _ *http.Request) {
+	session, found, err := h.store.CurrentFocusSession()
+	if err != nil {
+		h.logger.Error("current focus session failed", slog.Any("error", err))
+		respondError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	if !found {
+		respondJSON(w, http.StatusOK, map[string]any{"session": nil})
+		return
+	}
+	h.respondSession(w, session)
+}
+
+func (h *Handler) handleSessionGet(w http.ResponseWriter, r *http.Request) {
+	session, ok := h.loadSession(w, r)
+	if !ok {
+		return
+	}
+	h.respondSession(w, session)
+}
+
+func (h *Handler) handleSessionPause(w http.ResponseWriter, r *http.Request) {
+	session, ok := h.loadSession(w, r)
+	if !ok {
+		return
+	}
+	if session.Status != models.SessionActive {
+		respondError(w, http.StatusConflict, "session is not active")
+		return
+	}
+	session.Status = models.SessionPaused
+	session.PausedAtMs = h.clock.Now().UnixMilli()
+	if !h.saveSession(w, session) {
+		return
+	}
+	h.respondSession(w, session)
+}
+
+func (h *Handler) handleSessionResume(w http.ResponseWriter, r *http.Request) {
+	session, ok := h.loadSession(w, r)
+	if !ok {
+		return
+	}
+	if session.Status != models.SessionPaused {
+		respondError(w, http.StatusConflict, "session is not paused")
+		return
+	}
+	session.PausedMs += h.clock.Now().UnixMilli() - session.PausedAtMs
+	session.PausedAtMs = 0
+	session.Status = models.SessionActive
+	if !h.saveSession(w, session) {
+		return
+	}
+	h.respondSession(w, session)
+}
+
+// handleSessionEnd closes the session and turns its stats into a
+// REST_REMINDER that goes through the gateway like any other decision.
+func (h *Handler) handleSessionEnd(w http.ResponseWriter, r *http.Request) {
+	session, ok := h.loadSession(w, r)
+	if !ok {
+		return
+	}
+	var req sessionEndRequest
+	if err := decodeJSON(r, &req); err != nil && !errors.Is(err, io.EOF) {
+		respondError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	if req.Mode == "" {
+		req.Mode = models.ModeLight
+	}
+	if err := validateContext(models.Context{Mode: req.Mode, Timestamp: h.clock.Now().UnixMilli()}); err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if session.Status == models.SessionEnded {
+		respondError(w, http.StatusConflict, "session already ended")
+		return
+	}
+
+	nowMs := h.clock.Now().UnixMilli()
+	if session.Status == models.SessionPaused {
+		session.PausedMs += nowMs - session.PausedAtMs
+		session.PausedAtMs = 0
+	}
+	session.Status = models.SessionEnded
+	session.EndedAtMs = nowMs
+	stats, err := h.sessionStats(session, nowMs)
+	if err != nil {
+		h.logger.Error("focus session stats failed", slog.Any("error", err))
+		respondError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	ended, err := h.store.EndFocusSession(session)
+	if err != nil {
+		h.logger.Error("end focus session failed", slog.Any("error", err))
+		respondError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	if !ended {
+		// Another request ended it first and recorded the break reminder.
+		respondError(w, http.StatusConflict, "session already ended")
+		return
+	}
+
+	ctx := models.Context{
+		Timestamp: nowMs,
+		Mode:      req.Mode,
+		Signals: map[string]string{
+			"focus_session_id": strconv.FormatInt(session.ID, 10),
+			"session_minutes":  fmt.Sprintf("%.1f", float64(stats.ActiveMs)/60000),
+		},
+	}
+	if err := enrichSignals(h.store, h.focus, h.calendar, &ctx, time.UnixMilli(nowMs)); err != nil {
+		h.logger.Warn("failed to enrich signals for session end", slog.Any("error", err))
+	}
+	// Ending a session is an explicit user action, like typing a message, so
+	// neither the shared nor the REST_REMINDER cooldown holds the reminder back.
+	h.gateway.ClearCooldown(models.ActionRestReminder)
+	requestID := uuid.NewString()
+	decision, err := h.recordDecision(requestID, ctx, sessionEndAction(session, stats), "focus_session", "n/a", 0)
+	if err != nil {
+		h.logger.Error("insert decision failed", slog.String("request_id", requestID), slog.Any("error", err))
+		respondError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	session.DecisionRequestID = requestID
+	if !h.saveSession(w, session) {
+		return
+	}
+	h.logger.Info("focus session ended",
+		slog.Int64("session_id", session.ID),
+		slog.Int64("active_ms", stats.ActiveMs),
+		slog.String("request_id", requestID),
+	)
+	respondJSON(w, http.StatusOK, sessionResponse{Session: session, Stats: &stats, Decision: &decision})
+}
+
+func (h *Handler) loadSession(w http.ResponseWriter, r *http.Request) (models.FocusSession, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid id")
+		return models.FocusSession{}, false
+	}
+	session, found, err := h.store.GetFocusSession(id)
+	if err != nil {
+		h.logger.Error("get focus session failed", slog.Any("error", err))
+		respondError(w, http.StatusInternalServerError, "db error")
+		return models.FocusSession{}, false
+	}
+	if !found {
+		respondError(w, http.StatusNotFound, "session not found")
+		return models.FocusSession{}, false
+	}
+	return session, true
+}
+
+func (h *Handler) saveSession(w http.ResponseWriter, session models.FocusSession) bool {
+	if err := h.store.UpdateFocusSession(session); err != nil {
+		h.logger.Error("update focus session failed", slog.Any("error", err))
+		respondError(w, http.StatusInternalServerError, "db error")
+		return false
+	}
+	return true
+}
+
+func (h *Handler) respondSession(w http.ResponseWriter, session models.FocusSession) {
+	stats, err := h.sessionStats(session, h.clock.Now().UnixMilli())
+	if err != nil {
+		h.logger.Error("focus session stats failed", slog.Any("error", err))
+		respondError(w, http.StatusInternalServerError, "db error")
+		return
+	}
+	respondJSON(w, http.StatusOK, sessionResponse{Session: session, Stats: &stats})
+}
+
+// sessionStats summarises the focus events overlapping the session. Pauses
+// only reduce ActiveMs; events recorded while paused still count per app.
+func (h *Handler) sessionStats(session models.FocusSession, nowMs int64) (models.FocusSessionStats, error) {
+	endMs := nowMs
+	if session.EndedAtMs > 0 {
+		endMs = session.EndedAtMs
+	}
+	stats := models.FocusSessionStats{
+		ElapsedMs: endMs - session.StartedAtMs,
+		ActiveMs:  sessionActiveMs(session, nowMs),
+	}
+	stats.TargetReached = stats.ActiveMs >= int64(session.TargetMinutes)*time.Minute.Milliseconds()
+
+	categorizer, err := loadCategorizer(h.store)
+	if err != nil {
+		return stats, err
+	}
+	events, err := h.store.ListFocusEventsRange(session.StartedAtMs, endMs)
+	if err != nil {
+		return stats, err
+	}
+	stats.CategoryMs = focus.CategoryDurations(events, categorizer, session.StartedAtMs, endMs)
+
+	byApp := map[string]int64{}
+	lastApp := ""
+	for i, event := range events {
+		start := max(event.TsMs, session.StartedAtMs)
+		end := min(focus.EventEnd(events, i, endMs), endMs)
+		if end <= start {
+			continue
+		}
+		byApp[event.AppName] += end - start
+		if lastApp != "" && !strings.EqualFold(lastApp, event.AppName) {
+			stats.SwitchCount++
+		}
+		lastApp = event.AppName
+	}
+	stats.TopApps = make([]models.FocusAppDuration, 0, len(byApp))
+	for app, ms := range byApp {
+		stats.TopApps = append(stats.TopApps, models.FocusAppDuration{AppName: app, DurationMs: ms})
+	}
+	sort.Slice(stats.TopApps, func(i, j int) bool {
+		if stats.TopApps[i].DurationMs != stats.TopApps[j].DurationMs {
+			return stats.TopApps[i].DurationMs > stats.TopApps[j].DurationMs
+		}
+		return stats.TopApps[i].AppName < stats.TopApps[j].AppName
+	})
+	if len(stats.TopApps) > sessionTopApps {
+		stats.TopApps = stats.TopApps[:sessionTopApps]
+	}
+	return stats, nil
+}
+
+func sessionActiveMs(session models.FocusSession, nowMs int64) int64 {
+	endMs := nowMs
+	if session.EndedAtMs > 0 {
+		endMs = session.EndedAtMs
+	}
+	active := endMs - session.StartedAtMs - session.PausedMs
+	if session.Status == models.SessionPaused && session.PausedAtMs > 0 {
+		active -= endMs - session.PausedAtMs
+	}
+	return max(active, 0)
+}
+
+func sessionEndAction(session models.FocusSession, stats models.FocusSessionStats) models.Action {
+	minutes := int(stats.ActiveMs / time.Minute.Milliseconds())
+	message := fmt.Sprintf("「%s」专注结束，共专注 %d 分钟。起身活动一下，休息 5 分钟吧。", session.Name, minutes)
+	if !stats.TargetReached {
+		message = fmt.Sprintf("「%s」提前结束，已专注 %d 分钟（目标 %d 分钟）。先休息一下，再重新开始。", session.Name, minutes, session.TargetMinutes)
+	}
+	return models.Action{
+		ActionType: models.ActionRestReminder,
+		Message:    message,
+		Confidence: 1,
+		Cost:       0,
+		RiskLevel:  models.RiskLow,
+		Reason:     "focus_session_ended",
+	}
+}
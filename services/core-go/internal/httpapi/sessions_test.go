@@ -0,0 +1,139 @@
+package httpapi
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"always/core/internal/focus"
+	"always/core/internal/models"
+)
+
+func TestSessionLifecycle(t *testing.T) {
+	env := newTestEnv(t)
+	env.observe(0, focus.FocusSnapshot{AppName: "Code", PID: 1})
+
+	var started sessionResponse
+	start := models.FocusSessionStartRequest{Name: "write", TargetMinutes: 20}
+	if status := env.call(t, http.MethodPost, "/v1/sessions", start, &started); status != http.StatusOK {
+		t.Fatalf("start status = %d", status)
+	}
+	if started.Session.StartedAtMs != testStart.UnixMilli() || started.Session.Status != models.SessionActive {
+		t.Fatalf("session = %+v", started.Session)
+	}
+	path := fmt.Sprintf("/v1/sessions/%d", started.Session.ID)
+
+	env.observe(5*time.Minute, focus.FocusSnapshot{AppName: "Slack", PID: 2})
+	if status := env.call(t, http.MethodPost, path+"/pause", nil, nil); status != http.StatusOK {
+		t.Fatalf("pause status = %d", status)
+	}
+	env.observe(10*time.Minute, focus.FocusSnapshot{AppName: "Code", PID: 1})
+	var resumed sessionResponse
+	if status := env.call(t, http.MethodPost, path+"/resume", nil, &resumed); status != http.StatusOK {
+		t.Fatalf("resume status = %d", status)
+	}
+	if resumed.Session.PausedMs != (5 * time.Minute).Milliseconds() {
+		t.Fatalf("paused_ms = %d", resumed.Session.PausedMs)
+	}
+
+	env.clock.Set(testStart.Add(30 * time.Minute))
+	var ended sessionResponse
+	if status := env.call(t, http.MethodPost, path+"/end", nil, &ended); status != http.StatusOK {
+		t.Fatalf("end status = %d", status)
+	}
+	stats := ended.Stats
+	if stats == nil || stats.ActiveMs != (25*time.Minute).Milliseconds() || !stats.TargetReached || stats.SwitchCount != 2 {
+		t.Fatalf("stats = %+v", stats)
+	}
+	if len(stats.TopApps) != 2 || stats.TopApps[0].AppName != "Code" || stats.TopApps[0].DurationMs != (25*time.Minute).Milliseconds() {
+		t.Fatalf("top apps = %+v", stats.TopApps)
+	}
+	if status := env.call(t, http.MethodPost, path+"/end", nil, nil); status != http.StatusConflict {
+		t.Fatalf("second end status = %d", status)
+	}
+}
+
+func TestSessionEndReminderBypassesCooldowns(t *testing.T) {
+	env := newTestEnv(t)
+	env.action = models.Action{ActionType: models.ActionRestReminder, Message: "stretch", Confidence: 0.9, RiskLevel: models.RiskLow}
+	if resp := env.decide(t, models.ModeActive, nil); resp.Action.ActionType != models.ActionRestReminder {
+		t.Fatalf("first reminder = %+v %+v", resp.Action, resp.GatewayDecision)
+	}
+
+	var started sessionResponse
+	if status := env.call(t, http.MethodPost, "/v1/sessions", models.FocusSessionStartRequest{TargetMinutes: 1}, &started); status != http.StatusOK {
+		t.Fatalf("start status = %d", status)
+	}
+	// Well inside both the shared and the 45-minute REST_REMINDER cooldown.
+	env.clock.Advance(2 * time.Minute)
+	var ended sessionResponse
+	if status := env.call(t, http.MethodPost, fmt.Sprintf("/v1/sessions/%d/end", started.Session.ID), sessionEndRequest{Mode: models.ModeActive}, &ended); status != http.StatusOK {
+		t.Fatalf("end status = %d", status)
+	}
+	if ended.Decision == nil || ended.Decision.Action.ActionType != models.ActionRestReminder || ended.Decision.GatewayDecision.Decision != models.GatewayAllow {
+		t.Fatalf("end decision = %+v", ended.Decision)
+	}
+}
+
+func TestSessionStartIsExclusive(t *testing.T) {
+	env := newTestEnv(t)
+	var first sessionResponse
+	if status := env.call(t, http.MethodPost, "/v1/sessions", models.FocusSessionStartRequest{}, &first); status != http.StatusOK {
+		t.Fatalf("start status = %d", status)
+	}
+	var conflict struct {
+		Session models.FocusSession `json:"session"`
+	}
+	if status := env.call(t, http.MethodPost, "/v1/sessions", models.FocusSessionStartRequest{}, &conflict); status != http.StatusConflict || conflict.Session.ID != first.Session.ID {
+		t.Fatalf("second start = %d %+v", status, conflict)
+	}
+	// A paused session still blocks a new one.
+	path := fmt.Sprintf("/v1/sessions/%d", first.Session.ID)
+	env.call(t, http.MethodPost, path+"/pause", nil, nil)
+	if status := env.call(t, http.MethodPost, "/v1/sessions", models.FocusSessionStartRequest{}, nil); status != http.StatusConflict {
+		t.Fatalf("start while paused = %d", status)
+	}
+	env.call(t, http.MethodPost, path+"/end", nil, nil)
+	if status := env.call(t, http.MethodPost, "/v1/sessions", models.FocusSessionStartRequest{}, nil); status != http.StatusOK {
+		t.Fatalf("start after end = %d", status)
+	}
+}
+
+func TestSessionEndRecordsOneReminder(t *testing.T) {
+	env := newTestEnv(t)
+	var started sessionResponse
+	if status := env.call(t, http.MethodPost, "/v1/sessions", models.FocusSessionStartRequest{}, &started); status != http.StatusOK {
+		t.Fatalf("start status = %d", status)
+	}
+	env.clock.Set(testStart.Add(25 * time.Minute))
+	path := fmt.Sprintf("/v1/sessions/%d/end", started.Session.ID)
+
+	const enders = 8
+	statuses := make(chan int, enders)
+	var wg sync.WaitGroup
+	for range enders {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			statuses <- env.call(t, http.MethodPost, path, nil, nil)
+		}()
+	}
+	wg.Wait()
+	close(statuses)
+	counts := map[int]int{}
+	for status := range statuses {
+		counts[status]++
+	}
+	if counts[http.StatusOK] != 1 || counts[http.StatusConflict] != enders-1 {
+		t.Fatalf("end statuses = %v, want one 200 and the rest 409", counts)
+	}
+	var reminders int
+	if err := env.store.DB().QueryRow(`SELECT COUNT(*) FROM event_logs WHERE policy_version = 'focus_session'`).Scan(&reminders); err != nil {
+		t.Fatal(err)
+	}
+	if reminders != 1 {
+		t.Fatalf("break reminders recorded = %d, want 1", reminders)
+	}
+}
@@ -0,0 +1,96 @@
+package httpapi
+
+import (
+	"net/http"
+	"strconv"
+
+	"always/core/internal/gateway"
+)
+
+// settingSpec describes one key accepted by POST /v1/settings so clients can
+// render and validate forms without hard-coding them.
+type settingSpec struct {
+	Key         string   `json:"key"`
+	Type        string   `json:"type"`
+	Default     string   `json:"default,omitempty"`
+	Min         *float64 `json:"min,omitempty"`
+	Max         *float64 `json:"max,omitempty"`
+	Options     []string `json:"options,omitempty"`
+	Unit        string   `json:"unit,omitempty"`
+	Description string   `json:"description"`
+}
+
+func settingsSchema() []settingSpec {
+	zero := 0.0
+	one := 1.0
+	specs := []settingSpec{
+		{Key: settingQuietHours, Type: "string", Description: "Quiet hours as HH:MM-HH:MM; may wrap midnight."},
+		{Key: settingQuietCalendar, Type: "json", Default: "{}", Description: "Quiet windows per weekday, quiet dates and date ranges, and an override that forces quiet on or off until a time; applies on top of quiet_hours."},
+		{Key: settingCalendarPath, Type: "string", Description: "Local .ics file or directory of .ics files; meetings silence suggestions. Overrides CALENDAR_ICS_PATH."},
+		{Key: settingInterventionBudget, Type: "enum", Default: "medium", Options: []string{"low", "medium", "high"}, Description: "Scales every mode budget."},
+		{Key: settingFocusMonitor, Type: "bool", Default: "false", Description: "Record the foreground app and window title."},
+		{Key: settingFocusProviderCmd, Type: "string", Default: "builtin", Description: "External focus provider command, or builtin."},
+		{Key: settingFocusIdleThreshold, Type: "int", Default: "300", Min: &zero, Unit: "seconds", Description: "Input-free time before the user counts as idle; 0 disables idle detection."},
+		{Key: settingTitleRedaction, Type: "json", Default: "[]", Description: "Window title redaction rules applied before persistence."},
+		{Key: settingOllamaModel, Type: "string", Description: "Ollama model used by the AI service."},
+		{Key: settingAgentEnabled, Type: "bool", Default: "true", Description: "Generate suggestions at all."},
+		{Key: settingRuleOnlyMode, Type: "bool", Default: "false", Description: "Skip the AI service and only apply rules."},
+		{Key: settingAdaptiveBudget, Type: "bool", Default: "true", Description: "Scale mode budgets and recovery by recent feedback acceptance, within 0.6x to 1.4x."},
+		{Key: settingBudgetSilent, Type: "number", Default: "2", Min: &zero, Description: "Budget in SILENT mode."},
+		{Key: settingBudgetLight, Type: "number", Default: "6", Min: &zero, Description: "Budget in LIGHT mode."},
+		{Key: settingBudgetActive, Type: "number", Default: "10", Min: &zero, Description: "Budget in ACTIVE mode."},
+		{Key: settingDailyBudgetCap, Type: "number", Default: "0", Min: &zero, Description: "Daily cost cap; 0 means unlimited."},
+		{Key: settingHourlyBudgetCap, Type: "number", Default: "0", Min: &zero, Description: "Hourly cost cap; 0 means unlimited."},
+		{Key: settingCooldownSeconds, Type: "int", Default: "300", Min: &zero, Unit: "seconds", Description: "Minimum gap between interventions."},
+		{Key: gateway.SettingBudgetSchedule, Type: "json", Default: "[]", Description: "Time-of-day profiles overriding mode budgets, caps, recovery rate and cooldown; the first profile matching the current time and weekday wins."},
+		{Key: settingCooldownEncourage, Type: "int", Default: "0", Min: &zero, Unit: "seconds", Description: "Minimum gap between two ENCOURAGE actions; when set it replaces cooldown_seconds for them. 0 disables."},
+		{Key: settingCooldownTaskBreakdown, Type: "int", Default: "0", Min: &zero, Unit: "seconds", Description: "Minimum gap between two TASK_BREAKDOWN actions; when set it replaces cooldown_seconds for them. 0 disables."},
+		{Key: settingCooldownRestReminder, Type: "int", Default: "2700", Min: &zero, Unit: "seconds", Description: "Minimum gap between two REST_REMINDER actions; when set it replaces cooldown_seconds for them. 0 disables."},
+		{Key: settingCooldownReframe, Type: "int", Default: "0", Min: &zero, Unit: "seconds", Description: "Minimum gap between two REFRAME actions; when set it replaces cooldown_seconds for them. 0 disables."},
+		{Key: settingDailyLimitEncourage, Type: "int", Default: "0", Min: &zero, Description: "ENCOURAGE actions allowed per day; 0 means unlimited."},
+		{Key: settingDailyLimitTaskBreakdown, Type: "int", Default: "3", Min: &zero, Description: "TASK_BREAKDOWN actions allowed per day; 0 means unlimited."},
+		{Key: settingDailyLimitRestReminder, Type: "int", Default: "0", Min: &zero, Description: "REST_REMINDER actions allowed per day; 0 means unlimited."},
+		{Key: settingDailyLimitReframe, Type: "int", Default: "0", Min: &zero, Description: "REFRAME actions allowed per day; 0 means unlimited."},
+		{Key: gateway.SettingGatewayRules, Type: "json", Description: "Ordered gateway rules; replaces the rules file and the built-in rules."},
+		{Key: gateway.SettingGatewayLadder, Type: "json", Description: "Cheaper action types each type steps down to when blocked, most intrusive first; DO_NOT_DISTURB is always the last rung."},
+		{Key: settingLadderThreshold, Type: "int", Default: "3", Min: &one, Description: "IGNORED feedback in a row that moves an action type one rung down its ladder; ADOPTED or LIKE moves it back up."},
+	}
+
+	defaults := defaultFocusThresholds()
+	thresholds := []struct {
+		key         string
+		value       int
+		unit        string
+		description string
+	}{
+		{settingFocusSwitchWindow, defaults.SwitchWindowMinutes, "minutes", "Window switch_count is counted over."},
+		{settingFocusNoProgressHold, 45, "minutes", "Unchanged window title time before no-progress is reported."},
+		{settingStateNoProgressMinutes, defaults.NoProgressMinutes, "minutes", "No-progress time that makes the focus state NO_PROGRESS."},
+		{settingStateSwitchCount, defaults.SwitchCount, "", "Switches within the window that make the focus state DISTRACTED."},
+		{settingStateFocusedMinutes, defaults.FocusedMinutes, "minutes", "Time on one app that makes the focus state FOCUSED."},
+		{settingStateDistractingMinutes, defaults.DistractingMinutes, "minutes", "Distracting time in the last 10 minutes that makes the focus state DISTRACTED."},
+		{settingPatternPingPongSwitches, defaults.PingPongSwitches, "", "Back-and-forth switches between two apps, at least one not productive, that make the focus state PING_PONG."},
+		{settingPatternPeekSeconds, defaults.PeekSeconds, "seconds", "Visits to distracting apps shorter than this count as peeks."},
+		{settingPatternPeekCount, defaults.PeekCount, "", "Peeks within the window that make the focus state PEEKING."},
+		{settingMicroSwitchSeconds, defaults.MicroSwitchSeconds, "seconds", "Visits shorter than this between two stays on the same app are merged back into it; 0 disables merging."},
+		{settingPatternReturnMinutes, defaults.ReturnMinutes, "minutes", "Time away from productive apps, ongoing or on average, that makes the focus state SLOW_RETURN."},
+	}
+	for _, t := range thresholds {
+		bounds := focusThresholdRanges[t.key]
+		minValue, maxValue := float64(bounds.Min), float64(bounds.Max)
+		specs = append(specs, settingSpec{
+			Key:         t.key,
+			Type:        "int",
+			Default:     strconv.Itoa(t.value),
+			Min:         &minValue,
+			Max:         &maxValue,
+			Unit:        t.unit,
+			Description: t.description,
+		})
+	}
+	return specs
+}
+
+func (h *Handler) handleSettingsSchema(w http.ResponseWriter, _ *http.Request) {
+	respondJSON(w, http.StatusOK, settingsSchema())
+}
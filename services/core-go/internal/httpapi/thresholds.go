@@ -0,0 +1,113 @@
+package httpapi
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"always/core/internal/db"
+	"always/core/internal/focus"
+)
+
+const (
+	settingFocusSwitchWindow       = "focus_switch_window_minutes"
+	settingFocusNoProgressHold     = "focus_no_progress_hold_minutes"
+	settingStateNoProgressMinutes  = "focus_state_no_progress_minutes"
+	settingStateSwitchCount        = "focus_state_switch_count"
+	settingStateFocusedMinutes     = "focus_state_focused_minutes"
+	settingStateDistractingMinutes = "focus_state_distracting_minutes"
+	settingPatternPingPongSwitches = "focus_pattern_ping_pong_switches"
+	settingPatternPeekSeconds      = "focus_pattern_peek_seconds"
+	settingPatternPeekCount        = "focus_pattern_peek_count"
+	settingPatternReturnMinutes    = "focus_pattern_return_minutes"
+	settingMicroSwitchSeconds      = "focus_micro_switch_seconds"
+)
+
+type intRange struct {
+	Min int
+	Max int
+}
+
+// focusThresholdRanges bounds every integer focus threshold setting.
+var focusThresholdRanges = map[string]intRange{
+	settingFocusSwitchWindow:       {Min: 1, Max: 120},
+	settingFocusNoProgressHold:     {Min: 1, Max: 480},
+	settingStateNoProgressMinutes:  {Min: 1, Max: 480},
+	settingStateSwitchCount:        {Min: 1, Max: 200},
+	settingStateFocusedMinutes:     {Min: 1, Max: 480},
+	settingStateDistractingMinutes: {Min: 1, Max: 120},
+	settingPatternPingPongSwitches: {Min: 2, Max: 100},
+	settingPatternPeekSeconds:      {Min: 1, Max: 600},
+	settingPatternPeekCount:        {Min: 1, Max: 100},
+	settingPatternReturnMinutes:    {Min: 1, Max: 120},
+	settingMicroSwitchSeconds:      {Min: 0, Max: 60},
+}
+
+// focusThresholds are the cut-offs deriveFocusState uses. They are read per
+// request, so changed settings apply immediately. SwitchWindowMinutes only
+// matters when the monitor is off and switches come from stored events.
+type focusThresholds struct {
+	SwitchWindowMinutes int
+	NoProgressMinutes   int
+	SwitchCount         int
+	FocusedMinutes      int
+	DistractingMinutes  int
+	PingPongSwitches    int
+	PeekSeconds         int
+	PeekCount           int
+	ReturnMinutes       int
+	MicroSwitchSeconds  int
+}
+
+func defaultFocusThresholds() focusThresholds {
+	return focusThresholds{
+		SwitchWindowMinutes: 10,
+		NoProgressMinutes:   20,
+		SwitchCount:         8,
+		FocusedMinutes:      25,
+		DistractingMinutes:  5,
+		PingPongSwitches:    6,
+		PeekSeconds:         30,
+		PeekCount:           3,
+		ReturnMinutes:       3,
+		MicroSwitchSeconds:  3,
+	}
+}
+
+func (t focusThresholds) patterns() focus.PatternThresholds {
+	return focus.PatternThresholds{
+		PingPongSwitches: t.PingPongSwitches,
+		PeekDuration:     time.Duration(t.PeekSeconds) * time.Second,
+		PeekCount:        t.PeekCount,
+		ReturnLatency:    time.Duration(t.ReturnMinutes) * time.Minute,
+	}
+}
+
+func loadFocusThresholds(store *db.Store) (focusThresholds, error) {
+	thresholds := defaultFocusThresholds()
+	fields := map[string]*int{
+		settingFocusSwitchWindow:       &thresholds.SwitchWindowMinutes,
+		settingStateNoProgressMinutes:  &thresholds.NoProgressMinutes,
+		settingStateSwitchCount:        &thresholds.SwitchCount,
+		settingStateFocusedMinutes:     &thresholds.FocusedMinutes,
+		settingStateDistractingMinutes: &thresholds.DistractingMinutes,
+		settingPatternPingPongSwitches: &thresholds.PingPongSwitches,
+		settingPatternPeekSeconds:      &thresholds.PeekSeconds,
+		settingPatternPeekCount:        &thresholds.PeekCount,
+		settingPatternReturnMinutes:    &thresholds.ReturnMinutes,
+		settingMicroSwitchSeconds:      &thresholds.MicroSwitchSeconds,
+	}
+	for key, field := range fields {
+		value, ok, err := store.GetSetting(key)
+		if err != nil {
+			return thresholds, err
+		}
+		if !ok {
+			continue
+		}
+		if parsed, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && parsed >= focusThresholdRanges[key].Min {
+			*field = parsed
+		}
+	}
+	return thresholds, nil
+}
@@ -0,0 +1,98 @@
+package httpapi
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"always/core/internal/focus"
+	"always/core/internal/models"
+)
+
+func TestDeriveFocusState(t *testing.T) {
+	defaults := defaultFocusThresholds()
+	strict := defaults
+	strict.SwitchCount = 3
+	strict.FocusedMinutes = 10
+	strict.NoProgressMinutes = 5
+
+	tests := []struct {
+		name       string
+		in         focusStateInput
+		thresholds focusThresholds
+		want       string
+	}{
+		{"idle wins", focusStateInput{Idle: true, SwitchCount: 50}, defaults, "IDLE"},
+		{"no progress", focusStateInput{NoProgress: true, NoProgressDuration: 20 * time.Minute}, defaults, "NO_PROGRESS"},
+		{"no progress below threshold", focusStateInput{NoProgress: true, NoProgressDuration: 10 * time.Minute}, defaults, "LIGHT"},
+		{"no progress custom", focusStateInput{NoProgress: true, NoProgressDuration: 6 * time.Minute}, strict, "NO_PROGRESS"},
+		{"ping pong", focusStateInput{Pattern: models.FocusPatternPingPong, FocusMinutes: 40}, defaults, "PING_PONG"},
+		{"peeking", focusStateInput{Pattern: models.FocusPatternPeek}, defaults, "PEEKING"},
+		{"slow return", focusStateInput{Pattern: models.FocusPatternSlowReturn}, defaults, "SLOW_RETURN"},
+		{"switches", focusStateInput{SwitchCount: 8}, defaults, "DISTRACTED"},
+		{"switches custom", focusStateInput{SwitchCount: 3}, strict, "DISTRACTED"},
+		{"distracting minutes", focusStateInput{DistractingMinutes: 5}, defaults, "DISTRACTED"},
+		{"focused", focusStateInput{FocusMinutes: 25}, defaults, "FOCUSED"},
+		{"focused custom", focusStateInput{FocusMinutes: 12}, strict, "FOCUSED"},
+		{"light", focusStateInput{FocusMinutes: 12, SwitchCount: 3}, defaults, "LIGHT"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := deriveFocusState(tt.in, tt.thresholds); got != tt.want {
+				t.Fatalf("state = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFocusThresholdSettingsValidation(t *testing.T) {
+	env := newTestEnv(t)
+	for key, bounds := range focusThresholdRanges {
+		for _, value := range []int{bounds.Min - 1, bounds.Max + 1} {
+			req := models.SettingRequest{Key: key, Value: strconv.Itoa(value)}
+			if status := env.call(t, http.MethodPost, "/v1/settings", req, nil); status != http.StatusBadRequest {
+				t.Fatalf("%s=%d status = %d", key, value, status)
+			}
+		}
+		env.setSetting(t, key, strconv.Itoa(bounds.Max))
+	}
+
+	var schema []settingSpec
+	if status := env.call(t, http.MethodGet, "/v1/settings/schema", nil, &schema); status != http.StatusOK {
+		t.Fatalf("schema status = %d", status)
+	}
+	found := map[string]bool{}
+	for _, spec := range schema {
+		found[spec.Key] = true
+	}
+	for key := range focusThresholdRanges {
+		if !found[key] {
+			t.Errorf("%s missing from the settings schema", key)
+		}
+	}
+}
+
+func TestFocusThresholdsApplyLive(t *testing.T) {
+	env := newTestEnv(t)
+	env.observe(0, focus.FocusSnapshot{AppName: "Code", PID: 1})
+	env.observe(time.Minute, focus.FocusSnapshot{AppName: "Terminal", PID: 2})
+	env.observe(2*time.Minute, focus.FocusSnapshot{AppName: "Code", PID: 1})
+	env.observe(3*time.Minute, focus.FocusSnapshot{AppName: "Terminal", PID: 2})
+	if state := env.decide(t, models.ModeLight, nil).Context.FocusState; state != "LIGHT" {
+		t.Fatalf("state with defaults = %s", state)
+	}
+
+	env.setSetting(t, settingStateSwitchCount, "3")
+	if state := env.decide(t, models.ModeLight, nil).Context.FocusState; state != "DISTRACTED" {
+		t.Fatalf("state with 3 switches = %s", state)
+	}
+	// A one-minute window drops the switch at 1m.
+	env.setSetting(t, settingFocusSwitchWindow, "1")
+	if got := env.monitor.SwitchCount(); got != 2 {
+		t.Fatalf("monitor switches = %d, want 2", got)
+	}
+	if state := env.decide(t, models.ModeLight, nil).Context.FocusState; state != "LIGHT" {
+		t.Fatalf("state with 1m window = %s", state)
+	}
+}
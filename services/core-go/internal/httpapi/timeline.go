@@ -0,0 +1,96 @@
+package httpapi
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"always/core/internal/focus"
+	"always/core/internal/models"
+)
+
+const (
+	defaultTimelineRange = 7 * 24 * time.Hour
+	maxTimelineRange     = 90 * 24 * time.Hour
+	defaultStretchLimit  = 5
+	maxStretchLimit      = 50
+)
+
+func (h *Handler) handleFocusTimeline(w http.ResponseWriter, r *http.Request) {
+	sinceMs, untilMs, ok := parseTimelineRange(w, r, h.clock.Now().UnixMilli())
+	if !ok {
+		return
+	}
+	limit := defaultStretchLimit
+	if l := r.URL.Query().Get("stretches"); l != "" {
+		if parsed, err := parseInt(l); err == nil && parsed > 0 && parsed <= maxStretchLimit {
+			limit = parsed
+		}
+	}
+	events, categorizer, ok := h.timelineEvents(w, sinceMs, untilMs)
+	if !ok {
+		return
+	}
+	respondJSON(w, http.StatusOK, focus.BuildTimeline(events, categorizer, sinceMs, untilMs, time.Local, limit))
+}
+
+func (h *Handler) handleFocusHeatmap(w http.ResponseWriter, r *http.Request) {
+	sinceMs, untilMs, ok := parseTimelineRange(w, r, h.clock.Now().UnixMilli())
+	if !ok {
+		return
+	}
+	events, categorizer, ok := h.timelineEvents(w, sinceMs, untilMs)
+	if !ok {
+		return
+	}
+	respondJSON(w, http.StatusOK, focus.BuildHeatmap(events, categorizer, sinceMs, untilMs, time.Local))
+}
+
+func (h *Handler) timelineEvents(w http.ResponseWriter, sinceMs int64, untilMs int64) ([]models.FocusEvent, *focus.Categorizer, bool) {
+	categorizer, err := loadCategorizer(h.store)
+	if err != nil {
+		h.logger.Error("load focus categories failed", slog.Any("error", err))
+		respondError(w, http.StatusInternalServerError, "db error")
+		return nil, nil, false
+	}
+	events, err := h.store.ListFocusEventsRange(sinceMs, untilMs)
+	if err != nil {
+		h.logger.Error("list focus events failed", slog.Any("error", err))
+		respondError(w, http.StatusInternalServerError, "db error")
+		return nil, nil, false
+	}
+	return events, categorizer, true
+}
+
+// parseTimelineRange reads since_ms/until_ms, defaulting to the last week.
+// until_ms is capped at now so the open event is counted up to the present,
+// as FocusMetrics does.
+func parseTimelineRange(w http.ResponseWriter, r *http.Request, nowMs int64) (int64, int64, bool) {
+	untilMs := nowMs
+	if s := r.URL.Query().Get("until_ms"); s != "" {
+		parsed, err := parseInt64(s)
+		if err != nil || parsed <= 0 {
+			respondError(w, http.StatusBadRequest, "invalid until_ms")
+			return 0, 0, false
+		}
+		untilMs = min(parsed, nowMs)
+	}
+	sinceMs := untilMs - defaultTimelineRange.Milliseconds()
+	if s := r.URL.Query().Get("since_ms"); s != "" {
+		parsed, err := parseInt64(s)
+		if err != nil || parsed < 0 {
+			respondError(w, http.StatusBadRequest, "invalid since_ms")
+			return 0, 0, false
+		}
+		sinceMs = parsed
+	}
+	if sinceMs >= untilMs {
+		respondError(w, http.StatusBadRequest, "since_ms must be before until_ms")
+		return 0, 0, false
+	}
+	if untilMs-sinceMs > maxTimelineRange.Milliseconds() {
+		respondError(w, http.StatusBadRequest, "range too large")
+		return 0, 0, false
+	}
+	return sinceMs, untilMs, true
+}
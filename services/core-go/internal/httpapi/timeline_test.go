@@ -0,0 +1,69 @@
+package httpapi
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"always/core/internal/focus"
+	"always/core/internal/models"
+)
+
+func TestFocusTimelineEndpoints(t *testing.T) {
+	env := newTestEnv(t)
+	env.observe(0, focus.FocusSnapshot{AppName: "Code", PID: 1})
+	env.observe(20*time.Minute, focus.FocusSnapshot{AppName: "Slack", PID: 2})
+	env.clock.Set(testStart.Add(30 * time.Minute))
+
+	// until_ms past now is capped, so the open Slack event counts 10 minutes.
+	query := fmt.Sprintf("?since_ms=%d&until_ms=%d", testStart.UnixMilli(), testStart.Add(time.Hour).UnixMilli())
+	var timeline models.FocusTimeline
+	if status := env.call(t, http.MethodGet, "/v1/focus/timeline"+query, nil, &timeline); status != http.StatusOK {
+		t.Fatalf("timeline status = %d", status)
+	}
+	if timeline.UntilMs != env.clock.Now().UnixMilli() || len(timeline.Days) != 1 {
+		t.Fatalf("timeline = %+v", timeline)
+	}
+	day := timeline.Days[0]
+	if day.TotalMinutes != 30 || day.AppMinutes["Code"] != 20 || day.AppMinutes["Slack"] != 10 || day.Switches != 1 {
+		t.Fatalf("day = %+v", day)
+	}
+	metrics, err := env.store.FocusMetrics(env.clock.Now().UnixMilli(), (30 * time.Minute).Milliseconds())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metrics.FocusMinutes != day.TotalMinutes || metrics.SwitchCount != day.Switches {
+		t.Fatalf("metrics = %+v, timeline day = %+v", metrics, day)
+	}
+
+	var heatmap models.FocusHeatmap
+	if status := env.call(t, http.MethodGet, "/v1/focus/heatmap"+query, nil, &heatmap); status != http.StatusOK {
+		t.Fatalf("heatmap status = %d", status)
+	}
+	if len(heatmap.Cells) != 1 || heatmap.Cells[0].Hour != testStart.Hour() || heatmap.Cells[0].Minutes != 30 {
+		t.Fatalf("cells = %+v", heatmap.Cells)
+	}
+}
+
+func TestFocusTimelineRangeValidation(t *testing.T) {
+	env := newTestEnv(t)
+	nowMs := env.clock.Now().UnixMilli()
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"bad since", "?since_ms=abc"},
+		{"bad until", "?until_ms=-1"},
+		{"empty range", fmt.Sprintf("?since_ms=%d&until_ms=%d", nowMs, nowMs)},
+		{"since in the future", fmt.Sprintf("?since_ms=%d", nowMs+1)},
+		{"too long", fmt.Sprintf("?since_ms=%d", nowMs-(91*24*time.Hour).Milliseconds())},
+	}
+	for _, tt := range tests {
+		for _, path := range []string{"/v1/focus/timeline", "/v1/focus/heatmap"} {
+			if status := env.call(t, http.MethodGet, path+tt.query, nil, nil); status != http.StatusBadRequest {
+				t.Errorf("%s %s: status = %d", tt.name, path, status)
+			}
+		}
+	}
+}
@@ -37,6 +37,8 @@ const (
 	ActionTaskBreakdown ActionType = "TASK_BREAKDOWN"
 	ActionRestReminder  ActionType = "REST_REMINDER"
 	ActionReframe       ActionType = "REFRAME"
+	// ActionAmbient only tints the floating ball; it shows no message.
+	ActionAmbient ActionType = "AMBIENT"
 )
 
 type GatewayDecisionType string
@@ -45,6 +47,8 @@ const (
 	GatewayAllow    GatewayDecisionType = "ALLOW"
 	GatewayDeny     GatewayDecisionType = "DENY"
 	GatewayOverride GatewayDecisionType = "OVERRIDE"
+	// GatewayDegrade replaces an action with a cheaper one on its ladder.
+	GatewayDegrade GatewayDecisionType = "DEGRADE"
 )
 
 type Context struct {
@@ -78,6 +82,34 @@ type GatewayDecision struct {
 	Decision             GatewayDecisionType `json:"decision"`
 	Reason               string              `json:"reason"`
 	OverriddenActionType ActionType          `json:"overridden_action_type,omitempty"`
+	Pricing              *GatewayPricing     `json:"pricing,omitempty"`
+	Trace                []GatewayTraceStep  `json:"trace,omitempty"`
+}
+
+// GatewayTraceStep is one check the gateway ran, in evaluation order, with
+// the numbers it looked at.
+type GatewayTraceStep struct {
+	Check   string             `json:"check"`
+	Outcome string             `json:"outcome"`
+	Reason  string             `json:"reason,omitempty"`
+	Inputs  map[string]float64 `json:"inputs,omitempty"`
+}
+
+// GatewayPricing breaks down what an action costs against the budget.
+type GatewayPricing struct {
+	TableCost        float64          `json:"table_cost"`
+	ReportedCost     float64          `json:"reported_cost"`
+	BlendedCost      float64          `json:"blended_cost"`
+	Confidence       float64          `json:"confidence"`
+	ConfidenceFactor float64          `json:"confidence_factor"`
+	Multipliers      []CostMultiplier `json:"multipliers,omitempty"`
+	Cost             float64          `json:"cost"`
+}
+
+// CostMultiplier is a cost_multiplier gateway rule that matched.
+type CostMultiplier struct {
+	Rule   string  `json:"rule"`
+	Factor float64 `json:"factor"`
 }
 
 type DecisionResponse struct {
@@ -92,6 +124,12 @@ type DecisionResponse struct {
 	GatewayDecision GatewayDecision `json:"gateway_decision"`
 }
 
+// FeedbackSample is one piece of explicit or implicit feedback.
+type FeedbackSample struct {
+	Feedback    FeedbackType `json:"feedback"`
+	CreatedAtMs int64        `json:"created_at_ms"`
+}
+
 type FeedbackRequest struct {
 	RequestID    string       `json:"request_id"`
 	Feedback     FeedbackType `json:"feedback"`
@@ -160,6 +198,32 @@ type BudgetUsage struct {
 	HourlyHour string  `json:"hourly_hour"`
 }
 
+// ActionUsage tracks when an action type last fired and how often it fired on
+// DailyDay, for per-type cooldowns and daily limits.
+type ActionUsage struct {
+	ActionType ActionType `json:"action_type"`
+	LastMs     int64      `json:"last_ms"`
+	DailyDay   string     `json:"daily_day"`
+	DailyCount int        `json:"daily_count"`
+	// LadderLevel is how many rungs down the ladder feedback has moved this
+	// action type; IgnoredStreak counts IGNORED feedback since the last move.
+	LadderLevel   int `json:"ladder_level"`
+	IgnoredStreak int `json:"ignored_streak"`
+}
+
+// GatewayStateVersion is bumped whenever GatewayState changes incompatibly.
+const GatewayStateVersion = 1
+
+// GatewayState is everything the gateway needs to resume after a restart.
+type GatewayState struct {
+	Version            int              `json:"version"`
+	ModeBudgets        map[Mode]float64 `json:"mode_budgets"`
+	LastUpdateMs       map[Mode]int64   `json:"last_update_ms"`
+	LastInterventionMs int64            `json:"last_intervention_ms"`
+	Usage              BudgetUsage      `json:"usage"`
+	Actions            []ActionUsage    `json:"actions"`
+}
+
 type FocusStateSnapshot struct {
 	TsMs         int64   `json:"ts_ms"`
 	FocusState   string  `json:"focus_state"`
@@ -184,13 +248,197 @@ type FocusEvent struct {
 	PID         int    `json:"pid,omitempty"`
 	DurationMs  int64  `json:"duration_ms"`
 	WindowTitle string `json:"window_title,omitempty"`
+	Source      string `json:"source,omitempty"`
+	URL         string `json:"url,omitempty"`
+	Domain      string `json:"domain,omitempty"`
+	Category    string `json:"category,omitempty"`
+	// MergedPeeks counts the micro-switches folded into this event and
+	// MergedPeekMs the time they lasted.
+	MergedPeeks  int   `json:"merged_peeks,omitempty"`
+	MergedPeekMs int64 `json:"merged_peek_ms,omitempty"`
+	// LocalEventID is set on ingested events that overlap a locally polled
+	// one; they are kept for reference but left out of aggregates.
+	LocalEventID int64 `json:"local_event_id,omitempty"`
+}
+
+// FocusCompaction reports one compaction pass over stored focus events.
+type FocusCompaction struct {
+	SinceMs     int64 `json:"since_ms"`
+	UntilMs     int64 `json:"until_ms"`
+	Scanned     int   `json:"scanned"`
+	Deleted     int   `json:"deleted"`
+	MergedPeeks int   `json:"merged_peeks"`
+}
+
+// FocusSourceLocal tags events recorded by the focus monitor itself.
+const FocusSourceLocal = "local"
+
+type FocusIngestEvent struct {
+	Source      string `json:"source"`
+	AppName     string `json:"app_name,omitempty"`
+	BundleID    string `json:"bundle_id,omitempty"`
+	URL         string `json:"url,omitempty"`
+	Domain      string `json:"domain,omitempty"`
+	WindowTitle string `json:"window_title,omitempty"`
+	StartMs     int64  `json:"start_ms"`
+	EndMs       int64  `json:"end_ms"`
+}
+
+type FocusIngestRequest struct {
+	Events []FocusIngestEvent `json:"events"`
+}
+
+const (
+	CategoryProductive  = "productive"
+	CategoryNeutral     = "neutral"
+	CategoryDistracting = "distracting"
+)
+
+const (
+	CategoryMatchAppName     = "app_name"
+	CategoryMatchBundleID    = "bundle_id"
+	CategoryMatchWindowTitle = "window_title"
+	CategoryMatchDomain      = "domain"
+)
+
+// TitleRedactionRule rewrites window titles for App (app name or bundle id,
+// empty or "*" for every app). Action is drop, hash, regex or domain.
+type TitleRedactionRule struct {
+	App         string `json:"app,omitempty"`
+	Action      string `json:"action"`
+	Pattern     string `json:"pattern,omitempty"`
+	Replacement string `json:"replacement,omitempty"`
+}
+
+type FocusCategoryRule struct {
+	ID          int64  `json:"id"`
+	Category    string `json:"category"`
+	MatchField  string `json:"match_field"`
+	Pattern     string `json:"pattern"`
+	Priority    int    `json:"priority"`
+	CreatedAtMs int64  `json:"created_at_ms"`
 }
 
 type FocusCurrent struct {
-	TsMs         int64   `json:"ts_ms"`
-	AppName      string  `json:"app_name"`
-	BundleID     string  `json:"bundle_id,omitempty"`
-	PID          int     `json:"pid,omitempty"`
-	WindowTitle  string  `json:"window_title,omitempty"`
-	FocusMinutes float64 `json:"focus_minutes"`
+	TsMs         int64                `json:"ts_ms"`
+	AppName      string               `json:"app_name"`
+	BundleID     string               `json:"bundle_id,omitempty"`
+	PID          int                  `json:"pid,omitempty"`
+	WindowTitle  string               `json:"window_title,omitempty"`
+	FocusMinutes float64              `json:"focus_minutes"`
+	Provider     *FocusProviderHealth `json:"provider,omitempty"`
+	Patterns     *FocusPatterns       `json:"patterns,omitempty"`
+}
+
+const (
+	FocusPatternPingPong   = "ping_pong"
+	FocusPatternPeek       = "peek"
+	FocusPatternSlowReturn = "slow_return"
+)
+
+// FocusPatterns describes the recent switch sequence. Pattern names the
+// strongest pattern that crossed its threshold, empty when none did.
+type FocusPatterns struct {
+	Pattern          string   `json:"pattern,omitempty"`
+	PingPongApps     []string `json:"ping_pong_apps,omitempty"`
+	PingPongSwitches int      `json:"ping_pong_switches"`
+	PeekCount        int      `json:"peek_count"`
+	PeekApps         []string `json:"peek_apps,omitempty"`
+	Returns          int      `json:"returns"`
+	ReturnLatencyMs  int64    `json:"return_latency_ms"`
+	AwayMs           int64    `json:"away_ms"`
+}
+
+type FocusUsage struct {
+	TotalMinutes    float64            `json:"total_minutes"`
+	AppMinutes      map[string]float64 `json:"app_minutes"`
+	CategoryMinutes map[string]float64 `json:"category_minutes"`
+	Switches        int                `json:"switches"`
+}
+
+type FocusDayUsage struct {
+	Date string `json:"date"`
+	FocusUsage
+}
+
+type FocusHourUsage struct {
+	Hour int `json:"hour"`
+	FocusUsage
+}
+
+type FocusHeatmapCell struct {
+	Date     string  `json:"date"`
+	Hour     int     `json:"hour"`
+	Minutes  float64 `json:"minutes"`
+	Switches int     `json:"switches"`
+}
+
+type FocusStretch struct {
+	AppName  string  `json:"app_name"`
+	Category string  `json:"category"`
+	StartMs  int64   `json:"start_ms"`
+	EndMs    int64   `json:"end_ms"`
+	Minutes  float64 `json:"minutes"`
+}
+
+type FocusTimeline struct {
+	SinceMs          int64           `json:"since_ms"`
+	UntilMs          int64           `json:"until_ms"`
+	Days             []FocusDayUsage `json:"days"`
+	LongestStretches []FocusStretch  `json:"longest_stretches"`
+}
+
+type FocusHeatmap struct {
+	SinceMs int64              `json:"since_ms"`
+	UntilMs int64              `json:"until_ms"`
+	Hours   []FocusHourUsage   `json:"hours"`
+	Cells   []FocusHeatmapCell `json:"cells"`
+}
+
+const (
+	SessionActive = "active"
+	SessionPaused = "paused"
+	SessionEnded  = "ended"
+)
+
+type FocusSession struct {
+	ID                int64  `json:"id"`
+	Name              string `json:"name"`
+	Task              string `json:"task,omitempty"`
+	TargetMinutes     int    `json:"target_minutes"`
+	Status            string `json:"status"`
+	StartedAtMs       int64  `json:"started_at_ms"`
+	PausedAtMs        int64  `json:"paused_at_ms,omitempty"`
+	PausedMs          int64  `json:"paused_ms"`
+	EndedAtMs         int64  `json:"ended_at_ms,omitempty"`
+	DecisionRequestID string `json:"decision_request_id,omitempty"`
+}
+
+type FocusSessionStartRequest struct {
+	Name          string `json:"name"`
+	Task          string `json:"task,omitempty"`
+	TargetMinutes int    `json:"target_minutes"`
+}
+
+type FocusSessionStats struct {
+	ElapsedMs     int64              `json:"elapsed_ms"`
+	ActiveMs      int64              `json:"active_ms"`
+	TargetReached bool               `json:"target_reached"`
+	SwitchCount   int                `json:"switch_count"`
+	CategoryMs    map[string]int64   `json:"category_ms"`
+	TopApps       []FocusAppDuration `json:"top_apps"`
+}
+
+type FocusAppDuration struct {
+	AppName    string `json:"app_name"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+type FocusProviderHealth struct {
+	Name                string `json:"name"`
+	Healthy             bool   `json:"healthy"`
+	LastSuccessMs       int64  `json:"last_success_ms,omitempty"`
+	LastErrorMs         int64  `json:"last_error_ms,omitempty"`
+	LastError           string `json:"last_error,omitempty"`
+	ConsecutiveFailures int    `json:"consecutive_failures"`
 }
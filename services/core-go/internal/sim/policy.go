@@ -0,0 +1,65 @@
+package sim
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"always/core/internal/models"
+)
+
+// policyServer stands in for the AI service so a replay needs no model. It
+// proposes an action per focus_state; scenario policies override the default.
+func policyServer(overrides map[string]models.Action) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /ai/decide", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Context models.Context `json:"context"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid json", http.StatusBadRequest)
+			return
+		}
+		action, ok := overrides[req.Context.FocusState]
+		if !ok {
+			action = defaultPolicy(req.Context)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"action":         action,
+			"policy_version": "sim_policy",
+			"model_version":  "sim",
+		})
+	})
+	mux.HandleFunc("POST /ai/feedback", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	return mux
+}
+
+func defaultPolicy(ctx models.Context) models.Action {
+	action := models.Action{
+		ActionType: models.ActionDoNotDisturb,
+		Message:    "保持当前节奏。",
+		Confidence: 0.9,
+		RiskLevel:  models.RiskLow,
+		State:      ctx.FocusState,
+	}
+	switch ctx.FocusState {
+	case "NO_PROGRESS":
+		action.ActionType = models.ActionTaskBreakdown
+		action.Message = "好像卡住了，试着把下一步拆成十分钟内能完成的小任务。"
+		action.Confidence = 0.8
+	case "DISTRACTED", "PING_PONG", "PEEKING", "SLOW_RETURN":
+		action.ActionType = models.ActionReframe
+		action.Message = "注意力有点分散，先回到手头最重要的那件事上？"
+		action.Confidence = 0.75
+	case "FOCUSED":
+		if minutes, err := strconv.ParseFloat(ctx.Signals["focus_minutes"], 64); err == nil && minutes >= 50 {
+			action.ActionType = models.ActionRestReminder
+			action.Message = "已经连续专注很久了，起来活动一下吧。"
+			action.Confidence = 0.8
+		}
+	}
+	return action
+}
@@ -0,0 +1,178 @@
+package sim
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"always/core/internal/db"
+	"always/core/internal/focus"
+	"always/core/internal/models"
+)
+
+const defaultDecideEvery = time.Minute
+
+// Scenario is a scripted or recorded stretch of focus activity to replay.
+type Scenario struct {
+	// Start is RFC 3339; event offsets are relative to it.
+	Start              string            `json:"start"`
+	DurationSeconds    int64             `json:"duration_seconds,omitempty"`
+	Mode               models.Mode       `json:"mode,omitempty"`
+	DecideEverySeconds int64             `json:"decide_every_seconds,omitempty"`
+	Settings           map[string]string `json:"settings,omitempty"`
+	// Policy maps a focus_state to the action the stand-in AI proposes.
+	Policy map[string]models.Action `json:"policy,omitempty"`
+	Events []ScenarioEvent          `json:"events"`
+
+	start       time.Time
+	snapshots   []focus.FocusSnapshot
+	categories  []models.FocusCategoryRule
+	end         time.Time
+	decideEvery time.Duration
+}
+
+type ScenarioEvent struct {
+	OffsetSeconds int64  `json:"offset_s"`
+	AppName       string `json:"app_name"`
+	BundleID      string `json:"bundle_id,omitempty"`
+	PID           int    `json:"pid,omitempty"`
+	WindowTitle   string `json:"window_title,omitempty"`
+	IdleSeconds   int64  `json:"idle_s,omitempty"`
+}
+
+// LoadScenario reads a scripted JSON scenario.
+func LoadScenario(path string) (*Scenario, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read scenario: %w", err)
+	}
+	var scenario Scenario
+	if err := json.Unmarshal(raw, &scenario); err != nil {
+		return nil, fmt.Errorf("decode scenario: %w", err)
+	}
+	start, err := time.Parse(time.RFC3339, scenario.Start)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start: %w", err)
+	}
+	scenario.start = start
+	sort.SliceStable(scenario.Events, func(i, j int) bool {
+		return scenario.Events[i].OffsetSeconds < scenario.Events[j].OffsetSeconds
+	})
+	var lastOffset int64
+	for i, event := range scenario.Events {
+		if event.OffsetSeconds < 0 {
+			return nil, fmt.Errorf("event %d: negative offset", i)
+		}
+		scenario.snapshots = append(scenario.snapshots, focus.FocusSnapshot{
+			TsMs:        start.Add(time.Duration(event.OffsetSeconds) * time.Second).UnixMilli(),
+			AppName:     event.AppName,
+			BundleID:    event.BundleID,
+			PID:         event.PID,
+			WindowTitle: event.WindowTitle,
+			IdleMs:      event.IdleSeconds * 1000,
+		})
+		lastOffset = event.OffsetSeconds
+	}
+	duration := time.Duration(scenario.DurationSeconds) * time.Second
+	if duration <= 0 {
+		duration = time.Duration(lastOffset)*time.Second + defaultDecideEvery
+	}
+	scenario.end = start.Add(duration)
+	scenario.Normalize()
+	return &scenario, nil
+}
+
+// RecordedDay builds a scenario from the focus events a store recorded on the
+// local calendar day of day. Settings and category rules are copied so the
+// replay sees the same configuration; gaps longer than the idle threshold
+// become idle snapshots, since idle periods close events.
+func RecordedDay(store *db.Store, day time.Time) (*Scenario, error) {
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	end := start.AddDate(0, 0, 1)
+	events, err := store.ListFocusEventsRange(start.UnixMilli(), end.UnixMilli())
+	if err != nil {
+		return nil, err
+	}
+	settings, err := store.ListSettings()
+	if err != nil {
+		return nil, err
+	}
+	categories, err := store.ListFocusCategoryRules()
+	if err != nil {
+		return nil, err
+	}
+
+	scenario := &Scenario{
+		Start:      start.Format(time.RFC3339),
+		Settings:   map[string]string{},
+		start:      start,
+		end:        end,
+		categories: categories,
+	}
+	for _, setting := range settings {
+		scenario.Settings[setting.Key] = setting.Value
+	}
+	idleThreshold := 5 * time.Minute
+	if value, ok := scenario.Settings["focus_idle_threshold_seconds"]; ok {
+		var seconds int64
+		if _, err := fmt.Sscan(value, &seconds); err == nil {
+			idleThreshold = time.Duration(seconds) * time.Second
+		}
+	}
+
+	// Only locally polled events drive the monitor; ingested ones would also
+	// hide the idle gaps between them.
+	local := events[:0]
+	for _, event := range events {
+		if event.Source == "" || event.Source == models.FocusSourceLocal {
+			local = append(local, event)
+		}
+	}
+	for i, event := range local {
+		tsMs := max(event.TsMs, start.UnixMilli())
+		scenario.snapshots = append(scenario.snapshots, focus.FocusSnapshot{
+			TsMs:        tsMs,
+			AppName:     event.AppName,
+			BundleID:    event.BundleID,
+			PID:         event.PID,
+			WindowTitle: event.WindowTitle,
+		})
+		if event.DurationMs <= 0 || idleThreshold <= 0 {
+			continue
+		}
+		endMs := event.TsMs + event.DurationMs
+		nextMs := end.UnixMilli()
+		if i+1 < len(local) {
+			nextMs = local[i+1].TsMs
+		}
+		if nextMs-endMs >= idleThreshold.Milliseconds() {
+			scenario.snapshots = append(scenario.snapshots, focus.FocusSnapshot{
+				TsMs:        endMs + idleThreshold.Milliseconds(),
+				AppName:     event.AppName,
+				BundleID:    event.BundleID,
+				PID:         event.PID,
+				WindowTitle: event.WindowTitle,
+				IdleMs:      idleThreshold.Milliseconds(),
+			})
+		}
+	}
+	scenario.Normalize()
+	return scenario, nil
+}
+
+// Normalize fills defaults and recomputes the decision interval; call it after
+// changing Mode or DecideEverySeconds.
+func (s *Scenario) Normalize() {
+	if s.Mode == "" {
+		s.Mode = models.ModeLight
+	}
+	s.decideEvery = time.Duration(s.DecideEverySeconds) * time.Second
+	if s.decideEvery <= 0 {
+		s.decideEvery = defaultDecideEvery
+	}
+	if s.Settings == nil {
+		s.Settings = map[string]string{}
+	}
+}
@@ -0,0 +1,236 @@
+// Package sim replays focus activity through the real monitor, decision
+// pipeline and gateway against a virtual clock, so thresholds and budgets can
+// be tuned offline.
+package sim
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	"always/core/internal/ai"
+	"always/core/internal/clock"
+	"always/core/internal/db"
+	"always/core/internal/focus"
+	"always/core/internal/httpapi"
+	"always/core/internal/memory"
+	"always/core/internal/models"
+)
+
+// Settings the replay must not take from a scenario: they would disable or
+// replace the replay provider, or pre-empt the auto-suggestion window.
+var skippedSettings = map[string]bool{
+	"focus_monitor_enabled":   true,
+	"focus_provider_cmd":      true,
+	"last_auto_suggestion_ms": true,
+}
+
+type Options struct {
+	// AIURL sends decisions to a running AI service instead of the built-in
+	// stand-in policy.
+	AIURL string
+	// DBPath keeps the simulated store for inspection; a temporary file is
+	// used and removed when empty.
+	DBPath string
+	// Speed is virtual seconds per real second; 0 runs as fast as possible.
+	Speed float64
+	// Overrides are applied on top of the scenario settings.
+	Overrides map[string]string
+	Logger    *slog.Logger
+}
+
+// Step is one decision tick of the replay.
+type Step struct {
+	TsMs          int64                      `json:"ts_ms"`
+	Time          string                     `json:"time"`
+	FocusState    string                     `json:"focus_state"`
+	AppName       string                     `json:"app_name,omitempty"`
+	SwitchCount   int                        `json:"switch_count"`
+	Proposed      models.ActionType          `json:"proposed,omitempty"`
+	Final         models.ActionType          `json:"final"`
+	Decision      models.GatewayDecisionType `json:"decision"`
+	Reason        string                     `json:"reason"`
+	PolicyVersion string                     `json:"policy_version"`
+	Message       string                     `json:"message"`
+}
+
+// Fired reports whether the user would have seen an intervention.
+func (s Step) Fired() bool {
+	return s.Final != "" && s.Final != models.ActionDoNotDisturb
+}
+
+type Summary struct {
+	Ticks      int               `json:"ticks"`
+	Proposed   int               `json:"proposed"`
+	Fired      int               `json:"fired"`
+	ByAction   map[string]int    `json:"by_action"`
+	Suppressed map[string]int    `json:"suppressed"`
+	Rejected   map[string]string `json:"rejected_settings,omitempty"`
+}
+
+// Run replays the scenario and calls emit for every decision tick.
+func Run(scenario *Scenario, opts Options, emit func(Step)) (Summary, error) {
+	summary := Summary{ByAction: map[string]int{}, Suppressed: map[string]int{}}
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+
+	dbPath := opts.DBPath
+	if dbPath == "" {
+		dir, err := os.MkdirTemp("", "always-sim-")
+		if err != nil {
+			return summary, fmt.Errorf("create temp dir: %w", err)
+		}
+		defer os.RemoveAll(dir)
+		dbPath = filepath.Join(dir, "sim.db")
+	}
+	store, err := db.Open(dbPath)
+	if err != nil {
+		return summary, err
+	}
+	defer store.DB().Close()
+	if scenario.categories != nil {
+		if err := replaceCategoryRules(store, scenario.categories); err != nil {
+			return summary, err
+		}
+	}
+
+	aiURL := opts.AIURL
+	if aiURL == "" {
+		server := httptest.NewServer(policyServer(scenario.Policy))
+		defer server.Close()
+		aiURL = server.URL
+	}
+
+	clk := clock.NewVirtual(scenario.start)
+	monitor := focus.NewReplayMonitor(store, logger, clk)
+	handler := httpapi.NewHandler(store, ai.NewClient(aiURL), monitor, memory.NewService(store.DB(), logger), scenario.start, logger)
+	handler.SetClock(clk)
+	router := handler.Router()
+
+	settings := map[string]string{}
+	for key, value := range scenario.Settings {
+		settings[key] = value
+	}
+	for key, value := range opts.Overrides {
+		settings[key] = value
+	}
+	for key, value := range settings {
+		if skippedSettings[key] {
+			continue
+		}
+		if status, body := call(router, http.MethodPost, "/v1/settings", models.SettingRequest{Key: key, Value: value}); status != http.StatusOK {
+			if summary.Rejected == nil {
+				summary.Rejected = map[string]string{}
+			}
+			summary.Rejected[key] = string(bytes.TrimSpace(body))
+		}
+	}
+
+	snapshots := scenario.snapshots
+	last := scenario.start
+	for tick := scenario.start; tick.Before(scenario.end); tick = tick.Add(scenario.decideEvery) {
+		for len(snapshots) > 0 && snapshots[0].TsMs <= tick.UnixMilli() {
+			clk.Set(time.UnixMilli(snapshots[0].TsMs))
+			monitor.Observe(snapshots[0])
+			snapshots = snapshots[1:]
+		}
+		clk.Set(tick)
+		if opts.Speed > 0 {
+			time.Sleep(time.Duration(float64(tick.Sub(last)) / opts.Speed))
+		}
+		last = tick
+
+		step, err := decide(router, monitor, scenario.Mode, tick)
+		if err != nil {
+			return summary, err
+		}
+		summary.Ticks++
+		if step.Proposed != "" && step.Proposed != models.ActionDoNotDisturb {
+			summary.Proposed++
+			if !step.Fired() {
+				summary.Suppressed[step.Reason]++
+			}
+		}
+		if step.Fired() {
+			summary.Fired++
+			summary.ByAction[string(step.Final)]++
+		}
+		emit(step)
+	}
+	return summary, nil
+}
+
+func decide(router http.Handler, monitor *focus.Monitor, mode models.Mode, now time.Time) (Step, error) {
+	req := models.DecisionRequest{Context: models.Context{
+		Timestamp: now.UnixMilli(),
+		Mode:      mode,
+		Signals:   map[string]string{},
+	}}
+	status, body := call(router, http.MethodPost, "/v1/decision", req)
+	if status != http.StatusOK {
+		return Step{}, fmt.Errorf("decision at %s: %d %s", now.Format(time.RFC3339), status, bytes.TrimSpace(body))
+	}
+	var resp models.DecisionResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return Step{}, fmt.Errorf("decode decision: %w", err)
+	}
+
+	step := Step{
+		TsMs:          now.UnixMilli(),
+		Time:          now.Format("2006-01-02 15:04"),
+		FocusState:    resp.Context.FocusState,
+		AppName:       resp.Context.Signals["focus_app"],
+		SwitchCount:   monitor.SwitchCount(),
+		Final:         resp.Action.ActionType,
+		Decision:      resp.GatewayDecision.Decision,
+		Reason:        resp.GatewayDecision.Reason,
+		PolicyVersion: resp.PolicyVersion,
+		Message:       resp.Action.Message,
+	}
+	switch {
+	case resp.GatewayDecision.OverriddenActionType != "":
+		step.Proposed = resp.GatewayDecision.OverriddenActionType
+	case resp.ModelVersion == "n/a":
+		// Guards such as quiet hours answer before the policy is asked.
+		step.Reason = resp.PolicyVersion
+	default:
+		step.Proposed = resp.Action.ActionType
+	}
+	return step, nil
+}
+
+func call(router http.Handler, method string, path string, payload any) (int, []byte) {
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest(method, path, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec.Code, rec.Body.Bytes()
+}
+
+func replaceCategoryRules(store *db.Store, rules []models.FocusCategoryRule) error {
+	existing, err := store.ListFocusCategoryRules()
+	if err != nil {
+		return err
+	}
+	for _, rule := range existing {
+		if _, err := store.DeleteFocusCategoryRule(rule.ID); err != nil {
+			return err
+		}
+	}
+	for _, rule := range rules {
+		if _, err := store.InsertFocusCategoryRule(rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package sim
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"always/core/internal/db"
+	"always/core/internal/models"
+)
+
+const testScenario = `{
+  "start": "2026-10-12T09:00:00+08:00",
+  "duration_seconds": 5400,
+  "decide_every_seconds": 300,
+  "mode": "ACTIVE",
+  "settings": {"focus_state_switch_count": "4", "cooldown_seconds": "-5"},
+  "events": [
+    {"offset_s": 3240, "app_name": "Slack", "pid": 2},
+    {"offset_s": 0, "app_name": "Code", "pid": 1, "window_title": "a.go"},
+    {"offset_s": 3000, "app_name": "Slack", "pid": 2},
+    {"offset_s": 3060, "app_name": "Code", "pid": 1, "window_title": "b.go"},
+    {"offset_s": 3120, "app_name": "Slack", "pid": 2},
+    {"offset_s": 3180, "app_name": "Code", "pid": 1, "window_title": "c.go"}
+  ]
+}`
+
+func writeScenario(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "scenario.json")
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadScenario(t *testing.T) {
+	scenario, err := LoadScenario(writeScenario(t, testScenario))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scenario.snapshots) != 6 || scenario.snapshots[0].AppName != "Code" || scenario.snapshots[5].AppName != "Slack" {
+		t.Fatalf("snapshots = %+v", scenario.snapshots)
+	}
+	if scenario.decideEvery != 5*time.Minute || scenario.end.Sub(scenario.start) != 90*time.Minute {
+		t.Fatalf("every = %s, span = %s", scenario.decideEvery, scenario.end.Sub(scenario.start))
+	}
+
+	scenario, err = LoadScenario(writeScenario(t, `{"start": "2026-10-12T09:00:00Z", "events": [{"offset_s": 120, "app_name": "Code"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scenario.Mode != models.ModeLight || scenario.end.Sub(scenario.start) != 3*time.Minute {
+		t.Fatalf("defaults: mode = %s, span = %s", scenario.Mode, scenario.end.Sub(scenario.start))
+	}
+
+	for _, body := range []string{
+		`{"start": "yesterday", "events": []}`,
+		`{"start": "2026-10-12T09:00:00Z", "events": [{"offset_s": -1, "app_name": "Code"}]}`,
+		`{"start": `,
+	} {
+		if _, err := LoadScenario(writeScenario(t, body)); err == nil {
+			t.Errorf("LoadScenario(%s) succeeded", body)
+		}
+	}
+}
+
+func TestRunScenario(t *testing.T) {
+	scenario, err := LoadScenario(writeScenario(t, testScenario))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var steps []Step
+	summary, err := Run(scenario, Options{}, func(step Step) { steps = append(steps, step) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Ticks != 18 || len(steps) != 18 || summary.Fired != 1 || summary.ByAction[string(models.ActionReframe)] != 1 {
+		t.Fatalf("summary = %+v", summary)
+	}
+	if _, ok := summary.Rejected["cooldown_seconds"]; !ok || len(summary.Rejected) != 1 {
+		t.Fatalf("rejected = %v", summary.Rejected)
+	}
+	for i, step := range steps {
+		if want := scenario.start.Add(time.Duration(i) * 5 * time.Minute).UnixMilli(); step.TsMs != want {
+			t.Fatalf("step %d at %d, want %d", i, step.TsMs, want)
+		}
+	}
+	fired := steps[12]
+	if fired.Time != "2026-10-12 10:00" || !fired.Fired() || fired.Final != models.ActionReframe || fired.Decision != models.GatewayAllow {
+		t.Fatalf("step 12 = %+v", fired)
+	}
+	if steps[4].FocusState != "LIGHT" || steps[6].FocusState != "FOCUSED" || steps[11].FocusState != "DISTRACTED" {
+		t.Fatalf("states = %s %s %s", steps[4].FocusState, steps[6].FocusState, steps[11].FocusState)
+	}
+
+	// A scenario policy replaces the stand-in answer for its focus_state.
+	scenario.Policy = map[string]models.Action{
+		"FOCUSED": {ActionType: models.ActionEncourage, Message: "nice", Confidence: 0.9, RiskLevel: models.RiskLow},
+	}
+	summary, err = Run(scenario, Options{Overrides: map[string]string{"cooldown_seconds": "0"}}, func(Step) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.ByAction[string(models.ActionEncourage)] == 0 || len(summary.Rejected) != 0 {
+		t.Fatalf("summary with policy = %+v", summary)
+	}
+}
+
+func TestRecordedDay(t *testing.T) {
+	store, err := db.Open(filepath.Join(t.TempDir(), "always.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.DB().Close()
+	day := time.Date(2026, 10, 12, 0, 0, 0, 0, time.Local)
+	at := func(hour, minute int) int64 {
+		return day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute).UnixMilli()
+	}
+	for _, event := range []models.FocusEvent{
+		{TsMs: at(9, 0), AppName: "Code", PID: 1, DurationMs: (30 * time.Minute).Milliseconds()},
+		{TsMs: at(9, 10), AppName: "Figma", Source: "laptop", DurationMs: time.Minute.Milliseconds()},
+		{TsMs: at(10, 0), AppName: "Slack", PID: 2, DurationMs: (2 * time.Minute).Milliseconds()},
+		{TsMs: at(10, 3), AppName: "Code", PID: 1},
+	} {
+		if _, err := store.InsertFocusEvent(event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.UpsertSetting("focus_idle_threshold_seconds", "600"); err != nil {
+		t.Fatal(err)
+	}
+
+	scenario, err := RecordedDay(store, day.Add(12*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var apps []string
+	var idle []int64
+	for _, snapshot := range scenario.snapshots {
+		apps = append(apps, snapshot.AppName)
+		idle = append(idle, snapshot.IdleMs)
+	}
+	// The 30-minute gap after Code becomes an idle snapshot; the 1-minute gap
+	// after Slack does not, and the remote Figma event is skipped.
+	if len(apps) != 4 || apps[0] != "Code" || apps[1] != "Code" || apps[2] != "Slack" || apps[3] != "Code" {
+		t.Fatalf("apps = %v", apps)
+	}
+	if idle[1] != (10*time.Minute).Milliseconds() || scenario.snapshots[1].TsMs != at(9, 40) || idle[2] != 0 {
+		t.Fatalf("snapshots = %+v", scenario.snapshots)
+	}
+	if scenario.Settings["focus_idle_threshold_seconds"] != "600" || len(scenario.categories) == 0 {
+		t.Fatalf("settings = %v, categories = %d", scenario.Settings, len(scenario.categories))
+	}
+	if !scenario.start.Equal(day) || !scenario.end.Equal(day.AddDate(0, 0, 1)) {
+		t.Fatalf("span = %s - %s", scenario.start, scenario.end)
+	}
+}
@@ -50,6 +50,7 @@ func main() {
 	go func() {
 		<-shutdownCh
 		logger.Info("shutdown signal received")
+		focusMonitor.Stop()
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 		if err := server.Shutdown(ctx); err != nil {